@@ -22,11 +22,13 @@ import (
 	"github.com/catalyzeio/cli/commands/files"
 	"github.com/catalyzeio/cli/commands/git"
 	"github.com/catalyzeio/cli/commands/invites"
+	"github.com/catalyzeio/cli/commands/jobs"
 	"github.com/catalyzeio/cli/commands/keys"
 	"github.com/catalyzeio/cli/commands/logout"
 	"github.com/catalyzeio/cli/commands/logs"
 	"github.com/catalyzeio/cli/commands/maintenance"
 	"github.com/catalyzeio/cli/commands/metrics"
+	"github.com/catalyzeio/cli/commands/profile"
 	"github.com/catalyzeio/cli/commands/rake"
 	"github.com/catalyzeio/cli/commands/redeploy"
 	"github.com/catalyzeio/cli/commands/releases"
@@ -46,8 +48,12 @@ import (
 	"github.com/catalyzeio/cli/config"
 	"github.com/catalyzeio/cli/models"
 
+	"github.com/catalyzeio/cli/lib/auth"
 	"github.com/catalyzeio/cli/lib/httpclient"
+	"github.com/catalyzeio/cli/lib/interrupt"
 	"github.com/catalyzeio/cli/lib/pods"
+	"github.com/catalyzeio/cli/lib/prompts"
+	"github.com/catalyzeio/cli/lib/render"
 	"github.com/catalyzeio/cli/lib/updater"
 
 	"github.com/Sirupsen/logrus"
@@ -94,22 +100,17 @@ func Run() {
 	InitGlobalOpts(app, settings)
 	InitCLI(app, settings)
 
+	interrupt.Listen()
 	app.Run(os.Args)
+	// let the cleanups of an interrupted command finish and exit
+	interrupt.Wait()
 }
 
 func InitGlobalOpts(app *cli.Cli, settings *models.Settings) {
+	// hosts fall back to the profile and then the defaults when not set
 	accountsHost := os.Getenv(config.AccountsHostEnvVar)
-	if accountsHost == "" {
-		accountsHost = config.AccountsHost
-	}
 	authHost := os.Getenv(config.AuthHostEnvVar)
-	if authHost == "" {
-		authHost = config.AuthHost
-	}
 	paasHost := os.Getenv(config.PaasHostEnvVar)
-	if paasHost == "" {
-		paasHost = config.PaasHost
-	}
 	username := app.String(cli.StringOpt{
 		Name:      "U username",
 		Desc:      "Catalyze Username",
@@ -128,6 +129,28 @@ func InitGlobalOpts(app *cli.Cli, settings *models.Settings) {
 		EnvVar:    config.CatalyzeEnvironmentEnvVar,
 		HideValue: true,
 	})
+	profileName := app.String(cli.StringOpt{
+		Name:      "profile",
+		Desc:      "The name of the profile to use for this command",
+		EnvVar:    config.ProfileEnvVar,
+		HideValue: true,
+	})
+	output := app.String(cli.StringOpt{
+		Name:   "o output",
+		Desc:   "The format to print command results in: table, json, or yaml",
+		EnvVar: config.OutputEnvVar,
+		Value:  render.Table,
+	})
+	nonInteractive := app.Bool(cli.BoolOpt{
+		Name:   "non-interactive",
+		Desc:   "Never prompt for input. Commands that need confirmation or credentials that are not supplied through env variables will fail instead",
+		EnvVar: config.NonInteractiveEnvVar,
+	})
+	assumeYes := app.Bool(cli.BoolOpt{
+		Name:   "y yes",
+		Desc:   "Automatically answer yes to all confirmation prompts. Implies --non-interactive",
+		EnvVar: config.AssumeYesEnvVar,
+	})
 	if loggingLevel := os.Getenv(config.LogLevelEnvVar); loggingLevel != "" {
 		if lvl, err := logrus.ParseLevel(loggingLevel); err == nil {
 			logrus.SetLevel(lvl)
@@ -138,10 +161,23 @@ func InitGlobalOpts(app *cli.Cli, settings *models.Settings) {
 		if config.Beta {
 			logrus.Println("This is a BETA release. Please contact Catalyze support at support@catalyze.io with any issues.")
 		}
+		if err := render.Validate(*output); err != nil {
+			logrus.Fatal(err.Error())
+		}
 		r := config.FileSettingsRetriever{}
-		*settings = *r.GetSettings(*givenEnvName, "", accountsHost, authHost, "", paasHost, "", *username, *password)
+		*settings = *r.GetSettings(*profileName, *givenEnvName, "", accountsHost, authHost, "", paasHost, "", *username, *password)
+		settings.Output = *output
+		settings.AssumeYes = *assumeYes
+		settings.NonInteractive = *nonInteractive || *assumeYes
 		skip, _ := strconv.ParseBool(os.Getenv(config.SkipVerifyEnvVar))
-		settings.HTTPManager = httpclient.NewTLSHTTPManager(skip)
+		settings.HTTPManager = httpclient.NewTLSHTTPManager(skip, func() (string, error) {
+			// a session that expires part way through a command is renewed
+			// without failing the command
+			if _, err := auth.New(settings, prompts.New(settings)).Signin(); err != nil {
+				return "", err
+			}
+			return settings.SessionToken, nil
+		})
 		logrus.Debugf("%+v", settings)
 
 		if settings.Pods == nil || len(*settings.Pods) == 0 || settings.PodCheck < time.Now().Unix() {
@@ -194,11 +230,13 @@ func InitCLI(app *cli.Cli, settings *models.Settings) {
 	app.CommandLong(files.Cmd.Name, files.Cmd.ShortHelp, files.Cmd.LongHelp, files.Cmd.CmdFunc(settings))
 	app.CommandLong(git.Cmd.Name, git.Cmd.ShortHelp, git.Cmd.LongHelp, git.Cmd.CmdFunc(settings))
 	app.CommandLong(invites.Cmd.Name, invites.Cmd.ShortHelp, invites.Cmd.LongHelp, invites.Cmd.CmdFunc(settings))
+	app.CommandLong(jobs.Cmd.Name, jobs.Cmd.ShortHelp, jobs.Cmd.LongHelp, jobs.Cmd.CmdFunc(settings))
 	app.CommandLong(keys.Cmd.Name, keys.Cmd.ShortHelp, keys.Cmd.LongHelp, keys.Cmd.CmdFunc(settings))
 	app.CommandLong(logout.Cmd.Name, logout.Cmd.ShortHelp, logout.Cmd.LongHelp, logout.Cmd.CmdFunc(settings))
 	app.CommandLong(logs.Cmd.Name, logs.Cmd.ShortHelp, logs.Cmd.LongHelp, logs.Cmd.CmdFunc(settings))
 	app.CommandLong(maintenance.Cmd.Name, maintenance.Cmd.ShortHelp, maintenance.Cmd.LongHelp, maintenance.Cmd.CmdFunc(settings))
 	app.CommandLong(metrics.Cmd.Name, metrics.Cmd.ShortHelp, metrics.Cmd.LongHelp, metrics.Cmd.CmdFunc(settings))
+	app.CommandLong(profile.Cmd.Name, profile.Cmd.ShortHelp, profile.Cmd.LongHelp, profile.Cmd.CmdFunc(settings))
 	app.CommandLong(rake.Cmd.Name, rake.Cmd.ShortHelp, rake.Cmd.LongHelp, rake.Cmd.CmdFunc(settings))
 	app.CommandLong(redeploy.Cmd.Name, redeploy.Cmd.ShortHelp, redeploy.Cmd.LongHelp, redeploy.Cmd.CmdFunc(settings))
 	app.CommandLong(releases.Cmd.Name, releases.Cmd.ShortHelp, releases.Cmd.LongHelp, releases.Cmd.CmdFunc(settings))
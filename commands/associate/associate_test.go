@@ -75,3 +75,22 @@ func TestAssociate(t *testing.T) {
 		}
 	}
 }
+
+func TestAssumeOnlyAssociation(t *testing.T) {
+	if err := test.SetUpGitRepo(); err != nil {
+		t.Fatal(err)
+	}
+	if err := test.ClearAssociations(); err != nil {
+		t.Fatal(err)
+	}
+	if err := test.SetUpAssociation(); err != nil {
+		t.Fatal(err)
+	}
+	output, err := test.RunCommand(test.BinaryName, []string{"-y", "services", "list"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", output)
+	}
+	if !strings.Contains(output, test.SvcLabel) {
+		t.Errorf("Expected the services of %s to be listed. Found: %s", test.Alias, output)
+	}
+}
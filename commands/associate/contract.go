@@ -1,11 +1,11 @@
 package associate
 
 import (
-	"github.com/Sirupsen/logrus"
 	"github.com/catalyzeio/cli/commands/environments"
 	"github.com/catalyzeio/cli/commands/git"
 	"github.com/catalyzeio/cli/commands/services"
 	"github.com/catalyzeio/cli/lib/auth"
+	"github.com/catalyzeio/cli/lib/httpclient"
 	"github.com/catalyzeio/cli/lib/prompts"
 	"github.com/catalyzeio/cli/models"
 	"github.com/jault3/mow.cli"
@@ -27,12 +27,12 @@ var Cmd = models.Command{
 			remote := cmd.StringOpt("r remote", "catalyze", "The name of the remote")
 			defaultEnv := cmd.BoolOpt("d default", false, "[DEPRECATED] Specifies whether or not the associated environment will be the default")
 			cmd.Action = func() {
-				if _, err := auth.New(settings, prompts.New()).Signin(); err != nil {
-					logrus.Fatal(err.Error())
+				if _, err := auth.New(settings, prompts.New(settings)).Signin(); err != nil {
+					httpclient.Fatal(err)
 				}
 				err := CmdAssociate(*envName, *serviceName, *alias, *remote, *defaultEnv, New(settings), git.New(), environments.New(settings), services.New(settings))
 				if err != nil {
-					logrus.Fatal(err.Error())
+					httpclient.Fatal(err)
 				}
 			}
 			cmd.Spec = "ENV_NAME SERVICE_NAME [-a] [-r] [-d]"
@@ -2,16 +2,21 @@ package associated
 
 import (
 	"github.com/Sirupsen/logrus"
+	"github.com/catalyzeio/cli/lib/render"
 	"github.com/catalyzeio/cli/models"
 )
 
-func CmdAssociated(ia IAssociated) error {
+func CmdAssociated(ia IAssociated, ir render.IRender) error {
 	envs, err := ia.Associated()
 	if err != nil {
 		return err
 	}
-	for envAlias, env := range envs {
-		logrus.Printf(`%s:
+	if envs == nil {
+		envs = map[string]models.AssociatedEnv{}
+	}
+	return ir.Render(envs, func() error {
+		for envAlias, env := range envs {
+			logrus.Printf(`%s:
     Environment ID:   %s
     Environment Name: %s
     Service ID:       %s
@@ -19,11 +24,12 @@ func CmdAssociated(ia IAssociated) error {
     Pod:              %s
     Organization ID:  %s
 `, envAlias, env.EnvironmentID, env.Name, env.ServiceID, env.Directory, env.Pod, env.OrgID)
-	}
-	if len(envs) == 0 {
-		logrus.Println("No environments have been associated")
-	}
-	return nil
+		}
+		if len(envs) == 0 {
+			logrus.Println("No environments have been associated")
+		}
+		return nil
+	})
 }
 
 // Associated lists all currently associated environments.
@@ -1,7 +1,8 @@
 package associated
 
 import (
-	"github.com/Sirupsen/logrus"
+	"github.com/catalyzeio/cli/lib/httpclient"
+	"github.com/catalyzeio/cli/lib/render"
 	"github.com/catalyzeio/cli/models"
 	"github.com/jault3/mow.cli"
 )
@@ -17,9 +18,9 @@ var Cmd = models.Command{
 	CmdFunc: func(settings *models.Settings) func(cmd *cli.Cmd) {
 		return func(cmd *cli.Cmd) {
 			cmd.Action = func() {
-				err := CmdAssociated(New(settings))
+				err := CmdAssociated(New(settings), render.New(settings))
 				if err != nil {
-					logrus.Fatal(err.Error())
+					httpclient.Fatal(err)
 				}
 			}
 		}
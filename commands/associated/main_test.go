@@ -0,0 +1,12 @@
+package associated
+
+import (
+	"os"
+	"testing"
+
+	"github.com/catalyzeio/cli/test"
+)
+
+func TestMain(m *testing.M) {
+	os.Exit(test.Main(m))
+}
@@ -1,12 +1,13 @@
 package certs
 
 import (
-	"github.com/Sirupsen/logrus"
 	"github.com/catalyzeio/cli/commands/services"
 	"github.com/catalyzeio/cli/commands/ssl"
 	"github.com/catalyzeio/cli/config"
 	"github.com/catalyzeio/cli/lib/auth"
+	"github.com/catalyzeio/cli/lib/httpclient"
 	"github.com/catalyzeio/cli/lib/prompts"
+	"github.com/catalyzeio/cli/lib/render"
 	"github.com/catalyzeio/cli/models"
 	"github.com/jault3/mow.cli"
 )
@@ -47,15 +48,15 @@ var CreateSubCmd = models.Command{
 			selfSigned := subCmd.BoolOpt("s self-signed", false, "Whether or not the given SSL certificate and private key are self signed")
 			resolve := subCmd.BoolOpt("r resolve", true, "Whether or not to attempt to automatically resolve incomplete SSL certificate issues")
 			subCmd.Action = func() {
-				if _, err := auth.New(settings, prompts.New()).Signin(); err != nil {
-					logrus.Fatal(err.Error())
+				if _, err := auth.New(settings, prompts.New(settings)).Signin(); err != nil {
+					httpclient.Fatal(err)
 				}
 				if err := config.CheckRequiredAssociation(true, true, settings); err != nil {
-					logrus.Fatal(err.Error())
+					httpclient.Fatal(err)
 				}
 				err := CmdCreate(*name, *pubKeyPath, *privKeyPath, *selfSigned, *resolve, New(settings), services.New(settings), ssl.New(settings))
 				if err != nil {
-					logrus.Fatal(err.Error())
+					httpclient.Fatal(err)
 				}
 			}
 			subCmd.Spec = "NAME PUBLIC_KEY_PATH PRIVATE_KEY_PATH [-s] [-r]"
@@ -72,15 +73,15 @@ var ListSubCmd = models.Command{
 	CmdFunc: func(settings *models.Settings) func(cmd *cli.Cmd) {
 		return func(subCmd *cli.Cmd) {
 			subCmd.Action = func() {
-				if _, err := auth.New(settings, prompts.New()).Signin(); err != nil {
-					logrus.Fatal(err.Error())
+				if _, err := auth.New(settings, prompts.New(settings)).Signin(); err != nil {
+					httpclient.Fatal(err)
 				}
 				if err := config.CheckRequiredAssociation(true, true, settings); err != nil {
-					logrus.Fatal(err.Error())
+					httpclient.Fatal(err)
 				}
-				err := CmdList(New(settings), services.New(settings))
+				err := CmdList(New(settings), services.New(settings), render.New(settings))
 				if err != nil {
-					logrus.Fatal(err.Error())
+					httpclient.Fatal(err)
 				}
 			}
 		}
@@ -96,15 +97,15 @@ var RmSubCmd = models.Command{
 		return func(subCmd *cli.Cmd) {
 			name := subCmd.StringArg("HOSTNAME", "", "The hostname of the domain and SSL certificate and private key pair")
 			subCmd.Action = func() {
-				if _, err := auth.New(settings, prompts.New()).Signin(); err != nil {
-					logrus.Fatal(err.Error())
+				if _, err := auth.New(settings, prompts.New(settings)).Signin(); err != nil {
+					httpclient.Fatal(err)
 				}
 				if err := config.CheckRequiredAssociation(true, true, settings); err != nil {
-					logrus.Fatal(err.Error())
+					httpclient.Fatal(err)
 				}
 				err := CmdRm(*name, New(settings), services.New(settings))
 				if err != nil {
-					logrus.Fatal(err.Error())
+					httpclient.Fatal(err)
 				}
 			}
 			subCmd.Spec = "HOSTNAME"
@@ -127,15 +128,15 @@ var UpdateSubCmd = models.Command{
 			selfSigned := subCmd.BoolOpt("s self-signed", false, "Whether or not the given SSL certificate and private key are self signed")
 			resolve := subCmd.BoolOpt("r resolve", true, "Whether or not to attempt to automatically resolve incomplete SSL certificate issues")
 			subCmd.Action = func() {
-				if _, err := auth.New(settings, prompts.New()).Signin(); err != nil {
-					logrus.Fatal(err.Error())
+				if _, err := auth.New(settings, prompts.New(settings)).Signin(); err != nil {
+					httpclient.Fatal(err)
 				}
 				if err := config.CheckRequiredAssociation(true, true, settings); err != nil {
-					logrus.Fatal(err.Error())
+					httpclient.Fatal(err)
 				}
 				err := CmdUpdate(*name, *pubKeyPath, *privKeyPath, *selfSigned, *resolve, New(settings), services.New(settings), ssl.New(settings))
 				if err != nil {
-					logrus.Fatal(err.Error())
+					httpclient.Fatal(err)
 				}
 			}
 			subCmd.Spec = "NAME PUBLIC_KEY_PATH PRIVATE_KEY_PATH [-s] [-r]"
@@ -54,12 +54,20 @@ var (
 
 func TestMain(m *testing.M) {
 	flag.Parse()
+	teardown, err := test.Setup()
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
 	if err := CreateCertFiles(); err != nil {
 		fmt.Println(err)
+		CleanupCertFiles()
+		teardown()
 		os.Exit(1)
 	}
 	statusCode := m.Run()
 	CleanupCertFiles()
+	teardown()
 	os.Exit(statusCode)
 }
 
@@ -196,8 +204,8 @@ func CreateCertFiles() error {
 
 func CleanupCertFiles() error {
 	err := os.Remove(pubKeyPath)
-	if err != nil {
-		err = os.Remove(privKeyPath)
+	if keyErr := os.Remove(privKeyPath); err == nil {
+		err = keyErr
 	}
 	return err
 }
@@ -5,10 +5,11 @@ import (
 
 	"github.com/Sirupsen/logrus"
 	"github.com/catalyzeio/cli/commands/services"
+	"github.com/catalyzeio/cli/lib/render"
 	"github.com/catalyzeio/cli/models"
 )
 
-func CmdList(ic ICerts, is services.IServices) error {
+func CmdList(ic ICerts, is services.IServices, ir render.IRender) error {
 	service, err := is.RetrieveByLabel("service_proxy")
 	if err != nil {
 		return err
@@ -17,15 +18,20 @@ func CmdList(ic ICerts, is services.IServices) error {
 	if err != nil {
 		return err
 	}
-	if certs == nil || len(*certs) == 0 {
-		logrus.Println("No certs found")
-		return nil
-	}
-	logrus.Println("NAME")
-	for _, cert := range *certs {
-		logrus.Println(cert.Name)
+	if certs == nil {
+		certs = &[]models.Cert{}
 	}
-	return nil
+	return ir.Render(certs, func() error {
+		if len(*certs) == 0 {
+			logrus.Println("No certs found")
+			return nil
+		}
+		logrus.Println("NAME")
+		for _, cert := range *certs {
+			logrus.Println(cert.Name)
+		}
+		return nil
+	})
 }
 
 func (c *SCerts) List(svcID string) (*[]models.Cert, error) {
@@ -1,7 +1,7 @@
 package clear
 
 import (
-	"github.com/Sirupsen/logrus"
+	"github.com/catalyzeio/cli/lib/httpclient"
 	"github.com/catalyzeio/cli/models"
 	"github.com/jault3/mow.cli"
 )
@@ -36,7 +36,7 @@ var Cmd = models.Command{
 				}
 				err := CmdClear(*privateKey, *session, *envs, *defaultEnv, *pods, settings)
 				if err != nil {
-					logrus.Fatal(err.Error())
+					httpclient.Fatal(err)
 				}
 			}
 			cmd.Spec = "[--private-key] [--session] [--environments] [--default] [--pods] [--all]"
@@ -0,0 +1,12 @@
+package clear
+
+import (
+	"os"
+	"testing"
+
+	"github.com/catalyzeio/cli/test"
+)
+
+func TestMain(m *testing.M) {
+	os.Exit(test.Main(m))
+}
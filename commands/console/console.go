@@ -1,13 +1,12 @@
 package console
 
 import (
+	"context"
 	"crypto/tls"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
-	"os"
-	"os/signal"
 	"runtime"
 	"strings"
 
@@ -15,6 +14,8 @@ import (
 
 	"github.com/Sirupsen/logrus"
 	"github.com/catalyzeio/cli/commands/services"
+	"github.com/catalyzeio/cli/lib/interrupt"
+	"github.com/catalyzeio/cli/lib/jobs"
 	"github.com/catalyzeio/cli/models"
 	"github.com/docker/docker/pkg/term"
 )
@@ -64,6 +65,12 @@ func (c *SConsole) Open(command string, service *models.Service) error {
 	if err != nil {
 		return err
 	}
+	// the console job is deleted even if the command timed out or was
+	// interrupted, when the command's own context is already done
+	defer interrupt.OnInterrupt(interrupt.Jobs, func(ctx context.Context) error {
+		return c.destroyWithContext(ctx, job.ID, service)
+	})()
+	defer c.destroyWithContext(interrupt.Context(), job.ID, service)
 	// all because logrus treats print, println, and printf the same
 	logrus.StandardLogger().Out.Write([]byte(fmt.Sprintf("Waiting for the console (job ID = %s) to be ready. This might take a minute.", job.ID)))
 
@@ -83,7 +90,6 @@ func (c *SConsole) Open(command string, service *models.Service) error {
 		return fmt.Errorf("\nCould not open a console connection. Entered state '%s'", status)
 	}
 	job.Status = status
-	defer c.Destroy(job.ID, service)
 	creds, err := c.RetrieveTokens(job.ID, service)
 	if err != nil {
 		return err
@@ -110,8 +116,9 @@ func (c *SConsole) Open(command string, service *models.Service) error {
 		return err
 	}
 	defer term.RestoreTerminal(fdIn, oldState)
-
-	signal.Notify(make(chan os.Signal, 1), os.Interrupt)
+	defer interrupt.OnInterrupt(interrupt.Jobs, func(ctx context.Context) error {
+		return term.RestoreTerminal(fdIn, oldState)
+	})()
 
 	done := make(chan struct{}, 2)
 	go readWS(ws, stdout, done)
@@ -161,6 +168,14 @@ func (c *SConsole) Destroy(jobID string, service *models.Service) error {
 	return c.Jobs.Delete(jobID, service.ID)
 }
 
+// destroyWithContext deletes a console job with requests bound to the given
+// context instead of the command's
+func (c *SConsole) destroyWithContext(ctx context.Context, jobID string, service *models.Service) error {
+	settings := *c.Settings
+	settings.HTTPManager = settings.HTTPManager.WithContext(ctx)
+	return jobs.New(&settings).Delete(jobID, service.ID)
+}
+
 // Reads incoming data from the websocket and forwards it to stdout.
 func readWS(ws *websocket.Conn, t io.Writer, done chan struct{}) {
 	_, err := io.Copy(t, ws)
@@ -1,10 +1,11 @@
 package console
 
 import (
-	"github.com/Sirupsen/logrus"
 	"github.com/catalyzeio/cli/commands/services"
 	"github.com/catalyzeio/cli/config"
 	"github.com/catalyzeio/cli/lib/auth"
+	"github.com/catalyzeio/cli/lib/httpclient"
+	"github.com/catalyzeio/cli/lib/interrupt"
 	"github.com/catalyzeio/cli/lib/jobs"
 	"github.com/catalyzeio/cli/lib/prompts"
 	"github.com/catalyzeio/cli/models"
@@ -22,24 +23,32 @@ var Cmd = models.Command{
 		"When accessing a database service, the `COMMAND` argument is not needed because the appropriate prompt will be given to you. " +
 		"If you are connecting to an application service the `COMMAND` argument is required. Here are some sample commands\n\n" +
 		"```\ncatalyze -E \"<your_env_alias>\" console db01\n" +
-		"catalyze -E \"<your_env_alias>\" console app01 \"bundle exec rails console\"\n```",
+		"catalyze -E \"<your_env_alias>\" console app01 \"bundle exec rails console\"\n```\n\n" +
+		"Use `--timeout` to give up if the console is not ready within that many seconds. " +
+		"The console job is deleted when the console is closed, when it times out, and when the CLI is interrupted while waiting for it.",
 	CmdFunc: func(settings *models.Settings) func(cmd *cli.Cmd) {
 		return func(cmd *cli.Cmd) {
 			serviceName := cmd.StringArg("SERVICE_NAME", "", "The name of the service to open up a console for")
 			command := cmd.StringArg("COMMAND", "", "An optional command to run when the console becomes available")
+			timeout := cmd.IntOpt("timeout", 0, "The number of seconds to wait for the console to be ready before giving up, or 0 for no limit")
 			cmd.Action = func() {
-				if _, err := auth.New(settings, prompts.New()).Signin(); err != nil {
-					logrus.Fatal(err.Error())
+				cancel, err := interrupt.BindTimeout(settings, *timeout)
+				if err != nil {
+					httpclient.Fatal(err)
+				}
+				defer cancel()
+				if _, err := auth.New(settings, prompts.New(settings)).Signin(); err != nil {
+					httpclient.Fatal(err)
 				}
 				if err := config.CheckRequiredAssociation(true, true, settings); err != nil {
-					logrus.Fatal(err.Error())
+					httpclient.Fatal(err)
 				}
-				err := CmdConsole(*serviceName, *command, New(settings, jobs.New(settings)), services.New(settings))
+				err = CmdConsole(*serviceName, *command, New(settings, jobs.New(settings)), services.New(settings))
 				if err != nil {
-					logrus.Fatal(err.Error())
+					httpclient.Fatal(err)
 				}
 			}
-			cmd.Spec = "SERVICE_NAME [COMMAND]"
+			cmd.Spec = "SERVICE_NAME [COMMAND] [--timeout]"
 		}
 	},
 }
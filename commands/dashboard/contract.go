@@ -1,7 +1,7 @@
 package dashboard
 
 import (
-	"github.com/Sirupsen/logrus"
+	"github.com/catalyzeio/cli/lib/httpclient"
 	"github.com/catalyzeio/cli/models"
 	"github.com/jault3/mow.cli"
 )
@@ -18,7 +18,7 @@ var Cmd = models.Command{
 			cmd.Action = func() {
 				err := CmdDashboard(New(settings))
 				if err != nil {
-					logrus.Fatal(err.Error())
+					httpclient.Fatal(err)
 				}
 			}
 		}
@@ -1,12 +1,16 @@
 package db
 
 import (
+	"errors"
 	"fmt"
+	"sync"
 
 	"github.com/Sirupsen/logrus"
 	"github.com/catalyzeio/cli/commands/services"
 	"github.com/catalyzeio/cli/lib/jobs"
+	"github.com/catalyzeio/cli/lib/render"
 	"github.com/catalyzeio/cli/models"
+	"github.com/olekukonko/tablewriter"
 )
 
 func CmdBackup(databaseName string, skipPoll bool, id IDb, is services.IServices, ij jobs.IJobs) error {
@@ -39,7 +43,7 @@ func CmdBackup(databaseName string, skipPoll bool, id IDb, is services.IServices
 		}
 		job.Status = status
 		logrus.Printf("Ended in status '%s'", job.Status)
-		err = id.DumpLogs("backup", job, service)
+		err = id.DumpLogs(job, service)
 		if err != nil {
 			return err
 		}
@@ -53,6 +57,155 @@ func CmdBackup(databaseName string, skipPoll bool, id IDb, is services.IServices
 	return nil
 }
 
+// CmdBackupAll backs up every database service in the environment, starting up
+// to parallel backups at a time. Only starting the backups is limited, so the
+// next backup starts as soon as the previous one was accepted rather than
+// when it finishes. Unless skipPoll is given, every backup is polled until it
+// finishes and an error is returned if any of them did not finish
+// successfully.
+func CmdBackupAll(parallel int, skipPoll bool, id IDb, is services.IServices, ij jobs.IJobs, ir render.IRender) error {
+	if parallel < 1 {
+		return errors.New("--parallel must be at least 1")
+	}
+	svcs, err := is.List()
+	if err != nil {
+		return err
+	}
+	databases := []models.Service{}
+	if svcs != nil {
+		for _, svc := range *svcs {
+			if svc.Type == "database" {
+				databases = append(databases, svc)
+			}
+		}
+	}
+	if len(databases) == 0 {
+		return errors.New("No database services found in this environment")
+	}
+	logrus.Printf("Backing up %d database services", len(databases))
+	results := make([]models.BackupResult, len(databases))
+	sem := make(chan bool, parallel)
+	var wg sync.WaitGroup
+	for i := range databases {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results[i] = backupService(&databases[i], skipPoll, sem, id, ij)
+		}(i)
+	}
+	wg.Wait()
+
+	failed := 0
+	for _, result := range results {
+		if result.Error != "" || (!skipPoll && result.Status != "finished") {
+			failed++
+		}
+	}
+	err = ir.Render(results, func() error {
+		logrus.Println()
+		data := [][]string{{"SERVICE", "JOB ID", "STATUS", "ERROR"}}
+		for _, result := range results {
+			data = append(data, []string{result.ServiceLabel, result.JobID, result.Status, result.Error})
+		}
+
+		table := tablewriter.NewWriter(logrus.StandardLogger().Out)
+		table.SetBorder(false)
+		table.SetRowLine(false)
+		table.SetCenterSeparator("")
+		table.SetColumnSeparator("")
+		table.SetRowSeparator("")
+		table.AppendBulk(data)
+		table.Render()
+		if !skipPoll {
+			logrus.Println("\nYou can view the logs for a backup with the \"catalyze db logs DATABASE_NAME BACKUP_ID\" command")
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d backups did not finish successfully", failed, len(results))
+	}
+	return nil
+}
+
+// backupService starts a backup of a single service for CmdBackupAll and
+// polls it until it finishes unless skipPoll is given. A slot in sem is held
+// only while the backup is being started.
+func backupService(service *models.Service, skipPoll bool, sem chan bool, id IDb, ij jobs.IJobs) models.BackupResult {
+	result := models.BackupResult{ServiceID: service.ID, ServiceLabel: service.Label}
+	sem <- true
+	job, err := id.Backup(service)
+	<-sem
+	if err != nil {
+		result.Status = "error"
+		result.Error = err.Error()
+		return result
+	}
+	result.JobID = job.ID
+	result.Status = job.Status
+	logrus.Printf("Backup of %s started (job ID = %s)", service.Label, job.ID)
+	if skipPoll {
+		return result
+	}
+	if job.IsSnapshotBackup != nil && *job.IsSnapshotBackup {
+		if err = ij.WaitToAppear(job.ID, service.ID); err != nil {
+			result.Error = err.Error()
+			return result
+		}
+	}
+	status, err := ij.PollTillFinished(job.ID, service.ID)
+	if err != nil {
+		// the job most likely ended in a status other than finished
+		ended, retrieveErr := ij.Retrieve(job.ID, service.ID, false)
+		if retrieveErr != nil {
+			result.Status = "unknown"
+			result.Error = err.Error()
+			return result
+		}
+		status = ended.Status
+	}
+	result.Status = status
+	logrus.Printf("Backup of %s ended in status '%s'", service.Label, status)
+	return result
+}
+
+// safetyBackup creates a backup of the given service and waits for it to
+// finish. This is run before any operation that overwrites data.
+func safetyBackup(databaseName, action string, service *models.Service, id IDb, ij jobs.IJobs) error {
+	logrus.Printf("Backing up \"%s\" before performing the %s", databaseName, action)
+	job, err := id.Backup(service)
+	if err != nil {
+		return err
+	}
+	logrus.Printf("Backup started (job ID = %s)", job.ID)
+
+	// all because logrus treats print, println, and printf the same
+	logrus.Println("Polling until backup finishes.")
+	if job.IsSnapshotBackup != nil && *job.IsSnapshotBackup {
+		logrus.Printf("This is a snapshot backup, it may be a while before this backup shows up in the \"catalyze db list %s\" command.", databaseName)
+		err = ij.WaitToAppear(job.ID, service.ID)
+		if err != nil {
+			return err
+		}
+	}
+	status, err := ij.PollTillFinished(job.ID, service.ID)
+	if err != nil {
+		return err
+	}
+	job.Status = status
+	logrus.Printf("Ended in status '%s'", job.Status)
+	err = id.DumpLogs(job, service)
+	if err != nil {
+		return err
+	}
+	if job.Status != "finished" {
+		return fmt.Errorf("Job finished with invalid status %s", job.Status)
+	}
+	return nil
+}
+
 // Backup creates a new backup
 func (d *SDb) Backup(service *models.Service) (*models.Job, error) {
 	headers := d.Settings.HTTPManager.GetHeaders(d.Settings.SessionToken, d.Settings.Version, d.Settings.Pod, d.Settings.UsersID)
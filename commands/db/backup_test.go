@@ -0,0 +1,104 @@
+package db
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/catalyzeio/cli/models"
+	"github.com/catalyzeio/cli/test"
+)
+
+const backupCommandName = "backup"
+
+func TestBackupAll(t *testing.T) {
+	if err := test.SetUpGitRepo(); err != nil {
+		t.Fatal(err)
+	}
+	if err := test.SetUpAssociation(); err != nil {
+		t.Fatal(err)
+	}
+	originalServices := test.Server.Services[test.EnvID]
+	defer func() { test.Server.Services[test.EnvID] = originalServices }()
+	test.Server.Services[test.EnvID] = append(append([]models.Service{}, originalServices...),
+		models.Service{ID: "svc-db02", Label: "db02", Name: "mysql", Type: "database", Scale: 1},
+		models.Service{ID: "svc-mongo01", Label: "mongo01", Name: "mongodb", Type: "database", Scale: 1},
+	)
+	failBackup := func(w http.ResponseWriter, r *http.Request) {
+		job := test.Server.AddJob("svc-mongo01", models.Job{Type: "backup", Status: "failed"})
+		json.NewEncoder(w).Encode(job)
+	}
+	failPath := fmt.Sprintf("/environments/%s/services/svc-mongo01/backup", test.EnvID)
+	defer test.Server.Override("POST", failPath, nil)
+
+	var backupTests = []struct {
+		args           []string
+		failMongo      bool
+		expectErr      bool
+		expectedOutput string
+	}{
+		{[]string{"--all"}, false, false, `(?s)Backing up 3 database services.*SERVICE\s+JOB ID\s+STATUS.*db01\s+\S+\s+finished.*db02\s+\S+\s+finished.*mongo01\s+\S+\s+finished`},
+		{[]string{"--all", "--parallel", "1"}, true, true, `(?s)Backup of mongo01 ended in status 'failed'.*mongo01\s+\S+\s+failed.*1 of 3 backups did not finish successfully`},
+		{[]string{"--all", "-s"}, false, false, `(?s)db01\s+\S+\s+finished.*mongo01`},
+		{[]string{"--all", "--parallel", "0"}, false, true, "--parallel must be at least 1"},
+	}
+	for _, data := range backupTests {
+		t.Logf("Data: %+v", data)
+		if data.failMongo {
+			test.Server.Override("POST", failPath, failBackup)
+		} else {
+			test.Server.Override("POST", failPath, nil)
+		}
+		args := append([]string{"-E", test.Alias, "db", backupCommandName}, data.args...)
+		output, err := test.RunCommand(test.BinaryName, args)
+		if err != nil != data.expectErr {
+			t.Errorf("Unexpected error: %s", output)
+			continue
+		}
+		if !regexp.MustCompile(data.expectedOutput).MatchString(output) {
+			t.Errorf("Expected: %s. Found: %s", data.expectedOutput, output)
+		}
+	}
+}
+
+func TestBackupTimeout(t *testing.T) {
+	if err := test.SetUpGitRepo(); err != nil {
+		t.Fatal(err)
+	}
+	if err := test.SetUpAssociation(); err != nil {
+		t.Fatal(err)
+	}
+	backupPath := fmt.Sprintf("/environments/%s/services/%s/backup", test.EnvID, test.DBSvcID)
+	test.Server.Override("POST", backupPath, func(w http.ResponseWriter, r *http.Request) {
+		job := test.Server.AddJob(test.DBSvcID, models.Job{Type: "backup", Status: "running"})
+		json.NewEncoder(w).Encode(job)
+	})
+	defer test.Server.Override("POST", backupPath, nil)
+
+	var timeoutTests = []struct {
+		args           []string
+		expectedOutput string
+	}{
+		{[]string{test.DBLabel, "--timeout", "1"}, "Timed out before the command finished"},
+		{[]string{test.DBLabel, "--timeout=-1"}, "--timeout can not be negative"},
+	}
+	for _, data := range timeoutTests {
+		t.Logf("Data: %+v", data)
+		args := append([]string{"-E", test.Alias, "db", backupCommandName}, data.args...)
+		start := time.Now()
+		output, err := test.RunCommand(test.BinaryName, args)
+		if err == nil {
+			t.Errorf("Expected an error: %s", output)
+			continue
+		}
+		if elapsed := time.Since(start); elapsed > 4*time.Second {
+			t.Errorf("Expected the command to stop within the timeout but it took %s", elapsed)
+		}
+		if !regexp.MustCompile(data.expectedOutput).MatchString(output) {
+			t.Errorf("Expected: %s. Found: %s", data.expectedOutput, output)
+		}
+	}
+}
@@ -2,15 +2,18 @@ package db
 
 import (
 	"io"
+	"os"
 
 	"github.com/Sirupsen/logrus"
 	"github.com/catalyzeio/cli/commands/services"
 	"github.com/catalyzeio/cli/config"
 	"github.com/catalyzeio/cli/lib/auth"
 	"github.com/catalyzeio/cli/lib/crypto"
+	"github.com/catalyzeio/cli/lib/httpclient"
+	"github.com/catalyzeio/cli/lib/interrupt"
 	"github.com/catalyzeio/cli/lib/jobs"
 	"github.com/catalyzeio/cli/lib/prompts"
-	"github.com/catalyzeio/cli/lib/transfer"
+	"github.com/catalyzeio/cli/lib/render"
 	"github.com/catalyzeio/cli/models"
 	"github.com/catalyzeio/gcm/gcm"
 	"github.com/jault3/mow.cli"
@@ -21,15 +24,21 @@ import (
 var Cmd = models.Command{
 	Name:      "db",
 	ShortHelp: "Tasks for databases",
-	LongHelp:  "The `db` command gives access to backup, import, and export services for databases. The db command can not be run directly but has sub commands.",
+	LongHelp: "The `db` command gives access to backup, import, and export services for databases. The db command can not be run directly but has sub commands. " +
+		"The backup, download, export, import, and restore sub commands accept `--timeout` to stop them once that many seconds have passed. " +
+		"If a sub command is interrupted with Ctrl-C, any temporary or partially written files are removed and the CLI exits with code 130. " +
+		"Partially downloaded backups with a `.partial` suffix are kept so that the download can be resumed.",
 	CmdFunc: func(settings *models.Settings) func(cmd *cli.Cmd) {
 		return func(cmd *cli.Cmd) {
 			cmd.CommandLong(BackupSubCmd.Name, BackupSubCmd.ShortHelp, BackupSubCmd.LongHelp, BackupSubCmd.CmdFunc(settings))
+			cmd.CommandLong(DecryptSubCmd.Name, DecryptSubCmd.ShortHelp, DecryptSubCmd.LongHelp, DecryptSubCmd.CmdFunc(settings))
 			cmd.CommandLong(DownloadSubCmd.Name, DownloadSubCmd.ShortHelp, DownloadSubCmd.LongHelp, DownloadSubCmd.CmdFunc(settings))
 			cmd.CommandLong(ExportSubCmd.Name, ExportSubCmd.ShortHelp, ExportSubCmd.LongHelp, ExportSubCmd.CmdFunc(settings))
 			cmd.CommandLong(ImportSubCmd.Name, ImportSubCmd.ShortHelp, ImportSubCmd.LongHelp, ImportSubCmd.CmdFunc(settings))
 			cmd.CommandLong(ListSubCmd.Name, ListSubCmd.ShortHelp, ListSubCmd.LongHelp, ListSubCmd.CmdFunc(settings))
 			cmd.CommandLong(LogsSubCmd.Name, LogsSubCmd.ShortHelp, LogsSubCmd.LongHelp, LogsSubCmd.CmdFunc(settings))
+			cmd.CommandLong(PruneSubCmd.Name, PruneSubCmd.ShortHelp, PruneSubCmd.LongHelp, PruneSubCmd.CmdFunc(settings))
+			cmd.CommandLong(RestoreSubCmd.Name, RestoreSubCmd.ShortHelp, RestoreSubCmd.LongHelp, RestoreSubCmd.CmdFunc(settings))
 		}
 	},
 }
@@ -41,24 +50,68 @@ var BackupSubCmd = models.Command{
 		"The backup is started and unless `-s` is specified, the CLI will poll every few seconds until it finishes. " +
 		"Regardless of a successful backup or not, the logs for the backup will be printed to the console when the backup is finished. " +
 		"If an error occurs and the logs are not printed, you can use the [db logs](#db-logs) command to print out historical backup job logs. Here is a sample command\n\n" +
-		"```\ncatalyze -E \"<your_env_alias>\" db backup db01\n```",
+		"```\ncatalyze -E \"<your_env_alias>\" db backup db01\n```\n\n" +
+		"To back up every database service in the environment at once, specify `--all` instead of a database name. " +
+		"Up to `--parallel` backups are started at the same time and all of them are polled until they finish. " +
+		"A summary of every backup is printed at the end and the command fails if any backup did not finish successfully. " +
+		"The logs for each backup are not printed, use the [db logs](#db-logs) command to view them. Here is a sample command\n\n" +
+		"```\ncatalyze -E \"<your_env_alias>\" db backup --all\n```",
 	CmdFunc: func(settings *models.Settings) func(cmd *cli.Cmd) {
 		return func(subCmd *cli.Cmd) {
 			databaseName := subCmd.StringArg("DATABASE_NAME", "", "The name of the database service to create a backup for (i.e. 'db01')")
+			all := subCmd.BoolOpt("all", false, "Back up every database service in the environment")
+			parallel := subCmd.IntOpt("parallel", 4, "The number of backups to start at the same time when backing up every database service")
 			skipPoll := subCmd.BoolOpt("s skip-poll", false, "Whether or not to wait for the backup to finish")
+			timeout := subCmd.IntOpt("timeout", 0, "The number of seconds the command may run for before it is stopped, or 0 for no limit")
 			subCmd.Action = func() {
-				if _, err := auth.New(settings, prompts.New()).Signin(); err != nil {
-					logrus.Fatal(err.Error())
+				cancel, err := interrupt.BindTimeout(settings, *timeout)
+				if err != nil {
+					httpclient.Fatal(err)
+				}
+				defer cancel()
+				if _, err := auth.New(settings, prompts.New(settings)).Signin(); err != nil {
+					httpclient.Fatal(err)
 				}
 				if err := config.CheckRequiredAssociation(true, true, settings); err != nil {
-					logrus.Fatal(err.Error())
+					httpclient.Fatal(err)
 				}
-				err := CmdBackup(*databaseName, *skipPoll, New(settings, crypto.New(), jobs.New(settings)), services.New(settings), jobs.New(settings))
+				if *all {
+					err = CmdBackupAll(*parallel, *skipPoll, New(settings, crypto.New(), jobs.New(settings)), services.New(settings), jobs.New(settings), render.New(settings))
+				} else {
+					err = CmdBackup(*databaseName, *skipPoll, New(settings, crypto.New(), jobs.New(settings)), services.New(settings), jobs.New(settings))
+				}
+				if err != nil {
+					httpclient.Fatal(err)
+				}
+			}
+			subCmd.Spec = "(DATABASE_NAME | --all [--parallel]) [-s] [--timeout]"
+		}
+	},
+}
+
+var DecryptSubCmd = models.Command{
+	Name:      "decrypt",
+	ShortHelp: "Decrypt a backup downloaded with the encrypted option",
+	LongHelp: "`db decrypt` decrypts a backup that was downloaded with `db download --encrypted`. " +
+		"This is done entirely offline so you do not need to be signed in or associated to an environment. " +
+		"The wrapped key is read from the file next to the backup with a `.key.json` suffix unless another file is given with `--key-file`. " +
+		"If the key was wrapped with a public key, the matching private key must be given with `--private-key`. Otherwise you are prompted for the passphrase. " +
+		"Be careful using this command as it could decrypt PHI. Here is a sample command\n\n" +
+		"```\ncatalyze db decrypt ./db.sql.enc ./db.sql --private-key ~/.ssh/backups\n```",
+	CmdFunc: func(settings *models.Settings) func(cmd *cli.Cmd) {
+		return func(subCmd *cli.Cmd) {
+			encryptedPath := subCmd.StringArg("ENCRYPTED_FILEPATH", "", "The location of the encrypted backup")
+			filePath := subCmd.StringArg("FILEPATH", "", "The location to save the decrypted backup to. This location must NOT already exist unless -f is specified")
+			keyFile := subCmd.StringOpt("k key-file", "", "The file holding the wrapped key. Defaults to the encrypted backup's location with a .key.json suffix")
+			privateKey := subCmd.StringOpt("private-key", "", "The RSA private key matching the public key the backup's key was wrapped with")
+			force := subCmd.BoolOpt("f force", false, "If a file previously exists at \"filepath\", overwrite it")
+			subCmd.Action = func() {
+				err := CmdDecrypt(*encryptedPath, *filePath, *keyFile, *privateKey, *force, crypto.New(), prompts.New(settings))
 				if err != nil {
-					logrus.Fatal(err.Error())
+					httpclient.Fatal(err)
 				}
 			}
-			subCmd.Spec = "DATABASE_NAME [-s]"
+			subCmd.Spec = "ENCRYPTED_FILEPATH FILEPATH [-k] [--private-key] [-f]"
 		}
 	},
 }
@@ -72,26 +125,45 @@ var DownloadSubCmd = models.Command{
 		"The ID of the backup is found by first running the [db list](#db-list) command. Here is a sample command\n\n" +
 		"```\ncatalyze -E \"<your_env_alias>\" db download db01 cd2b4bce-2727-42d1-89e0-027bf3f1a203 ./db.sql\n```\n\n" +
 		"This assumes you are downloading a MySQL or PostgreSQL backup which takes the `.sql` file format. If you are downloading a mongo backup, the command might look like this\n\n" +
-		"```\ncatalyze -E \"<your_env_alias>\" db download db01 cd2b4bce-2727-42d1-89e0-027bf3f1a203 ./db.tar.gz\n```",
+		"```\ncatalyze -E \"<your_env_alias>\" db download db01 cd2b4bce-2727-42d1-89e0-027bf3f1a203 ./db.tar.gz\n```\n\n" +
+		"To keep a backup in cold storage, specify `--encrypted` to save the backup exactly as it is stored without decrypting it. " +
+		"The key needed to decrypt it is saved next to the backup in a file with a `.key.json` suffix. " +
+		"The key is wrapped with the RSA public key given with `--public-key`, or with a passphrase you are prompted for if no public key is given. " +
+		"In non-interactive mode the passphrase is read from the `CATALYZE_BACKUP_PASSPHRASE` or `CATALYZE_BACKUP_PASSPHRASE_FILE` environment variables. " +
+		"Use the [db decrypt](#db-decrypt) command to decrypt the backup later. Here is a sample command\n\n" +
+		"```\ncatalyze -E \"<your_env_alias>\" db download db01 cd2b4bce-2727-42d1-89e0-027bf3f1a203 ./db.sql.enc --encrypted --public-key ~/.ssh/backups.pub\n```\n\n" +
+		"The backup is downloaded in parts of `--part-size` MB, `--parallel` parts at a time, to a file next to FILEPATH with a `.partial` suffix. " +
+		"If the download is interrupted, run the same command again to resume it. " +
+		"Once every part has been downloaded the backup is decrypted and verified, and only then saved to FILEPATH.",
 	CmdFunc: func(settings *models.Settings) func(cmd *cli.Cmd) {
 		return func(subCmd *cli.Cmd) {
 			databaseName := subCmd.StringArg("DATABASE_NAME", "", "The name of the database service which was backed up (i.e. 'db01')")
 			backupID := subCmd.StringArg("BACKUP_ID", "", "The ID of the backup to download (found from \"catalyze backup list\")")
 			filePath := subCmd.StringArg("FILEPATH", "", "The location to save the downloaded backup to. This location must NOT already exist unless -f is specified")
 			force := subCmd.BoolOpt("f force", false, "If a file previously exists at \"filepath\", overwrite it and download the backup")
+			encrypted := subCmd.BoolOpt("e encrypted", false, "Save the backup without decrypting it along with a file holding its wrapped key")
+			publicKey := subCmd.StringOpt("public-key", "", "The RSA public key to wrap the key of an encrypted download with. If not given, a passphrase is used")
+			parallel := subCmd.IntOpt("parallel", 4, "The number of parts to download at the same time")
+			partSize := subCmd.IntOpt("part-size", 16, "The size in MB of each part the backup is downloaded in. Ignored when resuming a download")
+			timeout := subCmd.IntOpt("timeout", 0, "The number of seconds the command may run for before it is stopped, or 0 for no limit")
 			subCmd.Action = func() {
-				if _, err := auth.New(settings, prompts.New()).Signin(); err != nil {
-					logrus.Fatal(err.Error())
+				cancel, err := interrupt.BindTimeout(settings, *timeout)
+				if err != nil {
+					httpclient.Fatal(err)
+				}
+				defer cancel()
+				if _, err := auth.New(settings, prompts.New(settings)).Signin(); err != nil {
+					httpclient.Fatal(err)
 				}
 				if err := config.CheckRequiredAssociation(true, true, settings); err != nil {
-					logrus.Fatal(err.Error())
+					httpclient.Fatal(err)
 				}
-				err := CmdDownload(*databaseName, *backupID, *filePath, *force, New(settings, crypto.New(), jobs.New(settings)), prompts.New(), services.New(settings))
+				err = CmdDownload(*databaseName, *backupID, *filePath, *force, *encrypted, *publicKey, *parallel, *partSize, New(settings, crypto.New(), jobs.New(settings)), prompts.New(settings), services.New(settings), crypto.New())
 				if err != nil {
-					logrus.Fatal(err.Error())
+					httpclient.Fatal(err)
 				}
 			}
-			subCmd.Spec = "DATABASE_NAME BACKUP_ID FILEPATH [-f]"
+			subCmd.Spec = "DATABASE_NAME BACKUP_ID FILEPATH [-f] [-e [--public-key]] [--parallel] [--part-size] [--timeout]"
 		}
 	},
 }
@@ -105,25 +177,41 @@ var ExportSubCmd = models.Command{
 		"If an error occurs and the logs are not printed, you can use the [db logs](#db-logs) command to print out historical backup job logs. Here is a sample command\n\n" +
 		"```\ncatalyze -E \"<your_env_alias>\" db export db01 ./dbexport.sql\n```\n\n" +
 		"This assumes you are exporting a MySQL or PostgreSQL database which takes the `.sql` file format. If you are exporting a mongo database, the command might look like this\n\n" +
-		"```\ncatalyze -E \"<your_env_alias>\" db export db01 ./dbexport.tar.gz\n```",
+		"```\ncatalyze -E \"<your_env_alias>\" db export db01 ./dbexport.tar.gz\n```\n\n" +
+		"Like [db download](#db-download), the backup is downloaded in parallel parts and is only saved to FILEPATH once it has been completely downloaded, decrypted, and verified.\n\n" +
+		"If FILEPATH is `-` the exported data is written to stdout and all other output is written to stderr, so the decrypted data never touches the disk. " +
+		"This is useful for piping the data straight into a local database client. Here is a sample command\n\n" +
+		"```\ncatalyze -E \"<your_env_alias>\" db export db01 - | psql localdev\n```",
 	CmdFunc: func(settings *models.Settings) func(cmd *cli.Cmd) {
 		return func(subCmd *cli.Cmd) {
 			databaseName := subCmd.StringArg("DATABASE_NAME", "", "The name of the database to export data from (i.e. 'db01')")
-			filePath := subCmd.StringArg("FILEPATH", "", "The location to save the exported data. This location must NOT already exist unless -f is specified")
+			filePath := subCmd.StringArg("FILEPATH", "", "The location to save the exported data, or - to write it to stdout. This location must NOT already exist unless -f is specified")
 			force := subCmd.BoolOpt("f force", false, "If a file previously exists at `filepath`, overwrite it and export data")
+			parallel := subCmd.IntOpt("parallel", 4, "The number of parts to download at the same time")
+			partSize := subCmd.IntOpt("part-size", 16, "The size in MB of each part the backup is downloaded in")
+			timeout := subCmd.IntOpt("timeout", 0, "The number of seconds the command may run for before it is stopped, or 0 for no limit")
 			subCmd.Action = func() {
-				if _, err := auth.New(settings, prompts.New()).Signin(); err != nil {
-					logrus.Fatal(err.Error())
+				if *filePath == stdoutPath {
+					// stdout holds the exported data so everything else goes to stderr
+					logrus.SetOutput(os.Stderr)
+				}
+				cancel, err := interrupt.BindTimeout(settings, *timeout)
+				if err != nil {
+					httpclient.Fatal(err)
+				}
+				defer cancel()
+				if _, err := auth.New(settings, prompts.New(settings)).Signin(); err != nil {
+					httpclient.Fatal(err)
 				}
 				if err := config.CheckRequiredAssociation(true, true, settings); err != nil {
-					logrus.Fatal(err.Error())
+					httpclient.Fatal(err)
 				}
-				err := CmdExport(*databaseName, *filePath, *force, New(settings, crypto.New(), jobs.New(settings)), prompts.New(), services.New(settings), jobs.New(settings))
+				err = CmdExport(*databaseName, *filePath, *force, *parallel, *partSize, New(settings, crypto.New(), jobs.New(settings)), prompts.New(settings), services.New(settings), jobs.New(settings))
 				if err != nil {
-					logrus.Fatal(err.Error())
+					httpclient.Fatal(err)
 				}
 			}
-			subCmd.Spec = "DATABASE_NAME FILEPATH [-f]"
+			subCmd.Spec = "DATABASE_NAME FILEPATH [-f] [--parallel] [--part-size] [--timeout]"
 		}
 	},
 }
@@ -140,28 +228,55 @@ var ImportSubCmd = models.Command{
 		"and stored it at `./db.sql` you could import this into your database service. " +
 		"When importing data into mongo, you may specify the database and collection to import into using the `-d` and `-c` flags respectively. " +
 		"Regardless of a successful import or not, the logs for the import will be printed to the console when the import is finished. " +
-		"Before an import takes place, your database is backed up automatically in case any issues arise. Here is a sample command\n\n" +
-		"```\ncatalyze -E \"<your_env_alias>\" db import db01 ./db.sql\n```",
+		"Before an import takes place, your database is backed up automatically in case any issues arise. " +
+		"The file is encrypted and uploaded in a single request of at most 5 GB. " +
+		"On pods that accept multipart imports, the file is instead uploaded in parts of `--part-size` MB with no size limit, and each part is retried on its own if it fails. " +
+		"If a multipart upload is interrupted, run the same command again to resume it from the last part that was uploaded. " +
+		"The import is only started once every part has been uploaded. " +
+		"Gzip and zstd compressed files are decompressed before they are encrypted, which requires the `zstd` command for zstd files. Mongo archives are always imported as they are. " +
+		"Pass `-` as the file path to import from stdin so a dump never has to be written to disk. " +
+		"Since stdin is used for the data, no prompts are shown and the global `--yes` option is needed to skip the backup. " +
+		"Uploads of stdin and compressed files can not be resumed. Their encrypted data is written to a temporary file before it is uploaded unless the pod accepts multipart imports of unknown length, in which case it is uploaded as it is read. " +
+		"Before anything is backed up or uploaded, the start of the file is checked to make sure it is in a format the database can import. " +
+		"PostgreSQL and MySQL databases take plain SQL dumps, so a pg_dump custom format archive or a dump from the other database is rejected. " +
+		"Mongo databases take a gzipped tar of a mongodump output directory or JSON. " +
+		"Use `--validate-only` to check a file without importing it, or `--skip-validation` to import a file that fails the check anyway. Here are some sample commands\n\n" +
+		"```\ncatalyze -E \"<your_env_alias>\" db import db01 ./db.sql\n" +
+		"pg_dump mydb | catalyze -E \"<your_env_alias>\" db import db01 -\n" +
+		"catalyze -E \"<your_env_alias>\" db import db01 ./db.sql.gz\n```",
 	CmdFunc: func(settings *models.Settings) func(cmd *cli.Cmd) {
 		return func(subCmd *cli.Cmd) {
 			databaseName := subCmd.StringArg("DATABASE_NAME", "", "The name of the database to import data to (i.e. 'db01')")
-			filePath := subCmd.StringArg("FILEPATH", "", "The location of the file to import to the database")
+			filePath := subCmd.StringArg("FILEPATH", "", "The location of the file to import to the database, or '-' to import from stdin")
 			mongoCollection := subCmd.StringOpt("c mongo-collection", "", "If importing into a mongo service, the name of the collection to import into")
 			mongoDatabase := subCmd.StringOpt("d mongo-database", "", "If importing into a mongo service, the name of the database to import into")
 			skipBackup := subCmd.BoolOpt("s skip-backup", false, "Skip backing up database. Useful for large databases, which can have long backup times.")
+			partSize := subCmd.IntOpt("part-size", 64, "The size in MB of each part the file is uploaded in on pods that accept multipart imports. Ignored when resuming an upload")
+			validateOnly := subCmd.BoolOpt("validate-only", false, "Check that the file can be imported into the database without importing it")
+			skipValidation := subCmd.BoolOpt("skip-validation", false, "Import the file even if it does not look like a format the database can import")
+			timeout := subCmd.IntOpt("timeout", 0, "The number of seconds the command may run for before it is stopped, or 0 for no limit")
 			subCmd.Action = func() {
-				if _, err := auth.New(settings, prompts.New()).Signin(); err != nil {
-					logrus.Fatal(err.Error())
+				if *filePath == stdinPath {
+					// stdin holds the data to import so it can't answer prompts
+					settings.NonInteractive = true
+				}
+				cancel, err := interrupt.BindTimeout(settings, *timeout)
+				if err != nil {
+					httpclient.Fatal(err)
+				}
+				defer cancel()
+				if _, err := auth.New(settings, prompts.New(settings)).Signin(); err != nil {
+					httpclient.Fatal(err)
 				}
 				if err := config.CheckRequiredAssociation(true, true, settings); err != nil {
-					logrus.Fatal(err.Error())
+					httpclient.Fatal(err)
 				}
-				err := CmdImport(*databaseName, *filePath, *mongoCollection, *mongoDatabase, *skipBackup, New(settings, crypto.New(), jobs.New(settings)), prompts.New(), services.New(settings), jobs.New(settings))
+				err = CmdImport(*databaseName, *filePath, *mongoCollection, *mongoDatabase, *partSize, *skipBackup, *validateOnly, *skipValidation, settings, New(settings, crypto.New(), jobs.New(settings)), prompts.New(settings), services.New(settings), jobs.New(settings))
 				if err != nil {
-					logrus.Fatal(err.Error())
+					httpclient.Fatal(err)
 				}
 			}
-			subCmd.Spec = "DATABASE_NAME FILEPATH [-s][-d [-c]]"
+			subCmd.Spec = "DATABASE_NAME FILEPATH [-s][-d [-c]][--part-size][--validate-only | --skip-validation][--timeout]"
 		}
 	},
 }
@@ -178,15 +293,15 @@ var ListSubCmd = models.Command{
 			page := subCmd.IntOpt("p page", 1, "The page to view")
 			pageSize := subCmd.IntOpt("n page-size", 10, "The number of items to show per page")
 			subCmd.Action = func() {
-				if _, err := auth.New(settings, prompts.New()).Signin(); err != nil {
-					logrus.Fatal(err.Error())
+				if _, err := auth.New(settings, prompts.New(settings)).Signin(); err != nil {
+					httpclient.Fatal(err)
 				}
 				if err := config.CheckRequiredAssociation(true, true, settings); err != nil {
-					logrus.Fatal(err.Error())
+					httpclient.Fatal(err)
 				}
-				err := CmdList(*databaseName, *page, *pageSize, New(settings, crypto.New(), jobs.New(settings)), services.New(settings))
+				err := CmdList(*databaseName, *page, *pageSize, New(settings, crypto.New(), jobs.New(settings)), services.New(settings), render.New(settings))
 				if err != nil {
-					logrus.Fatal(err.Error())
+					httpclient.Fatal(err)
 				}
 			}
 			subCmd.Spec = "DATABASE_NAME [-p] [-n]"
@@ -205,15 +320,15 @@ var LogsSubCmd = models.Command{
 			databaseName := subCmd.StringArg("DATABASE_NAME", "", "The name of the database service (i.e. 'db01')")
 			backupID := subCmd.StringArg("BACKUP_ID", "", "The ID of the backup to download logs from (found from \"catalyze backup list\")")
 			subCmd.Action = func() {
-				if _, err := auth.New(settings, prompts.New()).Signin(); err != nil {
-					logrus.Fatal(err.Error())
+				if _, err := auth.New(settings, prompts.New(settings)).Signin(); err != nil {
+					httpclient.Fatal(err)
 				}
 				if err := config.CheckRequiredAssociation(true, true, settings); err != nil {
-					logrus.Fatal(err.Error())
+					httpclient.Fatal(err)
 				}
 				err := CmdLogs(*databaseName, *backupID, New(settings, crypto.New(), jobs.New(settings)), services.New(settings), jobs.New(settings))
 				if err != nil {
-					logrus.Fatal(err.Error())
+					httpclient.Fatal(err)
 				}
 			}
 			subCmd.Spec = "DATABASE_NAME BACKUP_ID"
@@ -221,17 +336,99 @@ var LogsSubCmd = models.Command{
 	},
 }
 
+var PruneSubCmd = models.Command{
+	Name:      "prune",
+	ShortHelp: "Delete backups that are no longer needed by a retention policy",
+	LongHelp: "`db prune` enforces a grandfather-father-son retention policy on the backups of a database service. " +
+		"The newest backup of each of the last `--daily` days, `--weekly` weeks, `--monthly` months, and `--yearly` years is kept and all other finished backups are permanently deleted. " +
+		"Weeks start on Monday and all periods are calculated in UTC. " +
+		"Backups that are still running or did not finish successfully are never deleted. " +
+		"Use `--dry-run` to preview which backups would be kept and deleted without deleting anything. Here is a sample command\n\n" +
+		"```\ncatalyze -E \"<your_env_alias>\" db prune db01 --daily 7 --weekly 4 --monthly 12\n```",
+	CmdFunc: func(settings *models.Settings) func(cmd *cli.Cmd) {
+		return func(subCmd *cli.Cmd) {
+			databaseName := subCmd.StringArg("DATABASE_NAME", "", "The name of the database service to prune backups for (i.e. 'db01')")
+			daily := subCmd.IntOpt("daily", 7, "The number of daily backups to keep")
+			weekly := subCmd.IntOpt("weekly", 4, "The number of weekly backups to keep")
+			monthly := subCmd.IntOpt("monthly", 12, "The number of monthly backups to keep")
+			yearly := subCmd.IntOpt("yearly", 0, "The number of yearly backups to keep")
+			dryRun := subCmd.BoolOpt("dry-run", false, "List the backups that would be kept and deleted without deleting anything")
+			subCmd.Action = func() {
+				if _, err := auth.New(settings, prompts.New(settings)).Signin(); err != nil {
+					httpclient.Fatal(err)
+				}
+				if err := config.CheckRequiredAssociation(true, true, settings); err != nil {
+					httpclient.Fatal(err)
+				}
+				err := CmdPrune(*databaseName, *daily, *weekly, *monthly, *yearly, *dryRun, New(settings, crypto.New(), jobs.New(settings)), prompts.New(settings), services.New(settings), jobs.New(settings), render.New(settings))
+				if err != nil {
+					httpclient.Fatal(err)
+				}
+			}
+			subCmd.Spec = "DATABASE_NAME [--daily] [--weekly] [--monthly] [--yearly] [--dry-run]"
+		}
+	},
+}
+
+var RestoreSubCmd = models.Command{
+	Name:      "restore",
+	ShortHelp: "Restore a database from a previously created backup",
+	LongHelp: "`db restore` replaces all data in a database service with the contents of a previously created backup of the same service. " +
+		"Unlike [db import](#db-import), the backup never leaves the platform so there is no limit on its size. " +
+		"Before restoring, a new backup of the database is created so that you can undo the restore if needed. " +
+		"This can be skipped with the `--skip-backup` flag. " +
+		"The ID of the backup is found by first running the [db list](#db-list) command. " +
+		"The logs for the restore are printed to the console when it finishes. Here is a sample command\n\n" +
+		"```\ncatalyze -E \"<your_env_alias>\" db restore db01 cd2b4bce-2727-42d1-89e0-027bf3f1a203\n```",
+	CmdFunc: func(settings *models.Settings) func(cmd *cli.Cmd) {
+		return func(subCmd *cli.Cmd) {
+			databaseName := subCmd.StringArg("DATABASE_NAME", "", "The name of the database service to restore (i.e. 'db01')")
+			backupID := subCmd.StringArg("BACKUP_ID", "", "The ID of the backup to restore (found from \"catalyze db list\")")
+			skipBackup := subCmd.BoolOpt("s skip-backup", false, "Skip backing up the database before restoring")
+			timeout := subCmd.IntOpt("timeout", 0, "The number of seconds the command may run for before it is stopped, or 0 for no limit")
+			subCmd.Action = func() {
+				cancel, err := interrupt.BindTimeout(settings, *timeout)
+				if err != nil {
+					httpclient.Fatal(err)
+				}
+				defer cancel()
+				if _, err := auth.New(settings, prompts.New(settings)).Signin(); err != nil {
+					httpclient.Fatal(err)
+				}
+				if err := config.CheckRequiredAssociation(true, true, settings); err != nil {
+					httpclient.Fatal(err)
+				}
+				err = CmdRestore(*databaseName, *backupID, *skipBackup, New(settings, crypto.New(), jobs.New(settings)), prompts.New(settings), services.New(settings), jobs.New(settings))
+				if err != nil {
+					httpclient.Fatal(err)
+				}
+			}
+			subCmd.Spec = "DATABASE_NAME BACKUP_ID [-s] [--timeout]"
+		}
+	},
+}
+
 // IDb
 type IDb interface {
 	Backup(service *models.Service) (*models.Job, error)
-	Download(backupID, filePath string, service *models.Service) error
-	Export(filePath string, job *models.Job, service *models.Service) error
-	Import(rt *transfer.ReaderTransfer, key, iv []byte, mongoCollection, mongoDatabase string, service *models.Service) (*models.Job, error)
+	Download(backupID, filePath string, parallel, partSize int, service *models.Service) error
+	DownloadEncrypted(backupID, filePath string, parallel, partSize int, service *models.Service) (*models.Job, error)
+	DownloadSize(url string) (int64, bool, error)
+	DownloadRange(url string, offset, length int64, w io.Writer) (bool, error)
+	Export(filePath string, parallel, partSize int, job *models.Job, service *models.Service) error
+	Import(filename string, key, iv []byte, mongoCollection, mongoDatabase string, service *models.Service) (*models.Job, error)
+	TempUploadURL(service *models.Service) (*models.TempURL, error)
+	UploadSingle(r io.Reader, length int64, service *models.Service) (string, error)
+	StartUpload(size int64, service *models.Service) (*models.Upload, error)
+	TempPartURL(upload *models.Upload, number int, service *models.Service) (*models.TempURL, error)
+	UploadPart(url string, body io.Reader, length int64) (string, bool, error)
+	CompleteUpload(upload *models.Upload, parts []models.UploadPart, service *models.Service) error
 	List(page, pageSize int, service *models.Service) (*[]models.Job, error)
+	Restore(backupID string, service *models.Service) (*models.Job, error)
 	TempDownloadURL(jobID string, service *models.Service) (*models.TempURL, error)
-	TempLogsURL(jobID string, serviceID string) (*models.TempURL, error)
-	DumpLogs(taskType string, job *models.Job, service *models.Service) error
+	DumpLogs(job *models.Job, service *models.Service) error
 	NewEncryptReader(reader io.Reader, key, iv []byte) (*gcm.EncryptReader, error)
+	NewEncryptReaderAt(reader io.Reader, key, iv []byte, offset, length int64) (io.Reader, int64, error)
 }
 
 // SDb is a concrete implementation of IDb
@@ -253,3 +450,7 @@ func New(settings *models.Settings, crypto crypto.ICrypto, jobs jobs.IJobs) IDb
 func (db *SDb) NewEncryptReader(reader io.Reader, key, iv []byte) (*gcm.EncryptReader, error) {
 	return db.Crypto.NewEncryptReader(reader, key, iv)
 }
+
+func (db *SDb) NewEncryptReaderAt(reader io.Reader, key, iv []byte, offset, length int64) (io.Reader, int64, error) {
+	return db.Crypto.NewEncryptReaderAt(reader, key, iv, offset, length)
+}
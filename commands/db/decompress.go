@@ -0,0 +1,106 @@
+package db
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os/exec"
+	"strings"
+)
+
+const (
+	gzipCompression = "gzip"
+	zstdCompression = "zstd"
+)
+
+var (
+	gzipMagic = []byte{0x1f, 0x8b}
+	zstdMagic = []byte{0x28, 0xb5, 0x2f, 0xfd}
+)
+
+// detectCompression peeks at the start of the given reader and returns the
+// compression format it uses, or an empty string if it is not compressed.
+func detectCompression(br *bufio.Reader) string {
+	header, _ := br.Peek(len(zstdMagic))
+	switch {
+	case bytes.HasPrefix(header, gzipMagic):
+		return gzipCompression
+	case bytes.HasPrefix(header, zstdMagic):
+		return zstdCompression
+	}
+	return ""
+}
+
+// decompress returns a reader of the decompressed contents of r. Zstandard is
+// decompressed by the zstd command which must be installed. Closing the
+// returned reader reports any error from the decompression.
+func decompress(r io.Reader, compression string) (io.ReadCloser, error) {
+	switch compression {
+	case gzipCompression:
+		return gzip.NewReader(r)
+	case zstdCompression:
+		if _, err := exec.LookPath("zstd"); err != nil {
+			return nil, fmt.Errorf("The zstd command is required to import zstd compressed files. Install zstd or decompress the file before importing it")
+		}
+		cmd := exec.Command("zstd", "--decompress", "--stdout", "--quiet")
+		cmd.Stdin = r
+		stderr := &bytes.Buffer{}
+		cmd.Stderr = stderr
+		stdout, err := cmd.StdoutPipe()
+		if err != nil {
+			return nil, err
+		}
+		if err = cmd.Start(); err != nil {
+			return nil, err
+		}
+		return &zstdReader{ReadCloser: stdout, cmd: cmd, stderr: stderr}, nil
+	}
+	return ioutil.NopCloser(r), nil
+}
+
+// zstdReader reads the output of the zstd command
+type zstdReader struct {
+	io.ReadCloser
+	cmd    *exec.Cmd
+	stderr *bytes.Buffer
+}
+
+// Read returns an error if zstd fails so that a corrupt file is never mistaken
+// for the end of the data
+func (z *zstdReader) Read(p []byte) (int, error) {
+	if z.cmd.ProcessState != nil {
+		// the output was already read in full and its pipe closed by wait
+		if err := z.wait(); err != nil {
+			return 0, err
+		}
+		return 0, io.EOF
+	}
+	n, err := z.ReadCloser.Read(p)
+	if err == io.EOF {
+		if waitErr := z.wait(); waitErr != nil {
+			return n, waitErr
+		}
+	}
+	return n, err
+}
+
+func (z *zstdReader) Close() error {
+	z.ReadCloser.Close()
+	return z.wait()
+}
+
+func (z *zstdReader) wait() error {
+	if z.cmd.ProcessState != nil {
+		if !z.cmd.ProcessState.Success() {
+			return fmt.Errorf("Failed to decompress the zstd input: %s", strings.TrimSpace(z.stderr.String()))
+		}
+		return nil
+	}
+	if err := z.cmd.Wait(); err != nil {
+		return fmt.Errorf("Failed to decompress the zstd input: %s", strings.TrimSpace(z.stderr.String()))
+	}
+	return nil
+}
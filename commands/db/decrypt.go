@@ -0,0 +1,192 @@
+package db
+
+import (
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/binary"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math"
+	"math/big"
+	"os"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/catalyzeio/cli/lib/crypto"
+	"github.com/catalyzeio/cli/lib/interrupt"
+	"github.com/catalyzeio/cli/lib/prompts"
+	"github.com/catalyzeio/cli/models"
+	"github.com/mitchellh/go-homedir"
+	"golang.org/x/crypto/ssh"
+)
+
+// CmdDecrypt decrypts a backup downloaded with `db download --encrypted`
+// using the wrapped key in its sidecar file. No API calls are made so this
+// works entirely offline.
+func CmdDecrypt(encryptedPath, filePath, keyPath, privateKeyPath string, force bool, ic crypto.ICrypto, ip prompts.IPrompts) error {
+	if keyPath == "" {
+		keyPath = keyFilePath(encryptedPath)
+	}
+	if !force {
+		if _, err := os.Stat(filePath); err == nil {
+			return fmt.Errorf("File already exists at path '%s'. Specify `--force` to overwrite", filePath)
+		}
+	}
+	b, err := ioutil.ReadFile(keyPath)
+	if err != nil {
+		return fmt.Errorf("Could not read the key file for the backup: %s", err)
+	}
+	var backupKey models.BackupKey
+	if err = json.Unmarshal(b, &backupKey); err != nil {
+		return fmt.Errorf("Invalid key file '%s': %s", keyPath, err)
+	}
+	var privateKey *rsa.PrivateKey
+	var passphrase string
+	if backupKey.Key.Method == crypto.WrapRSA {
+		if privateKeyPath == "" {
+			return errors.New("The key for this backup was wrapped with a public key. Specify the matching private key with --private-key")
+		}
+		privateKey, err = readPrivateKey(privateKeyPath, ip)
+	} else {
+		passphrase, err = ip.BackupPassphrase(false)
+	}
+	if err != nil {
+		return err
+	}
+	store, err := ic.UnwrapKey(&backupKey.Key, privateKey, passphrase)
+	if err != nil {
+		return err
+	}
+	logrus.Printf("Decrypting backup %s of %s", backupKey.JobID, backupKey.ServiceLabel)
+	encrypted, err := os.Open(encryptedPath)
+	if err != nil {
+		return err
+	}
+	defer encrypted.Close()
+	file, err := os.OpenFile(filePath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	defer interrupt.RemoveOnInterrupt(filePath)()
+	dfw, err := ic.NewDecryptWriteCloser(file, store.Key, store.IV)
+	if err == nil {
+		_, err = io.Copy(dfw, encrypted)
+		if closeErr := dfw.Close(); err == nil {
+			err = closeErr
+		}
+	} else {
+		file.Close()
+	}
+	if err != nil {
+		// never leave partially decrypted data behind
+		os.Remove(filePath)
+		return fmt.Errorf("Failed to decrypt the backup: %s", err)
+	}
+	logrus.Printf("Backup decrypted successfully to %s", filePath)
+	return nil
+}
+
+// readPublicKey reads an RSA public key in the OpenSSH authorized_keys format
+// or a PEM encoded public key
+func readPublicKey(path string) (*rsa.PublicKey, error) {
+	fullPath, err := homedir.Expand(path)
+	if err != nil {
+		return nil, err
+	}
+	b, err := ioutil.ReadFile(fullPath)
+	if err != nil {
+		return nil, err
+	}
+	if sshKey, _, _, _, err := ssh.ParseAuthorizedKey(b); err == nil {
+		if sshKey.Type() != ssh.KeyAlgoRSA {
+			return nil, errors.New("Invalid RSA public key format")
+		}
+		return parseSSHRSAPublicKey(sshKey.Marshal())
+	}
+	block, _ := pem.Decode(b)
+	if block == nil {
+		return nil, errors.New("Invalid RSA public key format")
+	}
+	if block.Type == "RSA PUBLIC KEY" {
+		publicKey, err := x509.ParsePKCS1PublicKey(block.Bytes)
+		if err != nil {
+			return nil, errors.New("Invalid RSA public key format")
+		}
+		return publicKey, nil
+	}
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	publicKey, ok := key.(*rsa.PublicKey)
+	if err != nil || !ok {
+		return nil, errors.New("Invalid RSA public key format")
+	}
+	return publicKey, nil
+}
+
+// parseSSHRSAPublicKey parses an RSA public key in the SSH wire format which
+// is the key type, exponent, and modulus, each prefixed with its length
+func parseSSHRSAPublicKey(b []byte) (*rsa.PublicKey, error) {
+	var fields [][]byte
+	for len(b) > 0 {
+		if len(b) < 4 {
+			return nil, errors.New("Invalid RSA public key format")
+		}
+		n := binary.BigEndian.Uint32(b)
+		if uint64(len(b)-4) < uint64(n) {
+			return nil, errors.New("Invalid RSA public key format")
+		}
+		fields = append(fields, b[4:4+n])
+		b = b[4+n:]
+	}
+	if len(fields) != 3 {
+		return nil, errors.New("Invalid RSA public key format")
+	}
+	e := new(big.Int).SetBytes(fields[1])
+	if !e.IsInt64() || e.Int64() > math.MaxInt32 {
+		return nil, errors.New("Invalid RSA public key exponent")
+	}
+	return &rsa.PublicKey{N: new(big.Int).SetBytes(fields[2]), E: int(e.Int64())}, nil
+}
+
+// readPrivateKey reads a PEM encoded RSA private key, prompting for its
+// passphrase if it is encrypted
+func readPrivateKey(path string, ip prompts.IPrompts) (*rsa.PrivateKey, error) {
+	fullPath, err := homedir.Expand(path)
+	if err != nil {
+		return nil, err
+	}
+	b, err := ioutil.ReadFile(fullPath)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(b)
+	if block == nil {
+		return nil, errors.New("Private key is not PEM-encoded")
+	}
+	der := block.Bytes
+	if x509.IsEncryptedPEMBlock(block) {
+		passphrase, err := ip.KeyPassphrase(fullPath)
+		if err != nil {
+			return nil, err
+		}
+		der, err = x509.DecryptPEMBlock(block, []byte(passphrase))
+		if err != nil {
+			return nil, err
+		}
+	}
+	if block.Type == "RSA PRIVATE KEY" {
+		privateKey, err := x509.ParsePKCS1PrivateKey(der)
+		if err != nil {
+			return nil, errors.New("Invalid RSA private key format")
+		}
+		return privateKey, nil
+	}
+	key, err := x509.ParsePKCS8PrivateKey(der)
+	privateKey, ok := key.(*rsa.PrivateKey)
+	if err != nil || !ok {
+		return nil, errors.New("Invalid RSA private key format")
+	}
+	return privateKey, nil
+}
@@ -0,0 +1,112 @@
+package db
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"io/ioutil"
+	"os"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/catalyzeio/cli/config"
+	"github.com/catalyzeio/cli/test"
+	"golang.org/x/crypto/ssh"
+)
+
+const decryptCommandName = "decrypt"
+
+func TestDecrypt(t *testing.T) {
+	if err := test.SetUpGitRepo(); err != nil {
+		t.Fatal(err)
+	}
+	if err := test.SetUpAssociation(); err != nil {
+		t.Fatal(err)
+	}
+	backupID := createBackup(t)
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pemBytes := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(privateKey)})
+	if err = ioutil.WriteFile("backups", pemBytes, 0600); err != nil {
+		t.Fatal(err)
+	}
+	publicKey, err := ssh.NewPublicKey(&privateKey.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = ioutil.WriteFile("backups.pub", ssh.MarshalAuthorizedKey(publicKey), 0644); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Unsetenv(config.BackupPassphraseEnvVar)
+
+	var decryptTests = []struct {
+		downloadArgs     []string
+		decryptArgs      []string
+		downloadPassword string
+		decryptPassword  string
+		expectErr        bool
+		expectedOutput   string
+	}{
+		{nil, nil, "correct horse", "correct horse", false, "Backup decrypted successfully"},
+		{nil, nil, "correct horse", "battery staple", true, "Make sure the passphrase is correct"},
+		{[]string{"--public-key", "backups.pub"}, []string{"--private-key", "backups"}, "", "", false, "Backup decrypted successfully"},
+		{[]string{"--public-key", "backups.pub"}, nil, "", "", true, "Specify the matching private key with --private-key"},
+	}
+	for _, data := range decryptTests {
+		t.Logf("Data: %+v", data)
+		os.Remove("db.sql")
+		os.Setenv(config.BackupPassphraseEnvVar, data.downloadPassword)
+		args := append([]string{"-y", "-E", test.Alias, "db", "download", test.DBLabel, backupID, "db.sql.enc", "-f", "--encrypted"}, data.downloadArgs...)
+		output, err := test.RunCommand(test.BinaryName, args)
+		if err != nil {
+			t.Errorf("Unexpected error downloading: %s", output)
+			continue
+		}
+		encrypted, _ := ioutil.ReadFile("db.sql.enc")
+		if len(encrypted) == 0 || bytes.Contains(encrypted, test.Server.BackupData[test.DBSvcID]) {
+			t.Errorf("Expected the downloaded backup to be encrypted")
+		}
+		if info, err := os.Stat("db.sql.enc.key.json"); err != nil || info.Mode().Perm() != 0600 {
+			t.Errorf("Expected a key file only readable by its owner. Found: %v, %v", info, err)
+		}
+
+		os.Setenv(config.BackupPassphraseEnvVar, data.decryptPassword)
+		args = append([]string{"--non-interactive", "db", decryptCommandName, "db.sql.enc", "db.sql"}, data.decryptArgs...)
+		output, err = test.RunCommand(test.BinaryName, args)
+		if err != nil != data.expectErr {
+			t.Errorf("Unexpected error: %s", output)
+			continue
+		}
+		if !strings.Contains(output, data.expectedOutput) {
+			t.Errorf("Expected: %s. Found: %s", data.expectedOutput, output)
+			continue
+		}
+		plain, err := ioutil.ReadFile("db.sql")
+		if data.expectErr {
+			if err == nil {
+				t.Error("Expected no decrypted file to be left behind")
+			}
+		} else if !bytes.Equal(plain, test.Server.BackupData[test.DBSvcID]) {
+			t.Errorf("Expected the decrypted backup. Found: %q", plain)
+		}
+	}
+}
+
+// createBackup runs the db backup command and returns the ID of the new
+// backup.
+func createBackup(t *testing.T) string {
+	output, err := test.RunCommand(test.BinaryName, []string{"-E", test.Alias, "db", "backup", test.DBLabel})
+	if err != nil {
+		t.Fatalf("Unexpected error creating a backup: %s", output)
+	}
+	match := regexp.MustCompile(`Backup started \(job ID = ([^\)]+)\)`).FindStringSubmatch(output)
+	if match == nil {
+		t.Fatalf("Could not find the backup ID in: %s", output)
+	}
+	return match[1]
+}
@@ -1,27 +1,38 @@
 package db
 
 import (
+	"crypto/rsa"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"os"
 
 	"github.com/Sirupsen/logrus"
 	"github.com/catalyzeio/cli/commands/services"
+	"github.com/catalyzeio/cli/lib/atomicfile"
+	"github.com/catalyzeio/cli/lib/crypto"
 	"github.com/catalyzeio/cli/lib/prompts"
 	"github.com/catalyzeio/cli/models"
 )
 
-func CmdDownload(databaseName, backupID, filePath string, force bool, id IDb, ip prompts.IPrompts, is services.IServices) error {
+func CmdDownload(databaseName, backupID, filePath string, force, encrypted bool, publicKeyPath string, parallel, partSize int, id IDb, ip prompts.IPrompts, is services.IServices, ic crypto.ICrypto) error {
 	err := ip.PHI()
 	if err != nil {
 		return err
 	}
-	if !force {
-		if _, err := os.Stat(filePath); err == nil {
-			return fmt.Errorf("File already exists at path '%s'. Specify `--force` to overwrite", filePath)
+	paths := []string{filePath}
+	if encrypted {
+		paths = append(paths, keyFilePath(filePath))
+	}
+	for _, path := range paths {
+		if !force {
+			if _, err := os.Stat(path); err == nil {
+				return fmt.Errorf("File already exists at path '%s'. Specify `--force` to overwrite", path)
+			}
 		}
-	} else {
-		os.Remove(filePath)
+	}
+	if err = validateDownloadOptions(parallel, partSize); err != nil {
+		return err
 	}
 	service, err := is.RetrieveByLabel(databaseName)
 	if err != nil {
@@ -30,26 +41,84 @@ func CmdDownload(databaseName, backupID, filePath string, force bool, id IDb, ip
 	if service == nil {
 		return fmt.Errorf("Could not find a service with the label \"%s\". You can list services with the \"catalyze services\" command.", databaseName)
 	}
-	err = id.Download(backupID, filePath, service)
+	if !encrypted {
+		err = id.Download(backupID, filePath, parallel, partSize, service)
+		if err != nil {
+			return err
+		}
+		logrus.Printf("%s backup downloaded successfully to %s", databaseName, filePath)
+		logrus.Printf("You can also view logs for this backup with the \"catalyze db logs %s %s\" command", databaseName, backupID)
+		return nil
+	}
+	// gather what is needed to wrap the key before downloading anything
+	var publicKey *rsa.PublicKey
+	var passphrase string
+	if publicKeyPath != "" {
+		publicKey, err = readPublicKey(publicKeyPath)
+	} else {
+		passphrase, err = ip.BackupPassphrase(true)
+	}
+	if err != nil {
+		return err
+	}
+	job, err := id.DownloadEncrypted(backupID, filePath, parallel, partSize, service)
+	if err != nil {
+		return err
+	}
+	wrapped, err := ic.WrapKey(job.Backup, publicKey, passphrase)
 	if err != nil {
+		os.Remove(filePath)
+		return err
+	}
+	b, err := json.MarshalIndent(models.BackupKey{
+		JobID:        job.ID,
+		ServiceID:    service.ID,
+		ServiceLabel: service.Label,
+		CreatedAt:    job.CreatedAt,
+		Key:          *wrapped,
+	}, "", "  ")
+	if err != nil {
+		os.Remove(filePath)
 		return err
 	}
-	logrus.Printf("%s backup downloaded successfully to %s", databaseName, filePath)
-	logrus.Printf("You can also view logs for this backup with the \"catalyze db logs %s %s\" command", databaseName, backupID)
+	if err = atomicfile.WriteFile(keyFilePath(filePath), b, 0600); err != nil {
+		os.Remove(filePath)
+		return err
+	}
+	logrus.Printf("%s encrypted backup downloaded successfully to %s and its wrapped key to %s", databaseName, filePath, keyFilePath(filePath))
+	logrus.Printf("You can decrypt it at any time with the \"catalyze db decrypt %s <output_path>\" command", filePath)
+	return nil
+}
+
+// keyFilePath returns the path of the sidecar file holding the wrapped key
+// for the encrypted backup at the given path
+func keyFilePath(filePath string) string {
+	return filePath + ".key.json"
+}
+
+// validateDownloadOptions checks the number of parallel parts and the size of
+// each part a backup is downloaded in
+func validateDownloadOptions(parallel, partSize int) error {
+	if parallel < 1 {
+		return errors.New("--parallel must be at least 1")
+	}
+	if partSize < 1 {
+		return errors.New("--part-size must be at least 1 MB")
+	}
 	return nil
 }
 
 // Download an existing backup to the local machine. The backup is encrypted
 // throughout the entire journey and then decrypted once it is stored locally.
-func (d *SDb) Download(backupID, filePath string, service *models.Service) error {
+func (d *SDb) Download(backupID, filePath string, parallel, partSize int, service *models.Service) error {
 	job, err := d.Jobs.Retrieve(backupID, service.ID, false)
 	if err != nil {
-		return err
+		return backupNotFound(err, backupID, service.Label)
 	}
 	if job.Type != "backup" || (job.Status != "finished" && job.Status != "disappeared") {
 		return errors.New("Only 'finished' 'backup' jobs may be downloaded")
 	}
-	return d.Export(filePath, job, service)
+	return d.Export(filePath, parallel, partSize, job, service)
 }
 
 func (d *SDb) TempDownloadURL(jobID string, service *models.Service) (*models.TempURL, error) {
@@ -65,3 +134,21 @@ func (d *SDb) TempDownloadURL(jobID string, service *models.Service) (*models.Te
 	}
 	return &tempURL, nil
 }
+
+// DownloadEncrypted downloads an existing backup to the local machine without
+// decrypting it. The backup is still verified against its key before it is
+// saved. The backup job is returned so that its key can be saved.
+func (d *SDb) DownloadEncrypted(backupID, filePath string, parallel, partSize int, service *models.Service) (*models.Job, error) {
+	job, err := d.Jobs.Retrieve(backupID, service.ID, false)
+	if err != nil {
+		return nil, backupNotFound(err, backupID, service.Label)
+	}
+	if job.Type != "backup" || (job.Status != "finished" && job.Status != "disappeared") || job.Backup == nil {
+		return nil, errors.New("Only 'finished' 'backup' jobs may be downloaded")
+	}
+	partialPath, err := d.fetchBackup(filePath, parallel, int64(partSize)*1024*1024, job, service)
+	if err != nil {
+		return nil, err
+	}
+	return job, d.finishDownload(partialPath, filePath, false, job)
+}
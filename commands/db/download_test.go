@@ -0,0 +1,98 @@
+package db
+
+import (
+	"bytes"
+	"crypto/rand"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"reflect"
+	"regexp"
+	"testing"
+
+	"github.com/catalyzeio/cli/test"
+)
+
+const downloadCommandName = "download"
+
+func TestDownloadRanges(t *testing.T) {
+	if err := test.SetUpGitRepo(); err != nil {
+		t.Fatal(err)
+	}
+	if err := test.SetUpAssociation(); err != nil {
+		t.Fatal(err)
+	}
+	// large enough to be downloaded in 4 parts of 1 MB
+	originalData := test.Server.BackupData[test.DBSvcID]
+	defer func() { test.Server.BackupData[test.DBSvcID] = originalData }()
+	data := make([]byte, 3*1024*1024+100)
+	if _, err := rand.Read(data); err != nil {
+		t.Fatal(err)
+	}
+	test.Server.BackupData[test.DBSvcID] = data
+	backupID := createBackup(t)
+	size := len(test.Server.Blobs["backup-"+backupID])
+	const mb = 1024 * 1024
+	allRanges := []string{"bytes=0-0", fmt.Sprintf("bytes=0-%d", mb-1), fmt.Sprintf("bytes=%d-%d", mb, 2*mb-1), fmt.Sprintf("bytes=%d-%d", 2*mb, 3*mb-1), fmt.Sprintf("bytes=%d-%d", 3*mb, size-1)}
+	resumedRanges := []string{"bytes=0-0", fmt.Sprintf("bytes=%d-%d", 2*mb, 3*mb-1), fmt.Sprintf("bytes=%d-%d", 3*mb, size-1)}
+
+	var downloadTests = []struct {
+		failures       map[int64][]int
+		ignoreRanges   bool
+		corrupt        bool
+		expectErr      bool
+		expectedOutput string
+		expectedRanges []string
+	}{
+		{nil, false, false, false, "backup downloaded successfully", allRanges},
+		{map[int64][]int{mb: {503}}, false, false, false, "backup downloaded successfully", allRanges},
+		{map[int64][]int{2 * mb: {404}}, false, false, true, "Failed to download part 3.*Run the same command again to resume the download", allRanges[:3]},
+		{nil, false, false, false, `(?s)Resuming the download of backup \S+ \(2 of 4 parts already downloaded\).*backup downloaded successfully`, resumedRanges},
+		{map[int64][]int{2 * mb: {404}}, false, false, true, "Run the same command again to resume the download", allRanges[:3]},
+		{nil, false, true, true, "The downloaded backup failed verification and has been removed", resumedRanges},
+		{nil, true, false, false, "backup downloaded successfully", []string{}},
+	}
+	for _, d := range downloadTests {
+		t.Logf("Data: %+v", d)
+		test.Server.DownloadFailures = map[int64][]int{}
+		for offset, failures := range d.failures {
+			test.Server.DownloadFailures[offset] = failures
+		}
+		test.Server.DownloadedRanges = []string{}
+		test.Server.IgnoreRanges = d.ignoreRanges
+		if d.corrupt {
+			partial, err := os.OpenFile("db.sql"+partialSuffix, os.O_WRONLY, 0600)
+			if err != nil {
+				t.Fatal(err)
+			}
+			partial.WriteAt([]byte("corrupt"), 10)
+			partial.Close()
+		}
+		output, err := test.RunCommand(test.BinaryName, []string{"-y", "-E", test.Alias, "db", downloadCommandName, test.DBLabel, backupID, "db.sql", "-f", "--parallel", "1", "--part-size", "1"})
+		test.Server.IgnoreRanges = false
+		if err != nil != d.expectErr {
+			t.Errorf("Unexpected error: %s", output)
+			continue
+		}
+		if !regexp.MustCompile(d.expectedOutput).MatchString(output) {
+			t.Errorf("Expected: %s. Found: %s", d.expectedOutput, output)
+			continue
+		}
+		if !reflect.DeepEqual(test.Server.DownloadedRanges, d.expectedRanges) {
+			t.Errorf("Expected ranges %v. Found: %v", d.expectedRanges, test.Server.DownloadedRanges)
+		}
+		downloaded, readErr := ioutil.ReadFile("db.sql")
+		if d.expectErr {
+			if readErr == nil {
+				t.Errorf("Expected nothing to be saved at the output path")
+			}
+		} else if !bytes.Equal(downloaded, data) {
+			t.Errorf("Expected the downloaded backup to match the backed up data")
+		}
+		_, partialErr := os.Stat("db.sql" + partialSuffix)
+		if expectPartial := d.expectErr && !d.corrupt; expectPartial != (partialErr == nil) {
+			t.Errorf("Expected a partial download to remain: %t. Found: %v", expectPartial, partialErr)
+		}
+		os.Remove("db.sql")
+	}
+}
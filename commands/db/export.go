@@ -3,31 +3,36 @@ package db
 import (
 	"fmt"
 	"io"
-	"net/http"
+	"io/ioutil"
 	"os"
-	"strconv"
+	"path/filepath"
 	"strings"
 	"time"
 
 	"github.com/Sirupsen/logrus"
 	"github.com/catalyzeio/cli/commands/services"
+	"github.com/catalyzeio/cli/lib/interrupt"
 	"github.com/catalyzeio/cli/lib/jobs"
 	"github.com/catalyzeio/cli/lib/prompts"
 	"github.com/catalyzeio/cli/lib/transfer"
 	"github.com/catalyzeio/cli/models"
 )
 
-func CmdExport(databaseName, filePath string, force bool, id IDb, ip prompts.IPrompts, is services.IServices, ij jobs.IJobs) error {
+// stdoutPath is the file path that exports to stdout
+const stdoutPath = "-"
+
+func CmdExport(databaseName, filePath string, force bool, parallel, partSize int, id IDb, ip prompts.IPrompts, is services.IServices, ij jobs.IJobs) error {
 	err := ip.PHI()
 	if err != nil {
 		return err
 	}
-	if !force {
+	if !force && filePath != stdoutPath {
 		if _, err := os.Stat(filePath); err == nil {
 			return fmt.Errorf("File already exists at path '%s'. Specify `--force` to overwrite", filePath)
 		}
-	} else {
-		os.Remove(filePath)
+	}
+	if err = validateDownloadOptions(parallel, partSize); err != nil {
+		return err
 	}
 	service, err := is.RetrieveByLabel(databaseName)
 	if err != nil {
@@ -57,61 +62,76 @@ func CmdExport(databaseName, filePath string, force bool, id IDb, ip prompts.IPr
 	job.Status = status
 	logrus.Printf("Ended in status '%s'", job.Status)
 	if job.Status != "finished" {
-		id.DumpLogs("backup", job, service)
+		id.DumpLogs(job, service)
 		return fmt.Errorf("Job finished with invalid status %s", job.Status)
 	}
 
-	err = id.Export(filePath, job, service)
+	err = id.Export(filePath, parallel, partSize, job, service)
 	if err != nil {
 		return err
 	}
-	err = id.DumpLogs("backup", job, service)
+	err = id.DumpLogs(job, service)
 	if err != nil {
 		return err
 	}
-	logrus.Printf("%s exported successfully to %s", service.Name, filePath)
+	if filePath == stdoutPath {
+		logrus.Printf("%s exported successfully to stdout", service.Name)
+	} else {
+		logrus.Printf("%s exported successfully to %s", service.Name, filePath)
+	}
 	return nil
 }
 
 // Export dumps all data from a database service and downloads the encrypted
 // data to the local machine. The export is accomplished by first creating a
 // backup. Once finished, the CLI asks where the file can be downloaded from.
-// The file is downloaded, decrypted, and saved locally.
-func (d *SDb) Export(filePath string, job *models.Job, service *models.Service) error {
-	tempURL, err := d.TempDownloadURL(job.ID, service)
+// The file is downloaded in parts of partSize MB, parallel parts at a time,
+// and is decrypted and saved locally once every part has been downloaded. If
+// the file path is "-" the decrypted data is written to stdout instead.
+func (d *SDb) Export(filePath string, parallel, partSize int, job *models.Job, service *models.Service) error {
+	if filePath == stdoutPath {
+		return d.exportToStdout(parallel, partSize, job, service)
+	}
+	partialPath, err := d.fetchBackup(filePath, parallel, int64(partSize)*1024*1024, job, service)
 	if err != nil {
 		return err
 	}
-	resp, err := http.Get(tempURL.URL)
+	return d.finishDownload(partialPath, filePath, true, job)
+}
+
+// exportToStdout downloads the encrypted backup to a temporary directory and
+// writes its decrypted contents to stdout so that plaintext never touches the
+// disk. Every chunk is verified before it is written, but if verification
+// fails part way through the output is incomplete and an error is returned.
+func (d *SDb) exportToStdout(parallel, partSize int, job *models.Job, service *models.Service) error {
+	dir, err := ioutil.TempDir("", "catalyze-export-")
 	if err != nil {
 		return err
 	}
-	defer resp.Body.Close()
-	size, err := strconv.Atoi(resp.Header.Get("Content-Length"))
+	defer os.RemoveAll(dir)
+	defer interrupt.RemoveOnInterrupt(dir)()
+	partialPath, err := d.fetchBackup(filepath.Join(dir, "backup"), parallel, int64(partSize)*1024*1024, job, service)
 	if err != nil {
 		return err
 	}
-	file, err := os.OpenFile(filePath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+	encrypted, err := os.Open(partialPath)
 	if err != nil {
 		return err
 	}
-	dfw, err := d.Crypto.NewDecryptWriteCloser(file, job.Backup.Key, job.Backup.IV)
+	defer encrypted.Close()
+	logrus.Println("Decrypting to stdout...")
+	dfw, err := d.Crypto.NewDecryptWriteCloser(nopWriteCloser{os.Stdout}, job.Backup.Key, job.Backup.IV)
 	if err != nil {
 		return err
 	}
-
-	wct := transfer.NewWriteCloserTransfer(dfw, size)
-	done := make(chan bool)
-	go printTransferStatus(true, wct, done)
-
-	_, err = io.Copy(wct, resp.Body)
+	_, err = io.Copy(dfw, encrypted)
+	if closeErr := dfw.Close(); err == nil {
+		err = closeErr
+	}
 	if err != nil {
-		done <- false
-		dfw.Close()
-		return err
+		return fmt.Errorf("Failed to decrypt the backup, the output is incomplete: %s", err)
 	}
-	done <- true
-	return dfw.Close()
+	return nil
 }
 
 func printTransferStatus(isDownload bool, tr transfer.Transfer, done <-chan bool) {
@@ -119,7 +139,7 @@ func printTransferStatus(isDownload bool, tr transfer.Transfer, done <-chan bool
 	final := "Download"
 	status := "Finished"
 	if isDownload {
-		logrus.Println("Decrypting and Downloading...")
+		logrus.Println("Downloading...")
 	} else {
 		logrus.Println("Encrypting and Uploading...")
 		action = "uploaded"
@@ -129,19 +149,18 @@ func printTransferStatus(isDownload bool, tr transfer.Transfer, done <-chan bool
 	success := true
 	isDone := false
 loop:
-	for i, l := tr.Transferred(), tr.Length(); i < l; i = tr.Transferred() {
+	for i, l := tr.Transferred(), tr.Length(); i < l || l < 0; i = tr.Transferred() {
 		select {
 		case success = <-done:
 			isDone = true
 			break loop
 		case <-time.After(time.Millisecond * 100):
-			percent := uint64(i / l * 100)
-			s := fmt.Sprintf("\r\033[m\t%s of %s (%d%%) %s", i, l, percent, action)
-			fmt.Print(s)
+			s := transferStatus(i, l, action)
+			fmt.Fprint(logrus.StandardLogger().Out, s)
 			sLen := len(s)
 			// this clears any dangling characters at the end with empty space
 			if sLen < lastLen {
-				fmt.Print(strings.Repeat(" ", lastLen-sLen))
+				fmt.Fprint(logrus.StandardLogger().Out, strings.Repeat(" ", lastLen-sLen))
 			} else {
 				lastLen = sLen
 			}
@@ -153,12 +172,12 @@ loop:
 
 	total := tr.Transferred()
 	l := tr.Length()
-	s := fmt.Sprintf("\r\033[m\t%s of %s (%d%%) %s", total, l, uint64(total/l*100), action)
-	fmt.Print(s)
+	s := transferStatus(total, l, action)
+	fmt.Fprint(logrus.StandardLogger().Out, s)
 	sLen := len(s)
 	// this clears any dangling characters at the end with empty space
 	if sLen < lastLen {
-		fmt.Print(strings.Repeat(" ", lastLen-sLen))
+		fmt.Fprint(logrus.StandardLogger().Out, strings.Repeat(" ", lastLen-sLen))
 	}
 
 	if !success {
@@ -166,3 +185,16 @@ loop:
 	}
 	logrus.Printf("\n%s %s!\n", final, status)
 }
+
+// transferStatus formats the progress of a transfer. A negative length means
+// the length of the transfer is not known.
+func transferStatus(transferred, length transfer.ByteSize, action string) string {
+	if length < 0 {
+		return fmt.Sprintf("\r\033[m\t%s %s", transferred, action)
+	}
+	percent := uint64(100)
+	if length > 0 {
+		percent = uint64(transferred / length * 100)
+	}
+	return fmt.Sprintf("\r\033[m\t%s of %s (%d%%) %s", transferred, length, percent, action)
+}
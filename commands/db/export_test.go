@@ -0,0 +1,49 @@
+package db
+
+import (
+	"os"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/catalyzeio/cli/test"
+)
+
+const exportCommandName = "export"
+
+func TestExportStdout(t *testing.T) {
+	if err := test.SetUpGitRepo(); err != nil {
+		t.Fatal(err)
+	}
+	if err := test.SetUpAssociation(); err != nil {
+		t.Fatal(err)
+	}
+
+	var exportTests = []struct {
+		answer         string
+		expectErr      bool
+		expectedStdout string
+		expectedStderr string
+	}{
+		{"y\n", false, string(test.Server.BackupData[test.DBSvcID]), `(?s)Do you wish to proceed\? \(y/n\).*backup of db01 finished.*exported successfully to stdout`},
+		{"n\n", true, "", `(?s)Do you wish to proceed\? \(y/n\).*Exiting`},
+	}
+	for _, data := range exportTests {
+		t.Logf("Data: %+v", data)
+		stdout, stderr, err := test.RunCommandSeparateOutput(test.BinaryName, []string{"-E", test.Alias, "db", exportCommandName, test.DBLabel, "-"}, strings.NewReader(data.answer))
+		if err != nil != data.expectErr {
+			t.Errorf("Unexpected error: %s", stderr)
+			continue
+		}
+		if stdout != data.expectedStdout {
+			t.Errorf("Expected stdout: %q. Found: %q", data.expectedStdout, stdout)
+		}
+		if !regexp.MustCompile(data.expectedStderr).MatchString(stderr) {
+			t.Errorf("Expected stderr: %s. Found: %s", data.expectedStderr, stderr)
+		}
+		if _, err := os.Stat("-"); err == nil {
+			t.Errorf("Expected no file to be created for -")
+			os.Remove("-")
+		}
+	}
+}
@@ -0,0 +1,413 @@
+package db
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/catalyzeio/cli/lib/atomicfile"
+	"github.com/catalyzeio/cli/lib/interrupt"
+	"github.com/catalyzeio/cli/lib/transfer"
+	"github.com/catalyzeio/cli/models"
+)
+
+const (
+	// partialSuffix is appended to the output path to get the path the
+	// encrypted backup is downloaded to
+	partialSuffix = ".partial"
+	// manifestSuffix is appended to the output path to get the path of the
+	// manifest recording the progress of the download
+	manifestSuffix = ".partial.json"
+)
+
+// downloadState is the progress of a ranged download of an encrypted backup.
+// It is saved next to the partially downloaded file after every part so that
+// an interrupted download is resumed by running the same command again. It
+// holds no key material, the key is retrieved with the backup job.
+type downloadState struct {
+	JobID    string `json:"jobId"`
+	Size     int64  `json:"size"`
+	PartSize int64  `json:"partSize"`
+	Parts    []int  `json:"parts"`
+
+	path string
+}
+
+// loadDownloadState returns the saved state of a previous attempt to download
+// the given backup to filePath. If there is no saved state, or it is for a
+// different backup, a new state is returned.
+func loadDownloadState(filePath, jobID string, size, partSize int64) *downloadState {
+	path := filePath + manifestSuffix
+	state := &downloadState{}
+	if b, err := ioutil.ReadFile(path); err == nil && json.Unmarshal(b, state) == nil &&
+		state.JobID == jobID && state.Size == size && state.PartSize > 0 {
+		if _, err := os.Stat(filePath + partialSuffix); err == nil {
+			state.path = path
+			return state
+		}
+	}
+	return &downloadState{JobID: jobID, Size: size, PartSize: partSize, Parts: []int{}, path: path}
+}
+
+// save writes the download state to disk
+func (s *downloadState) save() error {
+	b, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+	return atomicfile.WriteFile(s.path, b, 0600)
+}
+
+// numParts returns the number of parts the backup is downloaded in
+func (s *downloadState) numParts() int {
+	return int((s.Size + s.PartSize - 1) / s.PartSize)
+}
+
+// partRange returns the offset and length of the given part
+func (s *downloadState) partRange(number int) (int64, int64) {
+	offset := int64(number-1) * s.PartSize
+	length := s.PartSize
+	if offset+length > s.Size {
+		length = s.Size - offset
+	}
+	return offset, length
+}
+
+// removeDownload deletes the partially downloaded backup for filePath and its
+// manifest
+func removeDownload(filePath string) error {
+	for _, path := range []string{filePath + partialSuffix, filePath + manifestSuffix} {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+// fetchBackup downloads the encrypted backup of the given job to the partial
+// path for filePath in parts of partSize bytes, up to parallel parts at a
+// time, and returns the partial path. Parts downloaded by a previous attempt
+// are skipped. If the server does not support ranged requests the backup is
+// downloaded in a single request instead.
+func (d *SDb) fetchBackup(filePath string, parallel int, partSize int64, job *models.Job, service *models.Service) (string, error) {
+	tempURL, err := d.TempDownloadURL(job.ID, service)
+	if err != nil {
+		return "", err
+	}
+	size, ranged, err := d.DownloadSize(tempURL.URL)
+	if err != nil {
+		return "", err
+	}
+	if !ranged {
+		logrus.Debugln("Ranged requests are not supported, downloading the backup in a single request")
+		removeDownload(filePath)
+		parallel = 1
+		partSize = size
+		if partSize == 0 {
+			partSize = 1
+		}
+	}
+	partialPath := filePath + partialSuffix
+	state := loadDownloadState(filePath, job.ID, size, partSize)
+	file, err := os.OpenFile(partialPath, os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return "", err
+	}
+	completed := map[int]bool{}
+	for _, number := range state.Parts {
+		completed[number] = true
+	}
+	if len(completed) == 0 {
+		err = file.Truncate(0)
+	} else {
+		logrus.Printf("Resuming the download of backup %s (%d of %d parts already downloaded)", job.ID, len(completed), state.numParts())
+	}
+	if err == nil {
+		err = state.save()
+	}
+	if err != nil {
+		file.Close()
+		return "", err
+	}
+
+	var downloaded int64
+	parts := make(chan int, state.numParts())
+	for number := 1; number <= state.numParts(); number++ {
+		if completed[number] {
+			_, length := state.partRange(number)
+			downloaded += length
+		} else {
+			parts <- number
+		}
+	}
+	close(parts)
+	pt := transfer.NewProgressTransfer(size, downloaded)
+	done := make(chan bool)
+	go printTransferStatus(true, pt, done)
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	var firstErr error
+	for i := 0; i < parallel; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for number := range parts {
+				mu.Lock()
+				failed := firstErr != nil
+				mu.Unlock()
+				if failed {
+					return
+				}
+				err := d.downloadPart(number, file, state, tempURL.URL, pt, job, service)
+				mu.Lock()
+				if err == nil {
+					// the part must be on disk before the manifest says it is
+					if err = file.Sync(); err == nil {
+						state.Parts = append(state.Parts, number)
+						err = state.save()
+					}
+				}
+				if err != nil && firstErr == nil {
+					firstErr = err
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	done <- firstErr == nil
+	err = file.Close()
+	if firstErr != nil {
+		return "", fmt.Errorf("%s. Run the same command again to resume the download", firstErr)
+	}
+	return partialPath, err
+}
+
+// downloadPart downloads a single part of a backup into its place in the
+// file. A part that fails with an error that may be temporary is retried with
+// a fresh URL in case the previous one expired.
+func (d *SDb) downloadPart(number int, file *os.File, state *downloadState, url string, pt *transfer.ProgressTransfer, job *models.Job, service *models.Service) error {
+	offset, length := state.partRange(number)
+	var err error
+	for attempt := 1; attempt <= maxPartAttempts; attempt++ {
+		if attempt > 1 {
+			delay := time.Duration(1<<uint(attempt-2)) * time.Second
+			logrus.Debugf("Retrying part %d in %s: %s", number, delay, err)
+			if err = interrupt.Sleep(d.Settings.HTTPManager.Context(), delay); err != nil {
+				return err
+			}
+			var tempURL *models.TempURL
+			tempURL, err = d.TempDownloadURL(job.ID, service)
+			if err != nil {
+				return err
+			}
+			url = tempURL.URL
+		}
+		w := &offsetWriter{file: file, offset: offset, pt: pt}
+		var retry bool
+		retry, err = d.DownloadRange(url, offset, length, w)
+		if err == nil {
+			return nil
+		}
+		// start this part over in the progress
+		pt.Add(-w.written)
+		if !retry {
+			break
+		}
+	}
+	return fmt.Errorf("Failed to download part %d: %s", number, err)
+}
+
+// offsetWriter writes sequentially to a file starting at an offset and records
+// everything written in a ProgressTransfer
+type offsetWriter struct {
+	file    *os.File
+	offset  int64
+	written int64
+	pt      *transfer.ProgressTransfer
+}
+
+func (ow *offsetWriter) Write(p []byte) (int, error) {
+	n, err := ow.file.WriteAt(p, ow.offset+ow.written)
+	ow.written += int64(n)
+	ow.pt.Add(int64(n))
+	return n, err
+}
+
+// verifiedWriteCloser is the destination of a decrypted backup. It syncs the
+// file before closing it and records any error writing to it so that local
+// failures can be told apart from a backup that fails verification.
+type verifiedWriteCloser struct {
+	file *os.File
+	err  error
+}
+
+func (v *verifiedWriteCloser) Write(p []byte) (int, error) {
+	n, err := v.file.Write(p)
+	if err != nil {
+		v.err = err
+	}
+	return n, err
+}
+
+func (v *verifiedWriteCloser) Close() error {
+	err := v.file.Sync()
+	if closeErr := v.file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		v.err = err
+	}
+	return err
+}
+
+// nopWriteCloser adds a Close method that does nothing to a writer
+type nopWriteCloser struct {
+	io.Writer
+}
+
+func (nopWriteCloser) Close() error {
+	return nil
+}
+
+// finishDownload decrypts a completely downloaded backup, verifying every GCM
+// tag along the way, and moves the result to filePath once it is known to be
+// intact. If decrypt is false the decrypted data is discarded and the
+// encrypted backup itself is moved to filePath. The rename is atomic so a
+// partial or corrupt file never appears at filePath. A backup that fails
+// verification is removed so that the next attempt downloads it again.
+func (d *SDb) finishDownload(partialPath, filePath string, decrypt bool, job *models.Job) error {
+	logrus.Println("Decrypting and verifying...")
+	encrypted, err := os.Open(partialPath)
+	if err != nil {
+		return err
+	}
+	defer encrypted.Close()
+	var out *verifiedWriteCloser
+	var dest io.WriteCloser = nopWriteCloser{ioutil.Discard}
+	if decrypt {
+		temp, err := ioutil.TempFile(filepath.Dir(filePath), "."+filepath.Base(filePath)+".")
+		if err != nil {
+			return err
+		}
+		defer interrupt.RemoveOnInterrupt(temp.Name())()
+		out = &verifiedWriteCloser{file: temp}
+		dest = out
+	}
+	dfw, err := d.Crypto.NewDecryptWriteCloser(dest, job.Backup.Key, job.Backup.IV)
+	if err == nil {
+		_, err = io.Copy(dfw, encrypted)
+		if closeErr := dfw.Close(); err == nil {
+			err = closeErr
+		}
+	} else {
+		dest.Close()
+	}
+	if err != nil {
+		if out != nil {
+			os.Remove(out.file.Name())
+			if out.err != nil {
+				return out.err
+			}
+		}
+		encrypted.Close()
+		removeDownload(filePath)
+		return fmt.Errorf("The downloaded backup failed verification and has been removed: %s. Run the same command again to download it again", err)
+	}
+	if out != nil {
+		err = os.Rename(out.file.Name(), filePath)
+		if err != nil {
+			os.Remove(out.file.Name())
+			return err
+		}
+		return removeDownload(filePath)
+	}
+	encrypted.Close()
+	if err = os.Rename(partialPath, filePath); err != nil {
+		return err
+	}
+	return removeDownload(filePath)
+}
+
+// DownloadSize returns the size of the file at the given temporary URL and
+// whether the server supports ranged requests for it. A single byte is
+// requested rather than making a HEAD request since temporary URLs are only
+// signed for GET requests.
+func (d *SDb) DownloadSize(url string) (int64, bool, error) {
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return 0, false, err
+	}
+	req.Header.Set("Range", "bytes=0-0")
+	ctx := d.Settings.HTTPManager.Context()
+	resp, err := http.DefaultClient.Do(req.WithContext(ctx))
+	if ctxErr := interrupt.Err(ctx); ctxErr != nil {
+		err = ctxErr
+	}
+	if err != nil {
+		return 0, false, err
+	}
+	defer resp.Body.Close()
+	switch resp.StatusCode {
+	case http.StatusPartialContent:
+		contentRange := resp.Header.Get("Content-Range")
+		size, err := strconv.ParseInt(contentRange[strings.LastIndex(contentRange, "/")+1:], 10, 64)
+		if err != nil {
+			return 0, false, fmt.Errorf("Invalid Content-Range \"%s\" for the backup", contentRange)
+		}
+		return size, true, nil
+	case http.StatusOK:
+		if resp.ContentLength < 0 {
+			return 0, false, errors.New("The size of the backup could not be determined")
+		}
+		return resp.ContentLength, false, nil
+	}
+	b, _ := ioutil.ReadAll(resp.Body)
+	return 0, false, fmt.Errorf("Failed to download the backup (%d) %s", resp.StatusCode, string(b))
+}
+
+// DownloadRange downloads length bytes starting at offset from the given
+// temporary URL into w. If the download fails, the returned bool reports
+// whether the failure may be temporary and the range should be retried.
+func (d *SDb) DownloadRange(url string, offset, length int64, w io.Writer) (bool, error) {
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Range", fmt.Sprintf("bytes=%d-%d", offset, offset+length-1))
+	ctx := d.Settings.HTTPManager.Context()
+	resp, err := http.DefaultClient.Do(req.WithContext(ctx))
+	if ctxErr := interrupt.Err(ctx); ctxErr != nil {
+		return false, ctxErr
+	}
+	if err != nil {
+		return true, err
+	}
+	defer resp.Body.Close()
+	// a server without support for ranges sends the whole file, which is only
+	// what was asked for when downloading it in a single part
+	if resp.StatusCode != http.StatusPartialContent && (resp.StatusCode != http.StatusOK || offset != 0) {
+		b, _ := ioutil.ReadAll(resp.Body)
+		retry := resp.StatusCode >= 500 || resp.StatusCode == http.StatusRequestTimeout || resp.StatusCode == http.StatusTooManyRequests
+		return retry, fmt.Errorf("(%d) %s", resp.StatusCode, string(b))
+	}
+	if _, err = io.CopyN(w, resp.Body, length); err != nil {
+		if ctxErr := interrupt.Err(ctx); ctxErr != nil {
+			return false, ctxErr
+		}
+		// most likely the connection dropped part way through
+		return true, err
+	}
+	return false, nil
+}
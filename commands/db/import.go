@@ -1,26 +1,39 @@
 package db
 
 import (
+	"bufio"
+	"context"
 	"crypto/rand"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
-	"net/http"
-	"net/url"
+	"io"
+	"io/ioutil"
 	"os"
 	"strings"
 
 	"github.com/Sirupsen/logrus"
 	"github.com/catalyzeio/cli/commands/services"
 	"github.com/catalyzeio/cli/lib/crypto"
+	"github.com/catalyzeio/cli/lib/interrupt"
 	"github.com/catalyzeio/cli/lib/jobs"
 	"github.com/catalyzeio/cli/lib/prompts"
 	"github.com/catalyzeio/cli/lib/transfer"
 	"github.com/catalyzeio/cli/models"
+	"github.com/catalyzeio/gcm/gcm"
 )
 
-func CmdImport(databaseName, filePath, mongoCollection, mongoDatabase string, skipBackup bool, id IDb, ip prompts.IPrompts, is services.IServices, ij jobs.IJobs) error {
-	if _, err := os.Stat(filePath); os.IsNotExist(err) {
-		return fmt.Errorf("A file does not exist at path '%s'", filePath)
+// stdinPath is the file path that imports from stdin
+const stdinPath = "-"
+
+func CmdImport(databaseName, filePath, mongoCollection, mongoDatabase string, partSize int, skipBackup, validateOnly, skipValidation bool, settings *models.Settings, id IDb, ip prompts.IPrompts, is services.IServices, ij jobs.IJobs) error {
+	if filePath != stdinPath {
+		if _, err := os.Stat(filePath); os.IsNotExist(err) {
+			return fmt.Errorf("A file does not exist at path '%s'", filePath)
+		}
+	}
+	if partSize < 1 {
+		return fmt.Errorf("Invalid part size %d. The part size must be at least 1 MB", partSize)
 	}
 	service, err := is.RetrieveByLabel(databaseName)
 	if err != nil {
@@ -29,70 +42,131 @@ func CmdImport(databaseName, filePath, mongoCollection, mongoDatabase string, sk
 	if service == nil {
 		return fmt.Errorf("Could not find a service with the label \"%s\". You can list services with the \"catalyze services\" command.", databaseName)
 	}
-	key := make([]byte, crypto.KeySize)
-	iv := make([]byte, crypto.IVSize)
-	rand.Read(key)
-	rand.Read(iv)
-	file, err := os.Open(filePath)
-	if err != nil {
-		return err
+	input := bufio.NewReaderSize(os.Stdin, sniffSize)
+	var file *os.File
+	if filePath != stdinPath {
+		file, err = os.Open(filePath)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		input = bufio.NewReaderSize(file, sniffSize)
 	}
-	defer file.Close()
-	fi, err := file.Stat()
-	if err != nil {
-		return err
+	// mongo imports are gzipped archives which are imported as they are
+	compression := ""
+	if !strings.HasPrefix(service.Name, "mongo") {
+		compression = detectCompression(input)
 	}
-	encryptFileReader, err := id.NewEncryptReader(file, key, iv)
-	if err != nil {
-		return err
+	// compressed input is decompressed up front so that the data itself can
+	// be validated
+	source := input
+	var plain io.ReadCloser
+	if compression != "" {
+		logrus.Printf("Decompressing %s input", compression)
+		plain, err = decompress(input, compression)
+		if err != nil {
+			return err
+		}
+		defer plain.Close()
+		source = bufio.NewReaderSize(plain, sniffSize)
 	}
-	uploadSize := encryptFileReader.CalculateTotalSize(int(fi.Size()))
-	fiveGB := transfer.GB * 5
-	if transfer.ByteSize(uploadSize) > fiveGB {
-		return fmt.Errorf("the encrypted size of %s exceeds the maximum upload size of %s", filePath, fiveGB)
+	if !skipValidation {
+		if err = validateImport(service, source); err != nil {
+			return fmt.Errorf("%s. Specify --skip-validation to import it anyway", err)
+		}
 	}
-	rt := transfer.NewReaderTransfer(encryptFileReader, uploadSize)
-	if !skipBackup {
-		logrus.Printf("Backing up \"%s\" before performing the import", databaseName)
-		job, err := id.Backup(service)
+	if validateOnly {
+		logrus.Printf("'%s' is valid for importing into %s", filePath, databaseName)
+		return nil
+	}
+	// the size of plain files is known up front. On pods that accept
+	// multipart imports they can also be encrypted in place one part at a time
+	// which allows an interrupted upload to be resumed.
+	multipart := importSupportsMultipart(settings)
+	plainSize := int64(-1)
+	var state *uploadState
+	if file != nil && compression == "" {
+		fi, err := file.Stat()
 		if err != nil {
 			return err
 		}
-		logrus.Printf("Backup started (job ID = %s)", job.ID)
-
-		// all because logrus treats print, println, and printf the same
-		logrus.Println("Polling until backup finishes.")
-		if job.IsSnapshotBackup != nil && *job.IsSnapshotBackup {
-			logrus.Printf("This is a snapshot backup, it may be a while before this backup shows up in the \"catalyze db list %s\" command.", databaseName)
-			err = ij.WaitToAppear(job.ID, service.ID)
+		if fi.Mode().IsRegular() {
+			plainSize = fi.Size()
+		}
+		if fi.Mode().IsRegular() && multipart {
+			state, err = loadUploadState(filePath, fi, int64(partSize)*int64(transfer.MB), service, settings)
 			if err != nil {
 				return err
 			}
 		}
-		status, err := ij.PollTillFinished(job.ID, service.ID)
-		if err != nil {
+	}
+	if !skipBackup {
+		if err := safetyBackup(databaseName, "import", service, id, ij); err != nil {
 			return err
 		}
-		job.Status = status
-		logrus.Printf("Ended in status '%s'", job.Status)
-		err = id.DumpLogs("backup", job, service)
+	} else {
+		err := ip.YesNo("Are you sure you want to import data into your database without backing it up first? (y/n) ")
 		if err != nil {
 			return err
 		}
-		if job.Status != "finished" {
-			return fmt.Errorf("Job finished with invalid status %s", job.Status)
+	}
+	logrus.Printf("Importing '%s' into %s (ID = %s)", filePath, databaseName, service.ID)
+	var filename string
+	var key, iv []byte
+	if state != nil {
+		if state.Upload.ID == "" {
+			upload, err := id.StartUpload(state.encryptedSize(), service)
+			if err != nil {
+				return err
+			}
+			state.Upload = *upload
+			if err = state.save(); err != nil {
+				return err
+			}
 		}
+		if err = uploadFile(settings.HTTPManager.Context(), file, state, service, id); err != nil {
+			return err
+		}
+		filename = state.Upload.Filename
+		key, _ = hex.DecodeString(state.Key)
+		iv, _ = hex.DecodeString(state.IV)
 	} else {
-		err := ip.YesNo("Are you sure you want to import data into your database without backing it up first? (y/n) ")
+		key = make([]byte, crypto.KeySize)
+		iv = make([]byte, crypto.IVSize)
+		rand.Read(key)
+		rand.Read(iv)
+		er, err := id.NewEncryptReader(source, key, iv)
 		if err != nil {
 			return err
 		}
+		if multipart {
+			upload, err := uploadUnknownLength(settings.HTTPManager.Context(), er, int64(partSize)*int64(transfer.MB), importRequiresLength(settings), service, id)
+			if err != nil {
+				return err
+			}
+			filename = upload.Filename
+		} else {
+			filename, err = uploadSingle(er, plainSize, filePath, service, id)
+			if err != nil {
+				return err
+			}
+		}
+		if plain != nil {
+			// reports any error decompressing the input
+			if err = plain.Close(); err != nil {
+				return err
+			}
+		}
 	}
-	logrus.Printf("Importing '%s' into %s (ID = %s)", filePath, databaseName, service.ID)
-	job, err := id.Import(rt, key, iv, mongoCollection, mongoDatabase, service)
+	job, err := id.Import(filename, key, iv, mongoCollection, mongoDatabase, service)
 	if err != nil {
 		return err
 	}
+	if state != nil {
+		if err = state.remove(); err != nil {
+			logrus.Warnf("Failed to remove the upload state: %s", err)
+		}
+	}
 	// all because logrus treats print, println, and printf the same
 	logrus.StandardLogger().Out.Write([]byte(fmt.Sprintf("Processing import (job ID = %s).", job.ID)))
 
@@ -102,7 +176,7 @@ func CmdImport(databaseName, filePath, mongoCollection, mongoDatabase string, sk
 	}
 	job.Status = status
 	logrus.Printf("\nImport complete (end status = '%s')", job.Status)
-	err = id.DumpLogs("restore", job, service)
+	err = id.DumpLogs(job, service)
 	if err != nil {
 		return err
 	}
@@ -112,16 +186,110 @@ func CmdImport(databaseName, filePath, mongoCollection, mongoDatabase string, sk
 	return nil
 }
 
+// uploadSingle uploads encrypted data in a single request, which every pod
+// accepts. The encrypted size is calculated from plainSize when it is known.
+// Otherwise the encrypted data is written to a temporary file first to find
+// its size.
+func uploadSingle(er *gcm.EncryptReader, plainSize int64, filePath string, service *models.Service, id IDb) (string, error) {
+	r := io.Reader(er)
+	var length int64
+	if plainSize >= 0 {
+		length = int64(er.CalculateTotalSize(int(plainSize)))
+	} else {
+		spool, err := ioutil.TempFile("", "catalyze-import")
+		if err != nil {
+			return "", err
+		}
+		defer os.Remove(spool.Name())
+		defer interrupt.RemoveOnInterrupt(spool.Name())()
+		defer spool.Close()
+		logrus.Println("Encrypting to a temporary file...")
+		if length, err = io.Copy(spool, er); err != nil {
+			return "", err
+		}
+		if _, err = spool.Seek(0, io.SeekStart); err != nil {
+			return "", err
+		}
+		r = spool
+	}
+	fiveGB := transfer.GB * 5
+	if transfer.ByteSize(length) > fiveGB {
+		return "", fmt.Errorf("the encrypted size of %s exceeds the maximum upload size of %s", filePath, fiveGB)
+	}
+	return id.UploadSingle(r, length, service)
+}
+
+// uploadUnknownLength uploads encrypted data whose length is not known until
+// it has all been read. If the pod requires the length of an import up front,
+// the encrypted data is spooled to a temporary file first. Otherwise it is
+// streamed straight to the upload.
+func uploadUnknownLength(ctx context.Context, r io.Reader, partSize int64, requiresLength bool, service *models.Service, id IDb) (*models.Upload, error) {
+	if !requiresLength {
+		upload, err := id.StartUpload(-1, service)
+		if err != nil {
+			return nil, err
+		}
+		return upload, uploadStream(ctx, r, partSize, upload, service, id)
+	}
+	spool, err := ioutil.TempFile("", "catalyze-import")
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(spool.Name())
+	defer interrupt.RemoveOnInterrupt(spool.Name())()
+	defer spool.Close()
+	logrus.Println("Encrypting to a temporary file...")
+	size, err := io.Copy(spool, r)
+	if err != nil {
+		return nil, err
+	}
+	upload, err := id.StartUpload(size, service)
+	if err != nil {
+		return nil, err
+	}
+	return upload, uploadSpooled(ctx, spool, size, partSize, upload, service, id)
+}
+
+// importSupportsMultipart reports whether the pod of the associated
+// environment accepts imports uploaded in parts. Pods that do not report it
+// are sent the whole import in a single upload.
+func importSupportsMultipart(settings *models.Settings) bool {
+	if settings.Pods != nil {
+		for _, pod := range *settings.Pods {
+			if pod.Name == settings.Pod {
+				return pod.MultipartImports
+			}
+		}
+	}
+	return false
+}
+
+// importRequiresLength reports whether the pod of the associated environment
+// requires the length of an import before it is uploaded. If the pod is not
+// known, the length is assumed to be required.
+func importRequiresLength(settings *models.Settings) bool {
+	if settings.Pods != nil {
+		for _, pod := range *settings.Pods {
+			if pod.Name == settings.Pod {
+				return pod.ImportRequiresLength
+			}
+		}
+	}
+	return true
+}
+
 // Import imports data into a database service. The import is accomplished
-// by encrypting the file locally, requesting a location that it can be uploaded
-// to, then uploads the file. Once uploaded an automated service processes the
-// file and acts according to the given parameters.
+// by encrypting the file locally and uploading it in a single request, or in
+// parts with a multipart upload on pods that accept them. Once the upload is
+// complete, the import is started with the uploaded
+// file's name and an automated service processes the file and acts according
+// to the given parameters.
 //
 // The type of file that should be imported depends on the database. For
 // PostgreSQL and MySQL, this should be a single `.sql` file. For Mongo, this
 // should be a single tar'ed, gzipped archive (`.tar.gz`) of the database dump
 // that you want to import.
-func (d *SDb) Import(rt *transfer.ReaderTransfer, key, iv []byte, mongoCollection, mongoDatabase string, service *models.Service) (*models.Job, error) {
+func (d *SDb) Import(filename string, key, iv []byte, mongoCollection, mongoDatabase string, service *models.Service) (*models.Job, error) {
 	options := map[string]string{}
 	if mongoCollection != "" {
 		options["databaseCollection"] = mongoCollection
@@ -129,29 +297,11 @@ func (d *SDb) Import(rt *transfer.ReaderTransfer, key, iv []byte, mongoCollectio
 	if mongoDatabase != "" {
 		options["database"] = mongoDatabase
 	}
-	tmpURL, err := d.TempUploadURL(service)
-	if err != nil {
-		return nil, err
-	}
-	u, err := url.Parse(tmpURL.URL)
-	if err != nil {
-		return nil, err
-	}
-	req, err := http.NewRequest("PUT", tmpURL.URL, rt)
-	req.ContentLength = int64(rt.Length())
-	done := make(chan bool)
-	go printTransferStatus(false, rt, done)
-	_, err = http.DefaultClient.Do(req)
-	if err != nil {
-		done <- false
-		return nil, err
-	}
-	done <- true
 	importParams := map[string]interface{}{}
 	for key, value := range options {
 		importParams[key] = value
 	}
-	importParams["filename"] = strings.TrimLeft(u.Path, "/")
+	importParams["filename"] = filename
 	importParams["encryptionKey"] = string(d.Crypto.Hex(key, crypto.KeySize*2))
 	importParams["encryptionIV"] = string(d.Crypto.Hex(iv, crypto.IVSize*2))
 	importParams["dropDatabase"] = false
@@ -172,17 +322,3 @@ func (d *SDb) Import(rt *transfer.ReaderTransfer, key, iv []byte, mongoCollectio
 	}
 	return &job, nil
 }
-
-func (d *SDb) TempUploadURL(service *models.Service) (*models.TempURL, error) {
-	headers := d.Settings.HTTPManager.GetHeaders(d.Settings.SessionToken, d.Settings.Version, d.Settings.Pod, d.Settings.UsersID)
-	resp, statusCode, err := d.Settings.HTTPManager.Get(nil, fmt.Sprintf("%s%s/environments/%s/services/%s/restore-url", d.Settings.PaasHost, d.Settings.PaasHostVersion, d.Settings.EnvironmentID, service.ID), headers)
-	if err != nil {
-		return nil, err
-	}
-	var tempURL models.TempURL
-	err = d.Settings.HTTPManager.ConvertResp(resp, statusCode, &tempURL)
-	if err != nil {
-		return nil, err
-	}
-	return &tempURL, nil
-}
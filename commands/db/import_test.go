@@ -0,0 +1,230 @@
+package db
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/catalyzeio/cli/test"
+)
+
+const importCommandName = "import"
+
+func TestImport(t *testing.T) {
+	if err := test.SetUpGitRepo(); err != nil {
+		t.Fatal(err)
+	}
+	if err := test.SetUpAssociation(); err != nil {
+		t.Fatal(err)
+	}
+	small := []byte("CREATE TABLE imported (id TEXT PRIMARY KEY);\n")
+	if err := ioutil.WriteFile("small.sql", small, 0644); err != nil {
+		t.Fatal(err)
+	}
+	// large enough to be uploaded in four parts of 1 MB
+	var largeSQL bytes.Buffer
+	for i := 0; largeSQL.Len() < 3*1024*1024+512; i++ {
+		fmt.Fprintf(&largeSQL, "INSERT INTO imported (id) VALUES ('%08d');\n", i)
+	}
+	large := largeSQL.Bytes()[:3*1024*1024+512]
+	if err := ioutil.WriteFile("large.sql", large, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var gz bytes.Buffer
+	gw := gzip.NewWriter(&gz)
+	gw.Write(large)
+	gw.Close()
+	if err := ioutil.WriteFile("large.sql.gz", gz.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+	_, zstdErr := exec.LookPath("zstd")
+	if zstdErr == nil {
+		if output, err := exec.Command("zstd", "-q", "-o", "small.sql.zst", "small.sql").CombinedOutput(); err != nil {
+			t.Fatalf("Unexpected error compressing with zstd: %s", output)
+		}
+	}
+
+	var importTests = []struct {
+		filePath       string
+		stdin          bool
+		multipart      bool
+		streamed       bool
+		partSize       string
+		failures       map[int][]int
+		expectErr      bool
+		expectedOutput string
+		expectedParts  []int
+		expectedImport []byte
+	}{
+		{"small.sql", false, true, false, "", nil, false, "Import complete (end status = 'finished')", []int{1}, small},
+		{"large.sql", false, true, false, "1", map[int][]int{2: {500}}, false, "Import complete (end status = 'finished')", []int{1, 2, 3, 4}, large},
+		{"large.sql", false, true, false, "1", map[int][]int{3: {400}}, true, "Run the same command again to resume it from part 3", []int{1, 2}, nil},
+		{"large.sql", false, true, false, "1", nil, false, "Resuming upload", []int{3, 4}, large},
+		{"missing.sql", false, true, false, "", nil, true, "A file does not exist at path 'missing.sql'", nil, nil},
+		{"large.sql", true, true, false, "1", nil, false, "Encrypting to a temporary file", []int{1, 2, 3, 4}, large},
+		{"large.sql.gz", false, true, false, "1", nil, false, "Decompressing gzip input", []int{1, 2, 3, 4}, large},
+		{"large.sql.gz", true, true, true, "1", nil, false, "Import complete (end status = 'finished')", []int{1, 2, 3, 4}, large},
+		{"small.sql.zst", false, true, false, "", nil, false, "Decompressing zstd input", []int{1}, small},
+		{"small.sql", false, false, false, "", nil, false, "Import complete (end status = 'finished')", nil, small},
+		{"large.sql", true, false, false, "", nil, false, "Encrypting to a temporary file", nil, large},
+		{"large.sql.gz", false, false, false, "", nil, false, "Decompressing gzip input", nil, large},
+	}
+	for _, data := range importTests {
+		t.Logf("Data: %+v", data)
+		if strings.HasSuffix(data.filePath, ".zst") && zstdErr != nil {
+			t.Log("Skipping since zstd is not installed")
+			continue
+		}
+		test.Server.ResetImports(test.DBSvcID, data.failures)
+		if err := setImportPod(data.multipart, !data.streamed); err != nil {
+			t.Fatal(err)
+		}
+		filePath := data.filePath
+		stdin := io.Reader(strings.NewReader("n\n"))
+		if data.stdin {
+			f, err := os.Open(data.filePath)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer f.Close()
+			filePath = "-"
+			stdin = f
+		}
+		args := []string{"-y", "-E", test.Alias, "db", importCommandName, test.DBLabel, filePath, "-s"}
+		if data.partSize != "" {
+			args = append(args, "--part-size", data.partSize)
+		}
+		output, err := test.RunCommandWithStdin(test.BinaryName, args, stdin)
+		if err != nil != data.expectErr {
+			t.Errorf("Unexpected error: %s", output)
+			continue
+		}
+		if !strings.Contains(output, data.expectedOutput) {
+			t.Errorf("Expected: %s. Found: %s", data.expectedOutput, output)
+			continue
+		}
+		if data.streamed && strings.Contains(output, "Encrypting to a temporary file") {
+			t.Errorf("Expected the import to be streamed. Found: %s", output)
+		}
+		if uploaded := test.Server.Uploaded(); !reflect.DeepEqual(uploaded, data.expectedParts) {
+			t.Errorf("Expected parts %v to be uploaded. Found: %v", data.expectedParts, uploaded)
+		}
+		if imported := test.Server.Imported(test.DBSvcID); !bytes.Equal(imported, data.expectedImport) {
+			t.Errorf("Expected the file to be imported. Found %d bytes", len(imported))
+		}
+	}
+	if err := setImportPod(false, true); err != nil {
+		t.Fatal(err)
+	}
+	states, _ := filepath.Glob(filepath.Join(os.Getenv("HOME"), ".catalyze-uploads", "*"))
+	if len(states) != 0 {
+		t.Errorf("Expected the upload state to be removed after importing. Found: %v", states)
+	}
+}
+
+func TestImportStdinRequiresYes(t *testing.T) {
+	if err := test.SetUpGitRepo(); err != nil {
+		t.Fatal(err)
+	}
+	if err := test.SetUpAssociation(); err != nil {
+		t.Fatal(err)
+	}
+	test.Server.ResetImports(test.DBSvcID, nil)
+	args := []string{"-E", test.Alias, "db", importCommandName, test.DBLabel, "-", "-s"}
+	output, err := test.RunCommandWithStdin(test.BinaryName, args, strings.NewReader("y\nCREATE TABLE t (id TEXT);\n"))
+	if err == nil {
+		t.Fatalf("Expected an error when skipping the backup without --yes. Found: %s", output)
+	}
+	if !strings.Contains(output, "non-interactive") {
+		t.Errorf("Expected prompts to be disabled. Found: %s", output)
+	}
+	if test.Server.Imported(test.DBSvcID) != nil {
+		t.Error("Expected nothing to be imported")
+	}
+}
+
+// setImportPod changes whether the seeded pod accepts multipart imports and
+// requires their length and clears the pods cached by the CLI so the change is
+// seen.
+func setImportPod(multipart, requiresLength bool) error {
+	pod := &test.Server.Pods[0]
+	if pod.MultipartImports == multipart && pod.ImportRequiresLength == requiresLength {
+		return nil
+	}
+	pod.MultipartImports = multipart
+	pod.ImportRequiresLength = requiresLength
+	if output, err := test.RunCommand(test.BinaryName, []string{"clear", "--pods"}); err != nil {
+		return fmt.Errorf("Unexpected error clearing pods: %s", output)
+	}
+	return nil
+}
+
+func TestImportValidation(t *testing.T) {
+	if err := test.SetUpGitRepo(); err != nil {
+		t.Fatal(err)
+	}
+	if err := test.SetUpAssociation(); err != nil {
+		t.Fatal(err)
+	}
+	files := map[string][]byte{
+		"valid.sql":   []byte("--\n-- PostgreSQL database dump\n--\nCREATE TABLE imported (id TEXT PRIMARY KEY);\n"),
+		"custom.dump": append([]byte("PGDMP\x01\x0e\x00\x04\x08\x01\x01"), make([]byte, 64)...),
+		"mysql.sql":   []byte("-- MySQL dump 10.13  Distrib 8.0.33, for Linux (x86_64)\n--\nCREATE TABLE `imported` (`id` varchar(64));\n"),
+	}
+	var gz bytes.Buffer
+	gw := gzip.NewWriter(&gz)
+	gw.Write(files["mysql.sql"])
+	gw.Close()
+	files["mysql.sql.gz"] = gz.Bytes()
+	for name, b := range files {
+		if err := ioutil.WriteFile(name, b, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var validationTests = []struct {
+		filePath       string
+		args           []string
+		expectErr      bool
+		expectedOutput string
+		expectImport   bool
+	}{
+		{"valid.sql", []string{"--validate-only"}, false, "'valid.sql' is valid for importing into db01", false},
+		{"custom.dump", []string{"--validate-only"}, true, "The file is a pg_dump custom format archive but imports into db01 must be plain SQL", false},
+		{"custom.dump", nil, true, "pg_restore -f dump.sql FILE", false},
+		{"mysql.sql", nil, true, "The file is a MySQL dump but db01 is a PostgreSQL database", false},
+		{"mysql.sql.gz", nil, true, "The file is a MySQL dump but db01 is a PostgreSQL database", false},
+		{"custom.dump", []string{"--skip-validation"}, false, "Import complete (end status = 'finished')", true},
+	}
+	for _, data := range validationTests {
+		t.Logf("Data: %+v", data)
+		test.Server.ResetImports(test.DBSvcID, nil)
+		jobs := test.Server.JobCount(test.DBSvcID)
+		args := append([]string{"-E", test.Alias, "db", importCommandName, test.DBLabel, data.filePath}, data.args...)
+		output, err := test.RunCommand(test.BinaryName, args)
+		if err != nil != data.expectErr {
+			t.Errorf("Unexpected error: %s", output)
+			continue
+		}
+		if !strings.Contains(output, data.expectedOutput) {
+			t.Errorf("Expected: %s. Found: %s", data.expectedOutput, output)
+			continue
+		}
+		if data.expectImport {
+			if imported := test.Server.Imported(test.DBSvcID); !bytes.Equal(imported, files[data.filePath]) {
+				t.Errorf("Expected the file to be imported. Found %d bytes", len(imported))
+			}
+		} else if uploaded, newJobs := test.Server.Uploaded(), test.Server.JobCount(test.DBSvcID)-jobs; len(uploaded) != 0 || newJobs != 0 {
+			t.Errorf("Expected nothing to be backed up or uploaded. Found parts %v and %d new jobs", uploaded, newJobs)
+		}
+	}
+}
@@ -6,10 +6,12 @@ import (
 
 	"github.com/Sirupsen/logrus"
 	"github.com/catalyzeio/cli/commands/services"
+	"github.com/catalyzeio/cli/lib/httpclient"
+	"github.com/catalyzeio/cli/lib/render"
 	"github.com/catalyzeio/cli/models"
 )
 
-func CmdList(databaseName string, page, pageSize int, id IDb, is services.IServices) error {
+func CmdList(databaseName string, page, pageSize int, id IDb, is services.IServices, ir render.IRender) error {
 	service, err := is.RetrieveByLabel(databaseName)
 	if err != nil {
 		return err
@@ -22,18 +24,20 @@ func CmdList(databaseName string, page, pageSize int, id IDb, is services.IServi
 		return err
 	}
 	sort.Sort(SortedJobs(*jobs))
-	for _, job := range *jobs {
-		logrus.Printf("%s %s (status = %s)", job.ID, job.CreatedAt, job.Status)
-	}
-	if len(*jobs) == pageSize && page == 1 {
-		logrus.Println("(for older backups, try with --page 2 or adjust --page-size)")
-	}
-	if len(*jobs) == 0 && page == 1 {
-		logrus.Println("No backups created yet for this service.")
-	} else if len(*jobs) == 0 {
-		logrus.Println("No backups found with the given parameters.")
-	}
-	return nil
+	return ir.Render(jobs, func() error {
+		for _, job := range *jobs {
+			logrus.Printf("%s %s (status = %s)", job.ID, job.CreatedAt, job.Status)
+		}
+		if len(*jobs) == pageSize && page == 1 {
+			logrus.Println("(for older backups, try with --page 2 or adjust --page-size)")
+		}
+		if len(*jobs) == 0 && page == 1 {
+			logrus.Println("No backups created yet for this service.")
+		} else if len(*jobs) == 0 {
+			logrus.Println("No backups found with the given parameters.")
+		}
+		return nil
+	})
 }
 
 // SortedJobs is a wrapper for Jobs array in order to sort them by CreatedAt
@@ -66,3 +70,12 @@ func (d *SDb) List(page, pageSize int, service *models.Service) (*[]models.Job,
 	}
 	return &jobs, nil
 }
+
+// backupNotFound adds a hint for listing the backups of a database service to
+// an error for a backup ID that does not exist
+func backupNotFound(err error, backupID, databaseName string) error {
+	if !httpclient.IsNotFound(err) {
+		return err
+	}
+	return httpclient.WithHint(err, fmt.Sprintf("Could not find a backup with the ID \"%s\". You can list backups with the \"catalyze db list %s\" command.", backupID, databaseName))
+}
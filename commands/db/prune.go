@@ -0,0 +1,187 @@
+package db
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/catalyzeio/cli/commands/services"
+	"github.com/catalyzeio/cli/lib/jobs"
+	"github.com/catalyzeio/cli/lib/prompts"
+	"github.com/catalyzeio/cli/lib/render"
+	"github.com/catalyzeio/cli/models"
+	"github.com/olekukonko/tablewriter"
+)
+
+const (
+	backupDateForm = "2006-01-02T15:04:05"
+	prunePageSize  = 100
+)
+
+// retentionPolicy is the number of daily, weekly, monthly, and yearly backups
+// to keep. The newest backup in each period is the one kept for that period.
+type retentionPolicy struct {
+	Daily   int
+	Weekly  int
+	Monthly int
+	Yearly  int
+}
+
+// retentionPeriod groups backups into periods of time
+type retentionPeriod struct {
+	name string
+	keep int
+	key  func(t time.Time) string
+}
+
+func CmdPrune(databaseName string, daily, weekly, monthly, yearly int, dryRun bool, id IDb, ip prompts.IPrompts, is services.IServices, ij jobs.IJobs, ir render.IRender) error {
+	policy := retentionPolicy{Daily: daily, Weekly: weekly, Monthly: monthly, Yearly: yearly}
+	if policy.Daily < 0 || policy.Weekly < 0 || policy.Monthly < 0 || policy.Yearly < 0 {
+		return errors.New("The number of backups to keep can not be negative")
+	}
+	if policy.Daily+policy.Weekly+policy.Monthly+policy.Yearly == 0 {
+		return errors.New("At least one backup must be kept. Specify a number of daily, weekly, monthly, or yearly backups to keep")
+	}
+	service, err := is.RetrieveByLabel(databaseName)
+	if err != nil {
+		return err
+	}
+	if service == nil {
+		return fmt.Errorf("Could not find a service with the label \"%s\". You can list services with the \"catalyze services\" command.", databaseName)
+	}
+	backups, err := listAllBackups(id, service)
+	if err != nil {
+		return err
+	}
+	retention := applyRetention(backups, policy)
+	expired := []models.BackupRetention{}
+	for _, r := range retention {
+		if !r.Keep {
+			expired = append(expired, r)
+		}
+	}
+	if dryRun {
+		return ir.Render(retention, func() error {
+			printRetention(retention)
+			logrus.Printf("\n%d of %d backups would be deleted", len(expired), len(retention))
+			return nil
+		})
+	}
+	if len(expired) == 0 {
+		logrus.Printf("No backups of %s need to be deleted", databaseName)
+		return nil
+	}
+	printRetention(retention)
+	err = ip.YesNo(fmt.Sprintf("\nAre you sure you want to permanently delete %d of %d backups of %s? (y/n) ", len(expired), len(retention), databaseName))
+	if err != nil {
+		return err
+	}
+	failed := 0
+	for _, r := range expired {
+		if err = ij.Delete(r.ID, service.ID); err != nil {
+			logrus.Warnf("Failed to delete backup %s: %s", r.ID, err)
+			failed++
+			continue
+		}
+		logrus.Printf("Deleted backup %s (%s)", r.ID, r.CreatedAt)
+	}
+	if failed > 0 {
+		return fmt.Errorf("Failed to delete %d of %d backups", failed, len(expired))
+	}
+	logrus.Printf("Deleted %d backups of %s", len(expired), databaseName)
+	return nil
+}
+
+// listAllBackups pages through every backup of the given service. All pages
+// are retrieved before anything is deleted so that deletions do not shift
+// the pages.
+func listAllBackups(id IDb, service *models.Service) ([]models.Job, error) {
+	backups := []models.Job{}
+	for page := 1; ; page++ {
+		jobs, err := id.List(page, prunePageSize, service)
+		if err != nil {
+			return nil, err
+		}
+		backups = append(backups, *jobs...)
+		if len(*jobs) < prunePageSize {
+			return backups, nil
+		}
+	}
+}
+
+// applyRetention decides which backups are kept by the given policy. Only
+// finished backups are counted towards the policy or deleted. Backups that
+// are still running, failed, or have an unrecognized creation date are always
+// kept. Periods are calculated in UTC. The result is sorted newest first.
+func applyRetention(backups []models.Job, policy retentionPolicy) []models.BackupRetention {
+	periods := []retentionPeriod{
+		{"daily", policy.Daily, func(t time.Time) string { return t.Format("2006-01-02") }},
+		{"weekly", policy.Weekly, func(t time.Time) string {
+			year, week := t.ISOWeek()
+			return fmt.Sprintf("%d-W%02d", year, week)
+		}},
+		{"monthly", policy.Monthly, func(t time.Time) string { return t.Format("2006-01") }},
+		{"yearly", policy.Yearly, func(t time.Time) string { return t.Format("2006") }},
+	}
+
+	sorted := make([]models.Job, len(backups))
+	copy(sorted, backups)
+	sort.Sort(sort.Reverse(SortedJobs(sorted)))
+
+	retention := make([]models.BackupRetention, len(sorted))
+	times := make([]time.Time, len(sorted))
+	for i, job := range sorted {
+		retention[i] = models.BackupRetention{ID: job.ID, CreatedAt: job.CreatedAt, Status: job.Status}
+		t, err := time.Parse(backupDateForm, job.CreatedAt)
+		if err != nil {
+			retention[i].Keep = true
+			retention[i].Reasons = []string{"unknown date"}
+		} else if job.Status != "finished" {
+			retention[i].Keep = true
+			retention[i].Reasons = []string{job.Status}
+		}
+		times[i] = t.UTC()
+	}
+	for _, period := range periods {
+		kept := 0
+		last := ""
+		for i := range retention {
+			if kept >= period.keep {
+				break
+			}
+			if retention[i].Status != "finished" || times[i].IsZero() {
+				continue
+			}
+			if key := period.key(times[i]); key != last {
+				last = key
+				kept++
+				retention[i].Keep = true
+				retention[i].Reasons = append(retention[i].Reasons, period.name)
+			}
+		}
+	}
+	return retention
+}
+
+func printRetention(retention []models.BackupRetention) {
+	data := [][]string{{"BACKUP ID", "CREATED AT", "STATUS", "ACTION", "REASON"}}
+	for _, r := range retention {
+		action := "delete"
+		if r.Keep {
+			action = "keep"
+		}
+		data = append(data, []string{r.ID, r.CreatedAt, r.Status, action, strings.Join(r.Reasons, ", ")})
+	}
+
+	table := tablewriter.NewWriter(logrus.StandardLogger().Out)
+	table.SetBorder(false)
+	table.SetRowLine(false)
+	table.SetCenterSeparator("")
+	table.SetColumnSeparator("")
+	table.SetRowSeparator("")
+	table.AppendBulk(data)
+	table.Render()
+}
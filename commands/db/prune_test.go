@@ -0,0 +1,154 @@
+package db
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/catalyzeio/cli/models"
+	"github.com/catalyzeio/cli/test"
+)
+
+const pruneCommandName = "prune"
+
+// dailyBackups returns one finished backup at noon UTC for each day from start
+// through end inclusive, with IDs of the form backup-YYYY-MM-DD
+func dailyBackups(start, end string) []models.Job {
+	backups := []models.Job{}
+	from, _ := time.Parse("2006-01-02", start)
+	to, _ := time.Parse("2006-01-02", end)
+	for t := from; !t.After(to); t = t.AddDate(0, 0, 1) {
+		backups = append(backups, models.Job{
+			ID:        fmt.Sprintf("backup-%s", t.Format("2006-01-02")),
+			Type:      "backup",
+			Status:    "finished",
+			CreatedAt: t.Add(12 * time.Hour).Format(backupDateForm),
+		})
+	}
+	return backups
+}
+
+func TestApplyRetention(t *testing.T) {
+	backups := dailyBackups("2024-01-01", "2024-03-31")
+	backups = append(backups,
+		models.Job{ID: "backup-evening", Type: "backup", Status: "finished", CreatedAt: "2024-03-31T20:00:00"},
+		models.Job{ID: "backup-running", Type: "backup", Status: "running", CreatedAt: "2024-03-31T21:00:00"},
+		models.Job{ID: "backup-failed", Type: "backup", Status: "failed", CreatedAt: "2023-06-01T12:00:00"},
+		models.Job{ID: "backup-baddate", Type: "backup", Status: "finished", CreatedAt: "yesterday"},
+	)
+
+	var retentionTests = []struct {
+		policy   retentionPolicy
+		expected map[string]string
+	}{
+		{retentionPolicy{Daily: 7, Weekly: 4, Monthly: 3}, map[string]string{
+			"backup-running":    "running",
+			"backup-evening":    "daily, weekly, monthly",
+			"backup-2024-03-30": "daily",
+			"backup-2024-03-29": "daily",
+			"backup-2024-03-28": "daily",
+			"backup-2024-03-27": "daily",
+			"backup-2024-03-26": "daily",
+			"backup-2024-03-25": "daily",
+			"backup-2024-03-24": "weekly",
+			"backup-2024-03-17": "weekly",
+			"backup-2024-03-10": "weekly",
+			"backup-2024-02-29": "monthly",
+			"backup-2024-01-31": "monthly",
+			"backup-failed":     "failed",
+			"backup-baddate":    "unknown date",
+		}},
+		{retentionPolicy{Daily: 1, Yearly: 2}, map[string]string{
+			"backup-running": "running",
+			"backup-evening": "daily, yearly",
+			"backup-failed":  "failed",
+			"backup-baddate": "unknown date",
+		}},
+		{retentionPolicy{Weekly: 1, Monthly: 1}, map[string]string{
+			"backup-running": "running",
+			"backup-evening": "weekly, monthly",
+			"backup-failed":  "failed",
+			"backup-baddate": "unknown date",
+		}},
+	}
+	for _, data := range retentionTests {
+		t.Logf("Data: %+v", data)
+		retention := applyRetention(backups, data.policy)
+		if len(retention) != len(backups) {
+			t.Errorf("Expected %d backups. Found: %d", len(backups), len(retention))
+			continue
+		}
+		kept := 0
+		for i, r := range retention {
+			if i > 0 && retention[i-1].CreatedAt < r.CreatedAt {
+				t.Errorf("Expected backups to be sorted newest first. Found %s before %s", retention[i-1].CreatedAt, r.CreatedAt)
+			}
+			reasons, ok := data.expected[r.ID]
+			if r.Keep != ok {
+				t.Errorf("Expected backup %s to be kept: %t. Found: %t", r.ID, ok, r.Keep)
+				continue
+			}
+			if r.Keep {
+				kept++
+				if found := strings.Join(r.Reasons, ", "); found != reasons {
+					t.Errorf("Expected backup %s to be kept for %q. Found: %q", r.ID, reasons, found)
+				}
+			}
+		}
+		if kept != len(data.expected) {
+			t.Errorf("Expected %d backups to be kept. Found: %d", len(data.expected), kept)
+		}
+	}
+}
+
+func TestPrune(t *testing.T) {
+	if err := test.SetUpGitRepo(); err != nil {
+		t.Fatal(err)
+	}
+	if err := test.SetUpAssociation(); err != nil {
+		t.Fatal(err)
+	}
+
+	// one finished backup a day from 2024-03-01 through lastDay plus a
+	// running backup
+	var pruneTests = []struct {
+		lastDay        string
+		globalArgs     []string
+		args           []string
+		expectErr      bool
+		expectedOutput string
+		expectedLeft   int
+	}{
+		{"2024-03-10", nil, []string{"--daily", "3", "--weekly", "0", "--monthly", "0", "--dry-run"}, false, `(?s)BACKUP ID.*keep\s+daily.*delete.*7 of 11 backups would be deleted`, 11},
+		{"2024-03-10", nil, []string{"--daily", "3", "--weekly", "0", "--monthly", "0"}, true, "Exiting", 11},
+		{"2024-03-10", []string{"-y"}, []string{"--daily", "3", "--weekly", "0", "--monthly", "0"}, false, `(?s)Deleted backup .*Deleted 7 backups of db01`, 4},
+		{"2024-03-03", []string{"-y"}, []string{"--daily", "3", "--weekly", "0", "--monthly", "0"}, false, "No backups of db01 need to be deleted", 4},
+		{"2024-03-10", nil, []string{"--daily", "0", "--weekly", "0", "--monthly", "0"}, true, "At least one backup must be kept", 11},
+		{"2024-03-10", nil, []string{"--daily=-1"}, true, "can not be negative", 11},
+	}
+	for _, data := range pruneTests {
+		t.Logf("Data: %+v", data)
+		backups := dailyBackups("2024-03-01", data.lastDay)
+		backups = append(backups, models.Job{Type: "backup", Status: "running", CreatedAt: "2024-03-01T00:00:00"})
+		test.Server.Jobs[test.DBSvcID] = nil
+		for _, b := range backups {
+			test.Server.AddJob(test.DBSvcID, b)
+		}
+		args := append(data.globalArgs, "-E", test.Alias, "db", pruneCommandName, test.DBLabel)
+		args = append(args, data.args...)
+		output, err := test.RunCommand(test.BinaryName, args)
+		if err != nil != data.expectErr {
+			t.Errorf("Unexpected error: %s", output)
+			continue
+		}
+		if !regexp.MustCompile(data.expectedOutput).MatchString(output) {
+			t.Errorf("Expected: %s. Found: %s", data.expectedOutput, output)
+			continue
+		}
+		if left := len(test.Server.Jobs[test.DBSvcID]); left != data.expectedLeft {
+			t.Errorf("Expected %d backups to remain. Found: %d", data.expectedLeft, left)
+		}
+	}
+}
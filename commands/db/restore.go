@@ -1,53 +1,91 @@
 package db
 
-// RestoreBackup a database service from an existing backup
-/*func RestoreBackup(svcName, backupID string, skipPoll bool, id IDb, is services.IServices) error {
-	service, err := is.RetrieveByLabel(svcName)
+import (
+	"fmt"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/catalyzeio/cli/commands/services"
+	"github.com/catalyzeio/cli/lib/jobs"
+	"github.com/catalyzeio/cli/lib/prompts"
+	"github.com/catalyzeio/cli/models"
+)
+
+func CmdRestore(databaseName, backupID string, skipBackup bool, id IDb, ip prompts.IPrompts, is services.IServices, ij jobs.IJobs) error {
+	service, err := is.RetrieveByLabel(databaseName)
 	if err != nil {
 		return err
 	}
 	if service == nil {
-		return fmt.Errorf("Could not find a service with the label \"%s\"\n", svcName)
+		return fmt.Errorf("Could not find a service with the label \"%s\". You can list services with the \"catalyze services\" command.", databaseName)
 	}
-	task, err := id.Restore(backupID, service)
+	backup, err := ij.Retrieve(backupID, service.ID, false)
 	if err != nil {
-		return err
+		return backupNotFound(err, backupID, databaseName)
 	}
-	fmt.Printf("Restoring (task ID = %s)\n", task.ID)
-	if !skipPoll {
-		fmt.Print("Polling until restore finishes.")
-		ch := make(chan string, 1)
-		go helpers.PollTaskStatus(task.ID, ch, settings)
-		status := <-ch
-		task.Status = status
-		fmt.Printf("\nEnded in status '%s'\n", task.Status)
-		helpers.DumpLogs(service, task, "restore", settings)
-		if task.Status != "finished" {
-			return fmt.Errorf("Task finished with invalid status %s\n", task.Status)
+	if backup.Type != "backup" || backup.Status != "finished" {
+		return fmt.Errorf("Job %s is not a finished backup of %s. You can list backups with the \"catalyze db list %s\" command.", backupID, databaseName, databaseName)
+	}
+	if !skipBackup {
+		if err := safetyBackup(databaseName, "restore", service, id, ij); err != nil {
+			return err
+		}
+	} else {
+		err := ip.YesNo(fmt.Sprintf("Are you sure you want to restore %s to backup %s without backing it up first? (y/n) ", databaseName, backupID))
+		if err != nil {
+			return err
 		}
 	}
-}
+	logrus.Printf("Restoring %s (ID = %s) from backup %s", databaseName, service.ID, backupID)
+	job, err := id.Restore(backupID, service)
+	if err != nil {
+		return err
+	}
+	// all because logrus treats print, println, and printf the same
+	logrus.StandardLogger().Out.Write([]byte(fmt.Sprintf("Processing restore (job ID = %s).", job.ID)))
 
-func (d *SDb) Restore(backupID string, service *models.Service) (*models.Task, error) {
-	backup := map[string]string{
-		"archiveType":    "cf",
-		"encryptionType": "aes",
+	status, err := ij.PollTillFinished(job.ID, service.ID)
+	if err != nil {
+		// the logs of a failed restore usually say why it failed
+		if failed, retrieveErr := ij.Retrieve(job.ID, service.ID, false); retrieveErr == nil {
+			logrus.Println()
+			if logsErr := id.DumpLogs(failed, service); logsErr != nil {
+				logrus.Warnf("Could not retrieve the logs of restore job %s: %s", job.ID, logsErr)
+			}
+		}
+		return err
 	}
-	b, err := json.Marshal(backup)
+	job.Status = status
+	logrus.Printf("\nRestore complete (end status = '%s')", job.Status)
+	if job.Restore == nil {
+		// the keys for the restore logs may only be available once the job has run
+		restoreJob, err := ij.Retrieve(job.ID, service.ID, false)
+		if err != nil {
+			return err
+		}
+		job.Restore = restoreJob.Restore
+	}
+	err = id.DumpLogs(job, service)
 	if err != nil {
-		return nil, err
+		return err
+	}
+	if job.Status != "finished" {
+		return fmt.Errorf("Finished with invalid status %s", job.Status)
 	}
-	headers := httpclient.GetHeaders(d.Settings.APIKey, d.Settings.SessionToken, d.Settings.Version, d.Settings.Pod)
-	resp, statusCode, nil := httpclient.Post(b, fmt.Sprintf("%s%s/environments/%s/services/%s/restore/%s", d.Settings.PaasHost, d.Settings.PaasHostVersion, d.Settings.EnvironmentID, service.ID, backupID), headers)
+	return nil
+}
+
+// Restore replaces all data in a database service with the contents of an
+// existing backup of the same service.
+func (d *SDb) Restore(backupID string, service *models.Service) (*models.Job, error) {
+	headers := d.Settings.HTTPManager.GetHeaders(d.Settings.SessionToken, d.Settings.Version, d.Settings.Pod, d.Settings.UsersID)
+	resp, statusCode, err := d.Settings.HTTPManager.Post(nil, fmt.Sprintf("%s%s/environments/%s/services/%s/restore/%s", d.Settings.PaasHost, d.Settings.PaasHostVersion, d.Settings.EnvironmentID, service.ID, backupID), headers)
 	if err != nil {
 		return nil, err
 	}
-	var m map[string]string
-	err = httpclient.ConvertResp(resp, statusCode, &m)
+	var job models.Job
+	err = d.Settings.HTTPManager.ConvertResp(resp, statusCode, &job)
 	if err != nil {
 		return nil, err
 	}
-	return &models.Task{
-		ID: m["taskId"],
-	}, nil
-}*/
+	return &job, nil
+}
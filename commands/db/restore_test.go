@@ -0,0 +1,66 @@
+package db
+
+import (
+	"bytes"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/catalyzeio/cli/lib/crypto"
+	"github.com/catalyzeio/cli/models"
+	"github.com/catalyzeio/cli/test"
+)
+
+const restoreCommandName = "restore"
+
+func TestRestore(t *testing.T) {
+	if err := test.SetUpGitRepo(); err != nil {
+		t.Fatal(err)
+	}
+	if err := test.SetUpAssociation(); err != nil {
+		t.Fatal(err)
+	}
+	backupID := createBackup(t)
+	deploy := test.Server.AddJob(test.DBSvcID, models.Job{Type: "deploy", Status: "running"})
+	// a backup whose data was never uploaded fails to restore
+	empty := test.Server.AddJob(test.DBSvcID, models.Job{Type: "backup", Status: "finished", Backup: &models.EncryptionStore{
+		Key: strings.Repeat("00", crypto.KeySize),
+		IV:  strings.Repeat("00", crypto.IVSize),
+	}})
+
+	var restoreTests = []struct {
+		globalArgs     []string
+		backupID       string
+		skipBackup     bool
+		expectErr      bool
+		expectedOutput string
+	}{
+		{nil, backupID, false, false, `(?s)Backing up "db01" before performing the restore.*Restore complete \(end status = 'finished'\).*restore of db01 finished`},
+		{[]string{"-y"}, backupID, true, false, `(?s)^Restoring db01 .*Restore complete \(end status = 'finished'\)`},
+		{nil, backupID, true, true, "Exiting"},
+		{nil, deploy.ID, false, true, "is not a finished backup of db01"},
+		{[]string{"-y"}, empty.ID, true, true, `(?s)restore of db01 failed.*Error - ended in status 'failed'`},
+		{nil, "job-missing", false, true, `Could not find a backup with the ID "job-missing". You can list backups with the "catalyze db list db01" command.`},
+	}
+	for _, data := range restoreTests {
+		t.Logf("Data: %+v", data)
+		test.Server.ResetImports(test.DBSvcID, nil)
+		args := append(data.globalArgs, "-E", test.Alias, "db", restoreCommandName, test.DBLabel, data.backupID)
+		if data.skipBackup {
+			args = append(args, "--skip-backup")
+		}
+		output, err := test.RunCommand(test.BinaryName, args)
+		if err != nil != data.expectErr {
+			t.Errorf("Unexpected error: %s", output)
+			continue
+		}
+		if !regexp.MustCompile(data.expectedOutput).MatchString(output) {
+			t.Errorf("Expected: %s. Found: %s", data.expectedOutput, output)
+			continue
+		}
+		restored := test.Server.Imported(test.DBSvcID)
+		if !data.expectErr && !bytes.Equal(restored, test.Server.BackupData[test.DBSvcID]) {
+			t.Errorf("Expected the backup to be restored. Found: %q", restored)
+		}
+	}
+}
@@ -0,0 +1,461 @@
+package db
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/catalyzeio/cli/lib/atomicfile"
+	"github.com/catalyzeio/cli/lib/crypto"
+	"github.com/catalyzeio/cli/lib/interrupt"
+	"github.com/catalyzeio/cli/lib/transfer"
+	"github.com/catalyzeio/cli/models"
+	"github.com/mitchellh/go-homedir"
+)
+
+const (
+	// uploadStateDir is the directory in the user's home directory that holds
+	// the state of interrupted uploads
+	uploadStateDir = ".catalyze-uploads"
+	// maxUploadParts is the most parts a single upload may be split into
+	maxUploadParts = 10000
+	// maxPartAttempts is the number of times a single part is attempted before
+	// giving up on the upload
+	maxPartAttempts = 5
+)
+
+// uploadState is the progress of a multipart upload. It is saved after every
+// part so that an interrupted upload can be resumed. Since the same key and IV
+// must be used to finish an upload, the state file is only readable by its
+// owner and is removed once the import has been submitted.
+type uploadState struct {
+	EnvironmentID string              `json:"environmentId"`
+	ServiceID     string              `json:"serviceId"`
+	FilePath      string              `json:"filePath"`
+	Size          int64               `json:"size"`
+	ModTime       time.Time           `json:"modTime"`
+	PartSize      int64               `json:"partSize"`
+	Key           string              `json:"key"`
+	IV            string              `json:"iv"`
+	Upload        models.Upload       `json:"upload"`
+	Parts         []models.UploadPart `json:"parts"`
+	Completed     bool                `json:"completed"`
+
+	path string
+}
+
+// partSizeFor returns the plaintext size of each part when uploading a file of
+// the given size. The requested size is rounded up to a whole number of
+// encryption chunks so each part can be encrypted on its own, and is grown if
+// needed to keep the upload under the maximum number of parts.
+func partSizeFor(size, requested int64) int64 {
+	partSize := requested
+	if min := (size + maxUploadParts - 1) / maxUploadParts; partSize < min {
+		partSize = min
+	}
+	if rem := partSize % crypto.ChunkSize; rem != 0 || partSize == 0 {
+		partSize += crypto.ChunkSize - rem
+	}
+	return partSize
+}
+
+// loadUploadState returns the saved state of a previous attempt to upload the
+// given file to the given service. If there is no saved state, or the file has
+// changed since, a new state with a fresh key and IV is returned.
+func loadUploadState(filePath string, fi os.FileInfo, partSize int64, service *models.Service, settings *models.Settings) (*uploadState, error) {
+	absPath, err := filepath.Abs(filePath)
+	if err != nil {
+		return nil, err
+	}
+	homeDir, err := homedir.Dir()
+	if err != nil {
+		return nil, err
+	}
+	sum := sha256.Sum256([]byte(settings.EnvironmentID + "/" + service.ID + "/" + absPath))
+	path := filepath.Join(homeDir, uploadStateDir, hex.EncodeToString(sum[:16])+".json")
+
+	state := &uploadState{}
+	if b, err := ioutil.ReadFile(path); err == nil && json.Unmarshal(b, state) == nil &&
+		state.Size == fi.Size() && state.ModTime.Equal(fi.ModTime()) && state.Upload.ID != "" {
+		state.path = path
+		return state, nil
+	}
+	key := make([]byte, crypto.KeySize)
+	iv := make([]byte, crypto.IVSize)
+	if _, err = io.ReadFull(rand.Reader, key); err != nil {
+		return nil, err
+	}
+	if _, err = io.ReadFull(rand.Reader, iv); err != nil {
+		return nil, err
+	}
+	return &uploadState{
+		EnvironmentID: settings.EnvironmentID,
+		ServiceID:     service.ID,
+		FilePath:      absPath,
+		Size:          fi.Size(),
+		ModTime:       fi.ModTime(),
+		PartSize:      partSizeFor(fi.Size(), partSize),
+		Key:           hex.EncodeToString(key),
+		IV:            hex.EncodeToString(iv),
+		Parts:         []models.UploadPart{},
+		path:          path,
+	}, nil
+}
+
+// save writes the upload state to disk
+func (s *uploadState) save() error {
+	if err := os.MkdirAll(filepath.Dir(s.path), 0700); err != nil {
+		return err
+	}
+	b, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+	return atomicfile.WriteFile(s.path, b, 0600)
+}
+
+// remove deletes the saved upload state
+func (s *uploadState) remove() error {
+	err := os.Remove(s.path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	return err
+}
+
+// numParts returns the number of parts the file is uploaded in
+func (s *uploadState) numParts() int {
+	if s.Size == 0 {
+		return 1
+	}
+	return int((s.Size + s.PartSize - 1) / s.PartSize)
+}
+
+// partRange returns the plaintext offset and length of the given part
+func (s *uploadState) partRange(number int) (int64, int64) {
+	offset := int64(number-1) * s.PartSize
+	length := s.PartSize
+	if offset+length > s.Size {
+		length = s.Size - offset
+	}
+	return offset, length
+}
+
+// encryptedSize returns the size of the file once it has been encrypted
+func (s *uploadState) encryptedSize() int64 {
+	var total int64
+	for i := 1; i <= s.numParts(); i++ {
+		total += encryptedPartSize(s.partRange(i))
+	}
+	return total
+}
+
+// encryptedPartSize returns the encrypted size of a part starting at offset
+// with the given plaintext length
+func encryptedPartSize(offset, length int64) int64 {
+	chunks := (length + crypto.ChunkSize - 1) / crypto.ChunkSize
+	return length + chunks*crypto.TagSize
+}
+
+// uploadFile encrypts and uploads every part of the file that has not already
+// been uploaded, saving the state after each one, and then completes the
+// upload.
+func uploadFile(ctx context.Context, file *os.File, state *uploadState, service *models.Service, id IDb) error {
+	if state.Completed {
+		return nil
+	}
+	key, err := hex.DecodeString(state.Key)
+	if err != nil {
+		return err
+	}
+	iv, err := hex.DecodeString(state.IV)
+	if err != nil {
+		return err
+	}
+	uploaded := map[int]bool{}
+	var done int64
+	for _, part := range state.Parts {
+		uploaded[part.Number] = true
+		done += encryptedPartSize(state.partRange(part.Number))
+	}
+	if len(uploaded) > 0 {
+		logrus.Printf("Resuming upload of '%s' (%d of %d parts already uploaded)", state.FilePath, len(uploaded), state.numParts())
+	}
+	pt := transfer.NewProgressTransfer(state.encryptedSize(), done)
+	finished := make(chan bool)
+	go printTransferStatus(false, pt, finished)
+	for number := 1; number <= state.numParts(); number++ {
+		if uploaded[number] {
+			continue
+		}
+		offset, length := state.partRange(number)
+		etag, err := uploadPart(ctx, number, func() (io.Reader, int64, error) {
+			return id.NewEncryptReaderAt(io.NewSectionReader(file, offset, length), key, iv, offset, length)
+		}, &state.Upload, pt, service, id)
+		if err != nil {
+			finished <- false
+			return fmt.Errorf("%s\nThe upload was interrupted. Run the same command again to resume it from part %d", err, number)
+		}
+		state.Parts = append(state.Parts, models.UploadPart{Number: number, ETag: etag})
+		if err = state.save(); err != nil {
+			finished <- false
+			return err
+		}
+	}
+	finished <- true
+	if err := id.CompleteUpload(&state.Upload, state.Parts, service); err != nil {
+		return err
+	}
+	state.Completed = true
+	return state.save()
+}
+
+// uploadSpooled uploads an already encrypted file of the given size in parts
+// of partSize bytes and completes the upload
+func uploadSpooled(ctx context.Context, spool *os.File, size, partSize int64, upload *models.Upload, service *models.Service, id IDb) error {
+	parts := []models.UploadPart{}
+	pt := transfer.NewProgressTransfer(size, 0)
+	finished := make(chan bool)
+	go printTransferStatus(false, pt, finished)
+	for number, offset := 1, int64(0); offset < size || number == 1; number, offset = number+1, offset+partSize {
+		length := partSize
+		if offset+length > size {
+			length = size - offset
+		}
+		start := offset
+		etag, err := uploadPart(ctx, number, func() (io.Reader, int64, error) {
+			return io.NewSectionReader(spool, start, length), length, nil
+		}, upload, pt, service, id)
+		if err != nil {
+			finished <- false
+			return err
+		}
+		parts = append(parts, models.UploadPart{Number: number, ETag: etag})
+	}
+	finished <- true
+	return id.CompleteUpload(upload, parts, service)
+}
+
+// uploadStream uploads already encrypted data of unknown length as it is read,
+// holding one part of partSize bytes in memory at a time, and completes the
+// upload
+func uploadStream(ctx context.Context, r io.Reader, partSize int64, upload *models.Upload, service *models.Service, id IDb) error {
+	parts := []models.UploadPart{}
+	pt := transfer.NewProgressTransfer(-1, 0)
+	finished := make(chan bool)
+	go printTransferStatus(false, pt, finished)
+	buf := make([]byte, partSize)
+	for number := 1; ; number++ {
+		n, err := io.ReadFull(r, buf)
+		if err == io.EOF && number > 1 {
+			break
+		} else if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+			finished <- false
+			return err
+		}
+		part := buf[:n]
+		etag, uploadErr := uploadPart(ctx, number, func() (io.Reader, int64, error) {
+			return bytes.NewReader(part), int64(len(part)), nil
+		}, upload, pt, service, id)
+		if uploadErr != nil {
+			finished <- false
+			return uploadErr
+		}
+		parts = append(parts, models.UploadPart{Number: number, ETag: etag})
+		if err != nil {
+			break
+		}
+	}
+	finished <- true
+	return id.CompleteUpload(upload, parts, service)
+}
+
+// uploadPart uploads a single part of an upload and returns its ETag. Since a
+// part is retried with a fresh URL if it fails with an error that may be
+// temporary, open is called to get the encrypted contents of the part for
+// every attempt.
+func uploadPart(ctx context.Context, number int, open func() (io.Reader, int64, error), upload *models.Upload, pt *transfer.ProgressTransfer, service *models.Service, id IDb) (string, error) {
+	var err error
+	for attempt := 1; attempt <= maxPartAttempts; attempt++ {
+		if attempt > 1 {
+			delay := time.Duration(1<<uint(attempt-2)) * time.Second
+			logrus.Debugf("Retrying part %d in %s: %s", number, delay, err)
+			if err := interrupt.Sleep(ctx, delay); err != nil {
+				return "", err
+			}
+		}
+		var tempURL *models.TempURL
+		tempURL, err = id.TempPartURL(upload, number, service)
+		if err != nil {
+			return "", err
+		}
+		var body io.Reader
+		var size int64
+		body, size, err = open()
+		if err != nil {
+			return "", err
+		}
+		pr := &progressReader{reader: body, pt: pt}
+		var etag string
+		var retry bool
+		etag, retry, err = id.UploadPart(tempURL.URL, pr, size)
+		if err == nil {
+			return etag, nil
+		}
+		// start this part over in the progress
+		pt.Add(-pr.read)
+		if !retry {
+			break
+		}
+	}
+	return "", fmt.Errorf("Failed to upload part %d: %s", number, err)
+}
+
+// progressReader records everything read from it in a ProgressTransfer
+type progressReader struct {
+	reader io.Reader
+	pt     *transfer.ProgressTransfer
+	read   int64
+}
+
+func (pr *progressReader) Read(p []byte) (int, error) {
+	n, err := pr.reader.Read(p)
+	pr.read += int64(n)
+	pr.pt.Add(int64(n))
+	return n, err
+}
+
+// TempUploadURL retrieves a temporary URL that an import can be uploaded to
+// in a single request
+func (d *SDb) TempUploadURL(service *models.Service) (*models.TempURL, error) {
+	headers := d.Settings.HTTPManager.GetHeaders(d.Settings.SessionToken, d.Settings.Version, d.Settings.Pod, d.Settings.UsersID)
+	resp, statusCode, err := d.Settings.HTTPManager.Get(nil, fmt.Sprintf("%s%s/environments/%s/services/%s/restore-url", d.Settings.PaasHost, d.Settings.PaasHostVersion, d.Settings.EnvironmentID, service.ID), headers)
+	if err != nil {
+		return nil, err
+	}
+	var tempURL models.TempURL
+	err = d.Settings.HTTPManager.ConvertResp(resp, statusCode, &tempURL)
+	if err != nil {
+		return nil, err
+	}
+	return &tempURL, nil
+}
+
+// UploadSingle uploads length bytes of encrypted data to a temporary URL in a
+// single request and returns the name of the uploaded file to import
+func (d *SDb) UploadSingle(r io.Reader, length int64, service *models.Service) (string, error) {
+	tempURL, err := d.TempUploadURL(service)
+	if err != nil {
+		return "", err
+	}
+	u, err := url.Parse(tempURL.URL)
+	if err != nil {
+		return "", err
+	}
+	pt := transfer.NewProgressTransfer(length, 0)
+	finished := make(chan bool)
+	go printTransferStatus(false, pt, finished)
+	_, _, err = d.UploadPart(tempURL.URL, &progressReader{reader: r, pt: pt}, length)
+	if err != nil {
+		finished <- false
+		return "", err
+	}
+	finished <- true
+	return strings.TrimLeft(u.Path, "/"), nil
+}
+
+// StartUpload begins a new multipart upload to the given service. The size is
+// the total number of encrypted bytes that will be uploaded, or -1 if it is
+// not known up front. Pods that require the length of an import reject
+// uploads of unknown size.
+func (d *SDb) StartUpload(size int64, service *models.Service) (*models.Upload, error) {
+	params := map[string]int64{}
+	if size >= 0 {
+		params["size"] = size
+	}
+	b, err := json.Marshal(params)
+	if err != nil {
+		return nil, err
+	}
+	headers := d.Settings.HTTPManager.GetHeaders(d.Settings.SessionToken, d.Settings.Version, d.Settings.Pod, d.Settings.UsersID)
+	resp, statusCode, err := d.Settings.HTTPManager.Post(b, fmt.Sprintf("%s%s/environments/%s/services/%s/restore-url/multipart", d.Settings.PaasHost, d.Settings.PaasHostVersion, d.Settings.EnvironmentID, service.ID), headers)
+	if err != nil {
+		return nil, err
+	}
+	var upload models.Upload
+	err = d.Settings.HTTPManager.ConvertResp(resp, statusCode, &upload)
+	if err != nil {
+		return nil, err
+	}
+	return &upload, nil
+}
+
+// TempPartURL retrieves a temporary URL that a single part of a multipart
+// upload can be uploaded to. Parts are numbered starting at 1.
+func (d *SDb) TempPartURL(upload *models.Upload, number int, service *models.Service) (*models.TempURL, error) {
+	headers := d.Settings.HTTPManager.GetHeaders(d.Settings.SessionToken, d.Settings.Version, d.Settings.Pod, d.Settings.UsersID)
+	resp, statusCode, err := d.Settings.HTTPManager.Get(nil, fmt.Sprintf("%s%s/environments/%s/services/%s/restore-url/multipart/%s/%d", d.Settings.PaasHost, d.Settings.PaasHostVersion, d.Settings.EnvironmentID, service.ID, upload.ID, number), headers)
+	if err != nil {
+		return nil, err
+	}
+	var tempURL models.TempURL
+	err = d.Settings.HTTPManager.ConvertResp(resp, statusCode, &tempURL)
+	if err != nil {
+		return nil, err
+	}
+	return &tempURL, nil
+}
+
+// UploadPart uploads a single part to the given temporary URL and returns the
+// part's ETag. If the upload fails, the returned bool reports whether the
+// failure may be temporary and the part should be retried.
+func (d *SDb) UploadPart(url string, body io.Reader, length int64) (string, bool, error) {
+	req, err := http.NewRequest("PUT", url, body)
+	if err != nil {
+		return "", false, err
+	}
+	req.ContentLength = length
+	ctx := d.Settings.HTTPManager.Context()
+	resp, err := http.DefaultClient.Do(req.WithContext(ctx))
+	if ctxErr := interrupt.Err(ctx); ctxErr != nil {
+		return "", false, ctxErr
+	}
+	if err != nil {
+		return "", true, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		b, _ := ioutil.ReadAll(resp.Body)
+		retry := resp.StatusCode >= 500 || resp.StatusCode == http.StatusRequestTimeout || resp.StatusCode == http.StatusTooManyRequests
+		return "", retry, fmt.Errorf("(%d) %s", resp.StatusCode, string(b))
+	}
+	return resp.Header.Get("ETag"), false, nil
+}
+
+// CompleteUpload finishes a multipart upload once all of its parts have been
+// uploaded so the uploaded file can be imported
+func (d *SDb) CompleteUpload(upload *models.Upload, parts []models.UploadPart, service *models.Service) error {
+	b, err := json.Marshal(map[string][]models.UploadPart{"parts": parts})
+	if err != nil {
+		return err
+	}
+	headers := d.Settings.HTTPManager.GetHeaders(d.Settings.SessionToken, d.Settings.Version, d.Settings.Pod, d.Settings.UsersID)
+	resp, statusCode, err := d.Settings.HTTPManager.Post(b, fmt.Sprintf("%s%s/environments/%s/services/%s/restore-url/multipart/%s", d.Settings.PaasHost, d.Settings.PaasHostVersion, d.Settings.EnvironmentID, service.ID, upload.ID), headers)
+	if err != nil {
+		return err
+	}
+	return d.Settings.HTTPManager.ConvertResp(resp, statusCode, nil)
+}
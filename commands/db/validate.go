@@ -0,0 +1,160 @@
+package db
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/catalyzeio/cli/models"
+)
+
+// sniffSize is the number of bytes at the start of an import used to detect
+// its format
+const sniffSize = 8192
+
+const (
+	postgresEngine = "PostgreSQL"
+	mysqlEngine    = "MySQL"
+	mongoEngine    = "MongoDB"
+)
+
+const (
+	formatEmpty     = "an empty file"
+	formatSQL       = "a plain SQL dump"
+	formatPgDump    = "a PostgreSQL dump"
+	formatMySQLDump = "a MySQL dump"
+	formatPgCustom  = "a pg_dump custom format archive"
+	formatTar       = "a tar archive"
+	formatTarGz     = "a gzipped tar archive"
+	formatGzip      = "a gzip compressed file"
+	formatJSON      = "JSON"
+	formatBinary    = "a binary file"
+)
+
+var (
+	pgCustomMagic    = []byte("PGDMP")
+	tarMagic         = []byte("ustar")
+	pgDumpMarkers    = []string{"-- PostgreSQL database dump", "-- PostgreSQL database cluster dump"}
+	mysqlDumpMarkers = []string{"-- MySQL dump", "-- MariaDB dump"}
+)
+
+// databaseEngine returns the database engine of the given service from its
+// name, falling back to its label, or an empty string if it is not known
+func databaseEngine(service *models.Service) string {
+	for _, name := range []string{service.Name, service.Label} {
+		name = strings.ToLower(name)
+		switch {
+		case strings.HasPrefix(name, "postgres"):
+			return postgresEngine
+		case strings.HasPrefix(name, "mysql"), strings.HasPrefix(name, "mariadb"):
+			return mysqlEngine
+		case strings.HasPrefix(name, "mongo"):
+			return mongoEngine
+		}
+	}
+	return ""
+}
+
+// sniffFormat detects the format of an import from its first bytes
+func sniffFormat(header []byte) string {
+	switch {
+	case len(header) == 0:
+		return formatEmpty
+	case bytes.HasPrefix(header, pgCustomMagic):
+		return formatPgCustom
+	case isTar(header):
+		return formatTar
+	case bytes.HasPrefix(header, gzipMagic):
+		gr, err := gzip.NewReader(bytes.NewReader(header))
+		if err != nil {
+			return formatGzip
+		}
+		// the header is only the start of the stream so the decompressed data
+		// is expected to end early
+		decompressed := make([]byte, 512)
+		n, _ := io.ReadFull(gr, decompressed)
+		if isTar(decompressed[:n]) {
+			return formatTarGz
+		}
+		return formatGzip
+	case bytes.IndexByte(header, 0) != -1:
+		return formatBinary
+	}
+	text := string(header)
+	trimmed := strings.TrimLeft(text, " \t\r\n\ufeff")
+	if strings.HasPrefix(trimmed, "{") || strings.HasPrefix(trimmed, "[") {
+		return formatJSON
+	}
+	for _, marker := range pgDumpMarkers {
+		if strings.Contains(text, marker) {
+			return formatPgDump
+		}
+	}
+	for _, marker := range mysqlDumpMarkers {
+		if strings.Contains(text, marker) {
+			return formatMySQLDump
+		}
+	}
+	return formatSQL
+}
+
+// isTar reports whether the data starts with a POSIX tar header
+func isTar(header []byte) bool {
+	return len(header) >= 262 && bytes.Equal(header[257:262], tarMagic)
+}
+
+// checkFormat returns an error explaining why an import in the given format
+// can not be imported into a database of the given engine
+func checkFormat(engine, format, databaseName string) error {
+	switch engine {
+	case postgresEngine:
+		switch format {
+		case formatSQL, formatPgDump:
+			return nil
+		case formatPgCustom:
+			return fmt.Errorf("The file is %s but imports into %s must be plain SQL. Convert it with \"pg_restore -f dump.sql FILE\" or create the dump with \"pg_dump --format=plain\"", format, databaseName)
+		case formatMySQLDump:
+			return fmt.Errorf("The file is %s but %s is a %s database. Make sure you are importing into the right database", format, databaseName, engine)
+		}
+		return fmt.Errorf("The file is %s but imports into %s must be a plain SQL dump, optionally gzip or zstd compressed", format, databaseName)
+	case mysqlEngine:
+		switch format {
+		case formatSQL, formatMySQLDump:
+			return nil
+		case formatPgDump, formatPgCustom:
+			return fmt.Errorf("The file is %s but %s is a %s database. Make sure you are importing into the right database", format, databaseName, engine)
+		}
+		return fmt.Errorf("The file is %s but imports into %s must be a plain SQL dump, optionally gzip or zstd compressed", format, databaseName)
+	case mongoEngine:
+		switch format {
+		case formatTarGz, formatTar, formatJSON:
+			return nil
+		case formatSQL, formatPgDump, formatMySQLDump, formatPgCustom:
+			return fmt.Errorf("The file is %s but %s is a %s database. Make sure you are importing into the right database", format, databaseName, engine)
+		}
+		return fmt.Errorf("The file is %s but imports into %s must be a gzipped tar of a mongodump output directory or JSON", format, databaseName)
+	}
+	return nil
+}
+
+// validateImport checks that the start of the import is in a format the
+// given database service can import. The data is peeked at so none of it is
+// consumed from the reader.
+func validateImport(service *models.Service, br *bufio.Reader) error {
+	engine := databaseEngine(service)
+	if engine == "" {
+		logrus.Debugf("Skipping validation of the import since the database engine of %s is not known", service.Label)
+		return nil
+	}
+	header, err := br.Peek(sniffSize)
+	if err != nil && err != io.EOF && err != bufio.ErrBufferFull {
+		return err
+	}
+	format := sniffFormat(header)
+	logrus.Debugf("Detected %s for a %s import", format, engine)
+	return checkFormat(engine, format, service.Label)
+}
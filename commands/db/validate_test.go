@@ -0,0 +1,74 @@
+package db
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"testing"
+
+	"github.com/catalyzeio/cli/models"
+)
+
+func mongodumpArchive(t *testing.T, compress bool) []byte {
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	contents := []byte("bson")
+	if err := tw.WriteHeader(&tar.Header{Name: "dump/catalyze/users.bson", Mode: 0644, Size: int64(len(contents))}); err != nil {
+		t.Fatal(err)
+	}
+	tw.Write(contents)
+	tw.Close()
+	if !compress {
+		return buf.Bytes()
+	}
+	var gz bytes.Buffer
+	gw := gzip.NewWriter(&gz)
+	gw.Write(buf.Bytes())
+	gw.Close()
+	return gz.Bytes()
+}
+
+func TestValidateImport(t *testing.T) {
+	postgres := &models.Service{Label: "db01", Name: "postgresql", Type: "database"}
+	mysql := &models.Service{Label: "db02", Name: "mysql", Type: "database"}
+	mongo := &models.Service{Label: "mongo01", Name: "mongodb", Type: "database"}
+	unknown := &models.Service{Label: "cache01", Name: "redis", Type: "database"}
+	gzippedSQL := &bytes.Buffer{}
+	gw := gzip.NewWriter(gzippedSQL)
+	gw.Write([]byte("SELECT 1;\n"))
+	gw.Close()
+
+	var validateTests = []struct {
+		service        *models.Service
+		data           []byte
+		expectedFormat string
+		expectErr      bool
+	}{
+		{postgres, []byte("CREATE TABLE t (id INT);\n"), formatSQL, false},
+		{postgres, []byte("--\n-- PostgreSQL database dump\n--\n"), formatPgDump, false},
+		{postgres, []byte("PGDMP\x01\x0e\x00"), formatPgCustom, true},
+		{postgres, []byte("-- MySQL dump 10.13\n"), formatMySQLDump, true},
+		{postgres, []byte{}, formatEmpty, true},
+		{postgres, []byte{0x01, 0x00, 0x02}, formatBinary, true},
+		{mysql, []byte("-- MariaDB dump 10.19\n"), formatMySQLDump, false},
+		{mysql, []byte("INSERT INTO t VALUES (1);\n"), formatSQL, false},
+		{mysql, []byte("--\n-- PostgreSQL database dump\n--\n"), formatPgDump, true},
+		{mongo, mongodumpArchive(t, true), formatTarGz, false},
+		{mongo, mongodumpArchive(t, false), formatTar, false},
+		{mongo, []byte("  {\"_id\": 1}\n"), formatJSON, false},
+		{mongo, []byte("[{\"_id\": 1}]"), formatJSON, false},
+		{mongo, gzippedSQL.Bytes(), formatGzip, true},
+		{mongo, []byte("CREATE TABLE t (id INT);\n"), formatSQL, true},
+		{unknown, []byte{0x01, 0x00, 0x02}, formatBinary, false},
+	}
+	for _, data := range validateTests {
+		t.Logf("Data: %+v", data)
+		if format := sniffFormat(data.data); format != data.expectedFormat {
+			t.Errorf("Expected %s. Found: %s", data.expectedFormat, format)
+		}
+		err := checkFormat(databaseEngine(data.service), data.expectedFormat, data.service.Label)
+		if err != nil != data.expectErr {
+			t.Errorf("Unexpected error: %v", err)
+		}
+	}
+}
@@ -1,8 +1,8 @@
 package defaultcmd
 
 import (
-	"github.com/Sirupsen/logrus"
 	"github.com/catalyzeio/cli/config"
+	"github.com/catalyzeio/cli/lib/httpclient"
 	"github.com/catalyzeio/cli/models"
 	"github.com/jault3/mow.cli"
 )
@@ -22,11 +22,11 @@ var Cmd = models.Command{
 			alias := cmd.StringArg("ENV_ALIAS", "", "The alias of an already associated environment to set as the default")
 			cmd.Action = func() {
 				if err := config.CheckRequiredAssociation(true, false, settings); err != nil {
-					logrus.Fatal(err.Error())
+					httpclient.Fatal(err)
 				}
 				err := CmdDefault(*alias, New(settings))
 				if err != nil {
-					logrus.Fatal(err.Error())
+					httpclient.Fatal(err)
 				}
 			}
 			cmd.Spec = "ENV_ALIAS"
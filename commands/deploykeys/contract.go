@@ -5,10 +5,10 @@ import (
 
 	"golang.org/x/crypto/ssh"
 
-	"github.com/Sirupsen/logrus"
 	"github.com/catalyzeio/cli/commands/services"
 	"github.com/catalyzeio/cli/config"
 	"github.com/catalyzeio/cli/lib/auth"
+	"github.com/catalyzeio/cli/lib/httpclient"
 	"github.com/catalyzeio/cli/lib/prompts"
 	"github.com/catalyzeio/cli/models"
 	"github.com/jault3/mow.cli"
@@ -41,15 +41,15 @@ var AddSubCmd = models.Command{
 			path := subCmd.StringArg("KEY_PATH", "", "Relative path to the SSH key file")
 			serviceName := subCmd.StringArg("SERVICE_NAME", "", "The name of the code service to add this deploy key to")
 			subCmd.Action = func() {
-				if _, err := auth.New(settings, prompts.New()).Signin(); err != nil {
-					logrus.Fatal(err.Error())
+				if _, err := auth.New(settings, prompts.New(settings)).Signin(); err != nil {
+					httpclient.Fatal(err)
 				}
 				if err := config.CheckRequiredAssociation(true, true, settings); err != nil {
-					logrus.Fatal(err.Error())
+					httpclient.Fatal(err)
 				}
 				err := CmdAdd(*name, *path, *serviceName, New(settings), services.New(settings))
 				if err != nil {
-					logrus.Fatal(err.Error())
+					httpclient.Fatal(err)
 				}
 			}
 			subCmd.Spec = "NAME KEY_PATH SERVICE_NAME"
@@ -66,15 +66,15 @@ var ListSubCmd = models.Command{
 		return func(subCmd *cli.Cmd) {
 			serviceName := subCmd.StringArg("SERVICE_NAME", "", "The name of the code service to list deploy keys")
 			subCmd.Action = func() {
-				if _, err := auth.New(settings, prompts.New()).Signin(); err != nil {
-					logrus.Fatal(err.Error())
+				if _, err := auth.New(settings, prompts.New(settings)).Signin(); err != nil {
+					httpclient.Fatal(err)
 				}
 				if err := config.CheckRequiredAssociation(true, true, settings); err != nil {
-					logrus.Fatal(err.Error())
+					httpclient.Fatal(err)
 				}
 				err := CmdList(*serviceName, New(settings), services.New(settings))
 				if err != nil {
-					logrus.Fatal(err.Error())
+					httpclient.Fatal(err)
 				}
 			}
 			subCmd.Spec = "SERVICE_NAME"
@@ -94,15 +94,15 @@ var RmSubCmd = models.Command{
 			name := subCmd.StringArg("NAME", "", "The name of the key to remove")
 			serviceName := subCmd.StringArg("SERVICE_NAME", "", "The name of the code service to remove this deploy key from")
 			subCmd.Action = func() {
-				if _, err := auth.New(settings, prompts.New()).Signin(); err != nil {
-					logrus.Fatal(err.Error())
+				if _, err := auth.New(settings, prompts.New(settings)).Signin(); err != nil {
+					httpclient.Fatal(err)
 				}
 				if err := config.CheckRequiredAssociation(true, true, settings); err != nil {
-					logrus.Fatal(err.Error())
+					httpclient.Fatal(err)
 				}
 				err := CmdRm(*name, *serviceName, New(settings), services.New(settings))
 				if err != nil {
-					logrus.Fatal(err.Error())
+					httpclient.Fatal(err)
 				}
 			}
 			subCmd.Spec = "NAME SERVICE_NAME"
@@ -4,26 +4,32 @@ import (
 	"fmt"
 
 	"github.com/Sirupsen/logrus"
+	"github.com/catalyzeio/cli/lib/render"
 	"github.com/catalyzeio/cli/models"
 )
 
 // CmdList lists all environments which the user has access to
-func CmdList(environments IEnvironments) error {
+func CmdList(environments IEnvironments, ir render.IRender) error {
 	envs, errs := environments.List()
-	if envs == nil || len(*envs) == 0 {
-		logrus.Println("no environments found")
-	} else {
-		for _, env := range *envs {
-			logrus.Printf("%s: %s", env.Name, env.ID)
-		}
+	for pod, err := range errs {
+		logrus.Debugf("Failed to list environments for pod \"%s\": %s", pod, err)
 	}
-	if errs != nil && len(errs) > 0 {
-		for pod, err := range errs {
-			logrus.Debugf("Failed to list environments for pod \"%s\": %s", pod, err)
-		}
-		logrus.Println("If the environment you're looking for is not listed, ensure you have the correct permissions from your organization owner. If the environment is still not listed, please contact support@catalyze.io.")
+	if envs == nil {
+		envs = &[]models.Environment{}
 	}
-	return nil
+	return ir.Render(envs, func() error {
+		if len(*envs) == 0 {
+			logrus.Println("no environments found")
+		} else {
+			for _, env := range *envs {
+				logrus.Printf("%s: %s", env.Name, env.ID)
+			}
+		}
+		if len(errs) > 0 {
+			logrus.Println("If the environment you're looking for is not listed, ensure you have the correct permissions from your organization owner. If the environment is still not listed, please contact support@catalyze.io.")
+		}
+		return nil
+	})
 }
 
 func (e *SEnvironments) List() (*[]models.Environment, map[string]error) {
@@ -1,10 +1,10 @@
 package git
 
 import (
-	"github.com/Sirupsen/logrus"
 	"github.com/catalyzeio/cli/commands/services"
 	"github.com/catalyzeio/cli/config"
 	"github.com/catalyzeio/cli/lib/auth"
+	"github.com/catalyzeio/cli/lib/httpclient"
 	"github.com/catalyzeio/cli/lib/prompts"
 	"github.com/catalyzeio/cli/models"
 	"github.com/jault3/mow.cli"
@@ -36,15 +36,15 @@ var AddSubCmd = models.Command{
 			serviceName := subCmd.StringArg("SERVICE_NAME", "", "The name of the service to add a git remote for")
 			remote := subCmd.StringOpt("r remote", "catalyze", "The name of the git remote to be added")
 			subCmd.Action = func() {
-				if _, err := auth.New(settings, prompts.New()).Signin(); err != nil {
-					logrus.Fatal(err.Error())
+				if _, err := auth.New(settings, prompts.New(settings)).Signin(); err != nil {
+					httpclient.Fatal(err)
 				}
 				if err := config.CheckRequiredAssociation(true, true, settings); err != nil {
-					logrus.Fatal(err.Error())
+					httpclient.Fatal(err)
 				}
 				err := CmdAdd(*serviceName, *remote, New(), services.New(settings))
 				if err != nil {
-					logrus.Fatalln(err.Error())
+					httpclient.Fatal(err)
 				}
 			}
 			subCmd.Spec = "SERVICE_NAME [-r]"
@@ -63,15 +63,15 @@ var ShowSubCmd = models.Command{
 		return func(subCmd *cli.Cmd) {
 			serviceName := subCmd.StringArg("SERVICE_NAME", "", "The name of the service to add a git remote for")
 			subCmd.Action = func() {
-				if _, err := auth.New(settings, prompts.New()).Signin(); err != nil {
-					logrus.Fatal(err.Error())
+				if _, err := auth.New(settings, prompts.New(settings)).Signin(); err != nil {
+					httpclient.Fatal(err)
 				}
 				if err := config.CheckRequiredAssociation(true, true, settings); err != nil {
-					logrus.Fatal(err.Error())
+					httpclient.Fatal(err)
 				}
 				err := CmdShow(*serviceName, services.New(settings))
 				if err != nil {
-					logrus.Fatalln(err.Error())
+					httpclient.Fatal(err)
 				}
 			}
 			subCmd.Spec = "SERVICE_NAME"
@@ -1,10 +1,11 @@
 package invites
 
 import (
-	"github.com/Sirupsen/logrus"
 	"github.com/catalyzeio/cli/config"
 	"github.com/catalyzeio/cli/lib/auth"
+	"github.com/catalyzeio/cli/lib/httpclient"
 	"github.com/catalyzeio/cli/lib/prompts"
+	"github.com/catalyzeio/cli/lib/render"
 	"github.com/catalyzeio/cli/models"
 	"github.com/jault3/mow.cli"
 )
@@ -39,15 +40,15 @@ var AcceptSubCmd = models.Command{
 		return func(subCmd *cli.Cmd) {
 			inviteCode := subCmd.StringArg("INVITE_CODE", "", "The invite code that was sent in the invite email")
 			subCmd.Action = func() {
-				p := prompts.New()
+				p := prompts.New(settings)
 				a := auth.New(settings, p)
 				if _, err := a.Signin(); err != nil {
-					logrus.Fatal(err.Error())
+					httpclient.Fatal(err)
 				}
 
 				err := CmdAccept(*inviteCode, New(settings), a, p)
 				if err != nil {
-					logrus.Fatal(err.Error())
+					httpclient.Fatal(err)
 				}
 			}
 			subCmd.Spec = "INVITE_CODE"
@@ -66,15 +67,15 @@ var ListSubCmd = models.Command{
 	CmdFunc: func(settings *models.Settings) func(cmd *cli.Cmd) {
 		return func(subCmd *cli.Cmd) {
 			subCmd.Action = func() {
-				if _, err := auth.New(settings, prompts.New()).Signin(); err != nil {
-					logrus.Fatal(err.Error())
+				if _, err := auth.New(settings, prompts.New(settings)).Signin(); err != nil {
+					httpclient.Fatal(err)
 				}
 				if err := config.CheckRequiredAssociation(true, true, settings); err != nil {
-					logrus.Fatal(err.Error())
+					httpclient.Fatal(err)
 				}
-				err := CmdList(settings.EnvironmentName, New(settings))
+				err := CmdList(settings.EnvironmentName, New(settings), render.New(settings))
 				if err != nil {
-					logrus.Fatal(err.Error())
+					httpclient.Fatal(err)
 				}
 			}
 		}
@@ -94,15 +95,15 @@ var RmSubCmd = models.Command{
 		return func(subCmd *cli.Cmd) {
 			inviteID := subCmd.StringArg("INVITE_ID", "", "The ID of an invitation to remove")
 			subCmd.Action = func() {
-				if _, err := auth.New(settings, prompts.New()).Signin(); err != nil {
-					logrus.Fatal(err.Error())
+				if _, err := auth.New(settings, prompts.New(settings)).Signin(); err != nil {
+					httpclient.Fatal(err)
 				}
 				if err := config.CheckRequiredAssociation(true, true, settings); err != nil {
-					logrus.Fatal(err.Error())
+					httpclient.Fatal(err)
 				}
 				err := CmdRm(*inviteID, New(settings))
 				if err != nil {
-					logrus.Fatal(err.Error())
+					httpclient.Fatal(err)
 				}
 			}
 			subCmd.Spec = "INVITE_ID"
@@ -125,19 +126,19 @@ var SendSubCmd = models.Command{
 			subCmd.BoolOpt("m member", true, "Whether or not the user will be invited as a basic member")
 			adminRole := subCmd.BoolOpt("a admin", false, "Whether or not the user will be invited as an admin")
 			subCmd.Action = func() {
-				if _, err := auth.New(settings, prompts.New()).Signin(); err != nil {
-					logrus.Fatal(err.Error())
+				if _, err := auth.New(settings, prompts.New(settings)).Signin(); err != nil {
+					httpclient.Fatal(err)
 				}
 				if err := config.CheckRequiredAssociation(true, true, settings); err != nil {
-					logrus.Fatal(err.Error())
+					httpclient.Fatal(err)
 				}
 				role := "member"
 				if *adminRole {
 					role = "admin"
 				}
-				err := CmdSend(*email, role, settings.EnvironmentName, New(settings), prompts.New())
+				err := CmdSend(*email, role, settings.EnvironmentName, New(settings), prompts.New(settings))
 				if err != nil {
-					logrus.Fatal(err.Error())
+					httpclient.Fatal(err)
 				}
 			}
 			subCmd.Spec = "EMAIL [-m | -a]"
@@ -4,23 +4,29 @@ import (
 	"fmt"
 
 	"github.com/Sirupsen/logrus"
+	"github.com/catalyzeio/cli/lib/render"
 	"github.com/catalyzeio/cli/models"
 )
 
-func CmdList(envName string, ii IInvites) error {
+func CmdList(envName string, ii IInvites, ir render.IRender) error {
 	invts, err := ii.List()
 	if err != nil {
 		return err
 	}
-	if invts == nil || len(*invts) == 0 {
-		logrus.Printf("There are no pending invites for %s", envName)
-		return nil
-	}
-	logrus.Printf("Pending invites for %s:", envName)
-	for _, invite := range *invts {
-		logrus.Printf("\t%s %s", invite.Email, invite.ID)
+	if invts == nil {
+		invts = &[]models.Invite{}
 	}
-	return nil
+	return ir.Render(invts, func() error {
+		if len(*invts) == 0 {
+			logrus.Printf("There are no pending invites for %s", envName)
+			return nil
+		}
+		logrus.Printf("Pending invites for %s:", envName)
+		for _, invite := range *invts {
+			logrus.Printf("\t%s %s", invite.Email, invite.ID)
+		}
+		return nil
+	})
 }
 
 // List lists all pending invites for a given org.
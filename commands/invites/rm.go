@@ -4,10 +4,17 @@ import (
 	"fmt"
 
 	"github.com/Sirupsen/logrus"
+	"github.com/catalyzeio/cli/lib/httpclient"
 )
 
 func CmdRm(inviteID string, ii IInvites) error {
 	err := ii.Rm(inviteID)
+	if httpclient.IsNotFound(err) {
+		return httpclient.WithHint(err, fmt.Sprintf("Could not find an invite with the ID \"%s\". You can list pending invites with the \"catalyze invites list\" command.", inviteID))
+	}
+	if httpclient.IsForbidden(err) {
+		return httpclient.WithHint(err, "Only admins of your environment's organization can remove invites.")
+	}
 	if err != nil {
 		return err
 	}
@@ -6,6 +6,7 @@ import (
 	"strings"
 
 	"github.com/Sirupsen/logrus"
+	"github.com/catalyzeio/cli/lib/httpclient"
 	"github.com/catalyzeio/cli/lib/prompts"
 	"github.com/catalyzeio/cli/models"
 )
@@ -27,6 +28,9 @@ func CmdSend(email, envName, roleName string, ii IInvites, ip prompts.IPrompts)
 		}
 	}
 	err = ii.Send(email, role)
+	if httpclient.IsForbidden(err) {
+		return httpclient.WithHint(err, "Only admins of your environment's organization can invite users.")
+	}
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,31 @@
+package jobs
+
+import (
+	"fmt"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/catalyzeio/cli/commands/services"
+	libjobs "github.com/catalyzeio/cli/lib/jobs"
+	"github.com/catalyzeio/cli/lib/prompts"
+)
+
+// CmdCancel cancels a job that has not finished yet
+func CmdCancel(jobID, svcName string, ij libjobs.IJobs, is services.IServices, ip prompts.IPrompts) error {
+	job, service, err := findJob(jobID, svcName, ij, is)
+	if err != nil {
+		return err
+	}
+	if !isActive(job.Status) {
+		return fmt.Errorf("The %s job %s on %s is already %s and cannot be cancelled", job.Type, job.ID, service.Label, job.Status)
+	}
+	err = ip.YesNo(fmt.Sprintf("Are you sure you want to cancel the %s %s job %s on %s? (y/n) ", job.Status, job.Type, job.ID, service.Label))
+	if err != nil {
+		return err
+	}
+	logrus.Debugf("Deleting %s job (%s) on service %s", job.Type, job.ID, service.ID)
+	if err = ij.Delete(job.ID, service.ID); err != nil {
+		return err
+	}
+	logrus.Printf("Cancelled the %s job %s on %s", job.Type, job.ID, service.Label)
+	return nil
+}
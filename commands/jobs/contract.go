@@ -0,0 +1,150 @@
+package jobs
+
+import (
+	"github.com/catalyzeio/cli/commands/services"
+	"github.com/catalyzeio/cli/config"
+	"github.com/catalyzeio/cli/lib/auth"
+	"github.com/catalyzeio/cli/lib/httpclient"
+	libjobs "github.com/catalyzeio/cli/lib/jobs"
+	"github.com/catalyzeio/cli/lib/prompts"
+	"github.com/catalyzeio/cli/lib/render"
+	"github.com/catalyzeio/cli/models"
+	"github.com/jault3/mow.cli"
+)
+
+// Cmd is the contract between the user and the CLI. This specifies the command
+// name, arguments, and required/optional arguments and flags for the command.
+var Cmd = models.Command{
+	Name:      "jobs",
+	ShortHelp: "List, inspect, cancel, and view the logs of jobs",
+	LongHelp: "The `jobs` command gives direct access to the jobs run on your services such as builds, deploys, workers, backups, and restores. " +
+		"Every job has an ID which can be used to inspect the job, cancel it, or view its logs. " +
+		"The jobs command cannot be run directly but has sub commands.",
+	CmdFunc: func(settings *models.Settings) func(cmd *cli.Cmd) {
+		return func(cmd *cli.Cmd) {
+			cmd.CommandLong(CancelSubCmd.Name, CancelSubCmd.ShortHelp, CancelSubCmd.LongHelp, CancelSubCmd.CmdFunc(settings))
+			cmd.CommandLong(ListSubCmd.Name, ListSubCmd.ShortHelp, ListSubCmd.LongHelp, ListSubCmd.CmdFunc(settings))
+			cmd.CommandLong(LogsSubCmd.Name, LogsSubCmd.ShortHelp, LogsSubCmd.LongHelp, LogsSubCmd.CmdFunc(settings))
+			cmd.CommandLong(ShowSubCmd.Name, ShowSubCmd.ShortHelp, ShowSubCmd.LongHelp, ShowSubCmd.CmdFunc(settings))
+		}
+	},
+}
+
+var CancelSubCmd = models.Command{
+	Name:      "cancel",
+	ShortHelp: "Cancel a job that has not finished yet",
+	LongHelp: "`jobs cancel` stops a job that is scheduled, queued, or running. " +
+		"Jobs that have already finished or failed cannot be cancelled. " +
+		"If SERVICE_NAME is not given, every service in the environment is searched for the job. " +
+		"Here is a sample command\n\n" +
+		"```\ncatalyze -E \"<your_env_alias>\" jobs cancel 00000000-0000-0000-0000-000000000000\n```",
+	CmdFunc: func(settings *models.Settings) func(cmd *cli.Cmd) {
+		return func(subCmd *cli.Cmd) {
+			jobID := subCmd.StringArg("JOB_ID", "", "The ID of the job to cancel")
+			serviceName := subCmd.StringArg("SERVICE_NAME", "", "The name of the service the job belongs to")
+			subCmd.Action = func() {
+				if _, err := auth.New(settings, prompts.New(settings)).Signin(); err != nil {
+					httpclient.Fatal(err)
+				}
+				if err := config.CheckRequiredAssociation(true, true, settings); err != nil {
+					httpclient.Fatal(err)
+				}
+				err := CmdCancel(*jobID, *serviceName, libjobs.New(settings), services.New(settings), prompts.New(settings))
+				if err != nil {
+					httpclient.Fatal(err)
+				}
+			}
+			subCmd.Spec = "JOB_ID [SERVICE_NAME]"
+		}
+	},
+}
+
+var ListSubCmd = models.Command{
+	Name:      "list",
+	ShortHelp: "List the jobs of a service or of every service in the environment",
+	LongHelp: "`jobs list` lists jobs newest first along with their ID, service, type, status, and target. " +
+		"If SERVICE_NAME is not given, the jobs of every service in the environment are listed. " +
+		"The list can be narrowed down by job type, status, and worker target. " +
+		"Here are some sample commands\n\n" +
+		"```\ncatalyze -E \"<your_env_alias>\" jobs list\n" +
+		"catalyze -E \"<your_env_alias>\" jobs list code-1 --type worker --status running\n" +
+		"catalyze -E \"<your_env_alias>\" jobs list code-1 --target mailer\n```",
+	CmdFunc: func(settings *models.Settings) func(cmd *cli.Cmd) {
+		return func(subCmd *cli.Cmd) {
+			serviceName := subCmd.StringArg("SERVICE_NAME", "", "The name of the service to list jobs for")
+			jobType := subCmd.StringOpt("type", "", "Only list jobs of this type, such as build, deploy, worker, backup, or restore")
+			status := subCmd.StringOpt("status", "", "Only list jobs in this status, such as running, finished, or failed")
+			target := subCmd.StringOpt("target", "", "Only list jobs started for this Procfile target")
+			subCmd.Action = func() {
+				if _, err := auth.New(settings, prompts.New(settings)).Signin(); err != nil {
+					httpclient.Fatal(err)
+				}
+				if err := config.CheckRequiredAssociation(true, true, settings); err != nil {
+					httpclient.Fatal(err)
+				}
+				err := CmdList(*serviceName, *jobType, *status, *target, libjobs.New(settings), services.New(settings), render.New(settings))
+				if err != nil {
+					httpclient.Fatal(err)
+				}
+			}
+			subCmd.Spec = "[SERVICE_NAME] [--type] [--status] [--target]"
+		}
+	},
+}
+
+var LogsSubCmd = models.Command{
+	Name:      "logs",
+	ShortHelp: "Print the logs of a backup, restore, or import job",
+	LongHelp: "`jobs logs` downloads the logs of a backup, restore, or import job, decrypts them, and prints them to the console. " +
+		"Other jobs do not store their logs, so view them in your logging dashboard instead. " +
+		"If SERVICE_NAME is not given, every service in the environment is searched for the job. " +
+		"Here is a sample command\n\n" +
+		"```\ncatalyze -E \"<your_env_alias>\" jobs logs 00000000-0000-0000-0000-000000000000\n```",
+	CmdFunc: func(settings *models.Settings) func(cmd *cli.Cmd) {
+		return func(subCmd *cli.Cmd) {
+			jobID := subCmd.StringArg("JOB_ID", "", "The ID of the job to print the logs of")
+			serviceName := subCmd.StringArg("SERVICE_NAME", "", "The name of the service the job belongs to")
+			subCmd.Action = func() {
+				if _, err := auth.New(settings, prompts.New(settings)).Signin(); err != nil {
+					httpclient.Fatal(err)
+				}
+				if err := config.CheckRequiredAssociation(true, true, settings); err != nil {
+					httpclient.Fatal(err)
+				}
+				err := CmdLogs(*jobID, *serviceName, libjobs.New(settings), services.New(settings))
+				if err != nil {
+					httpclient.Fatal(err)
+				}
+			}
+			subCmd.Spec = "JOB_ID [SERVICE_NAME]"
+		}
+	},
+}
+
+var ShowSubCmd = models.Command{
+	Name:      "show",
+	ShortHelp: "Show the details of a job",
+	LongHelp: "`jobs show` prints the details of a job including its type, status, target, creation date, and the environment variables it was started with. " +
+		"If SERVICE_NAME is not given, every service in the environment is searched for the job. " +
+		"Here is a sample command\n\n" +
+		"```\ncatalyze -E \"<your_env_alias>\" jobs show 00000000-0000-0000-0000-000000000000\n```",
+	CmdFunc: func(settings *models.Settings) func(cmd *cli.Cmd) {
+		return func(subCmd *cli.Cmd) {
+			jobID := subCmd.StringArg("JOB_ID", "", "The ID of the job to show")
+			serviceName := subCmd.StringArg("SERVICE_NAME", "", "The name of the service the job belongs to")
+			subCmd.Action = func() {
+				if _, err := auth.New(settings, prompts.New(settings)).Signin(); err != nil {
+					httpclient.Fatal(err)
+				}
+				if err := config.CheckRequiredAssociation(true, true, settings); err != nil {
+					httpclient.Fatal(err)
+				}
+				err := CmdShow(*jobID, *serviceName, libjobs.New(settings), services.New(settings), render.New(settings))
+				if err != nil {
+					httpclient.Fatal(err)
+				}
+			}
+			subCmd.Spec = "JOB_ID [SERVICE_NAME]"
+		}
+	},
+}
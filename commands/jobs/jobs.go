@@ -0,0 +1,73 @@
+package jobs
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/catalyzeio/cli/commands/services"
+	"github.com/catalyzeio/cli/lib/httpclient"
+	libjobs "github.com/catalyzeio/cli/lib/jobs"
+	"github.com/catalyzeio/cli/models"
+)
+
+// activeStatuses are the statuses of jobs that have not finished yet
+var activeStatuses = []string{"scheduled", "queued", "started", "running", "waiting"}
+
+func isActive(status string) bool {
+	for _, s := range activeStatuses {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
+
+// targetServices returns the service with the given label, or every service
+// in the environment if no label is given
+func targetServices(svcName string, is services.IServices) ([]models.Service, error) {
+	if svcName != "" {
+		service, err := is.RetrieveByLabel(svcName)
+		if err != nil {
+			return nil, err
+		}
+		if service == nil {
+			return nil, fmt.Errorf("Could not find a service with the label \"%s\". You can list services with the \"catalyze services list\" command.", svcName)
+		}
+		return []models.Service{*service}, nil
+	}
+	svcs, err := is.List()
+	if err != nil {
+		return nil, err
+	}
+	return *svcs, nil
+}
+
+// findJob retrieves the job with the given ID, including its spec and
+// encryption keys, along with the service it belongs to. Jobs are only
+// addressable through their service so without a service name every service
+// in the environment is searched.
+func findJob(jobID, svcName string, ij libjobs.IJobs, is services.IServices) (*models.Job, *models.Service, error) {
+	svcs, err := targetServices(svcName, is)
+	if err != nil {
+		return nil, nil, err
+	}
+	hint := fmt.Sprintf("Could not find a job with the ID \"%s\". You can list jobs with the \"catalyze jobs list\" command.", jobID)
+	var notFoundErr error
+	for i := range svcs {
+		job, err := ij.Retrieve(jobID, svcs[i].ID, true)
+		if err != nil {
+			if !httpclient.IsNotFound(err) {
+				return nil, nil, err
+			}
+			logrus.Debugf("Job %s not found on %s: %s", jobID, svcs[i].Label, err)
+			notFoundErr = err
+			continue
+		}
+		return job, &svcs[i], nil
+	}
+	if notFoundErr != nil {
+		return nil, nil, httpclient.WithHint(notFoundErr, hint)
+	}
+	return nil, nil, errors.New(hint)
+}
@@ -0,0 +1,133 @@
+package jobs
+
+import (
+	"fmt"
+	"net/http"
+	"os/exec"
+	"regexp"
+	"testing"
+
+	"github.com/catalyzeio/cli/lib/httpclient"
+	"github.com/catalyzeio/cli/models"
+	"github.com/catalyzeio/cli/test"
+)
+
+// setUpJobs replaces the jobs of the code and database services with a known
+// set and returns the IDs of the running worker, the finished deploy, and the
+// finished backup
+func setUpJobs(t *testing.T) (string, string, string) {
+	test.Server.Jobs[test.CodeSvcID] = nil
+	test.Server.Jobs[test.DBSvcID] = nil
+	deploy := test.Server.AddJob(test.CodeSvcID, models.Job{Type: "deploy", Status: "finished", Spec: &models.Spec{Payload: &models.Payload{Environment: map[string]string{"RAILS_ENV": "production", "API_KEY": "secret"}}}})
+	worker := test.Server.AddJob(test.CodeSvcID, models.Job{Type: "worker", Status: "running", Target: "mailer"})
+	test.Server.AddJob(test.CodeSvcID, models.Job{Type: "worker", Status: "finished", Target: "cron"})
+	output, err := test.RunCommand(test.BinaryName, []string{"-E", test.Alias, "db", "backup", test.DBLabel})
+	if err != nil {
+		t.Fatalf("Failed to create a backup: %s", output)
+	}
+	backups := test.Server.Jobs[test.DBSvcID]
+	return worker.ID, deploy.ID, backups[len(backups)-1].ID
+}
+
+func TestJobs(t *testing.T) {
+	if err := test.SetUpGitRepo(); err != nil {
+		t.Fatal(err)
+	}
+	if err := test.SetUpAssociation(); err != nil {
+		t.Fatal(err)
+	}
+	workerID, deployID, backupID := setUpJobs(t)
+
+	var jobsTests = []struct {
+		args           []string
+		expectErr      bool
+		expectedOutput string
+	}{
+		{[]string{"list"}, false, `(?s)ID\s+SERVICE\s+TYPE\s+STATUS\s+TARGET\s+CREATED AT.*` + backupID + `\s+db01\s+backup\s+finished.*worker\s+finished\s+cron.*` + workerID + `\s+code-1\s+worker\s+running\s+mailer.*` + deployID + `\s+code-1\s+deploy\s+finished`},
+		{[]string{"list", test.SvcLabel, "--type", "worker", "--status", "running"}, false, `^\s*ID.*\n\s*` + workerID + `\s+code-1\s+worker\s+running\s+mailer\s+\S.*\n$`},
+		{[]string{"list", test.SvcLabel, "--target", "cron"}, false, `cron`},
+		{[]string{"list", test.SvcLabel, "--status", "failed"}, false, `No jobs found`},
+		{[]string{"list", "bad-service"}, true, `Could not find a service with the label "bad-service"`},
+		{[]string{"show", deployID}, false, `(?s)ID:\s+` + deployID + `.*Service:\s+code-1.*Type:\s+deploy.*Status:\s+finished.*Environment:.*API_KEY\s+secret.*RAILS_ENV\s+production`},
+		{[]string{"show", workerID, test.SvcLabel}, false, `(?s)Target:\s+mailer.*No environment variables were set for this job`},
+		{[]string{"show", "job-missing"}, true, `Could not find a job with the ID "job-missing"`},
+		{[]string{"logs", deployID}, true, `The deploy job \S+ does not store its logs.*logging dashboard`},
+		{[]string{"logs", backupID, test.DBLabel}, false, `(?s)Begin db01 logs.*backup of db01 finished.*End db01 logs`},
+		{[]string{"cancel", deployID}, true, `The deploy job \S+ on code-1 is already finished and cannot be cancelled`},
+		{[]string{"cancel", workerID}, false, `Cancelled the worker job \S+ on code-1`},
+		{[]string{"show", workerID}, true, `Could not find a job with the ID`},
+	}
+	for _, data := range jobsTests {
+		t.Logf("Data: %+v", data)
+		args := append([]string{"-y", "-E", test.Alias, "jobs"}, data.args...)
+		output, err := test.RunCommand(test.BinaryName, args)
+		if err != nil != data.expectErr {
+			t.Errorf("Unexpected error: %s", output)
+			continue
+		}
+		if !regexp.MustCompile(data.expectedOutput).MatchString(output) {
+			t.Errorf("Expected: %s. Found: %s", data.expectedOutput, output)
+		}
+	}
+}
+
+func TestJobsAPIErrors(t *testing.T) {
+	if err := test.SetUpGitRepo(); err != nil {
+		t.Fatal(err)
+	}
+	if err := test.SetUpAssociation(); err != nil {
+		t.Fatal(err)
+	}
+	setUpJobs(t)
+	servicesPath := fmt.Sprintf("/environments/%s/services", test.EnvID)
+	jobsPath := fmt.Sprintf("%s/%s/jobs", servicesPath, test.CodeSvcID)
+	defer test.Server.Override("GET", servicesPath, nil)
+	defer test.Server.Override("GET", jobsPath, nil)
+
+	var apiErrorTests = []struct {
+		args             []string
+		path             string
+		handler          http.HandlerFunc
+		expectedExitCode int
+		expectedOutput   string
+	}{
+		{[]string{"show", "job-missing", test.SvcLabel}, "", nil, httpclient.ExitCodeNotFound, `(?s)\(404\).*\nCould not find a job with the ID "job-missing"`},
+		{[]string{"list", test.SvcLabel}, jobsPath, func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(403)
+			w.Write([]byte(`{"title":"Forbidden","description":"You do not have access to this environment","code":403}`))
+		}, httpclient.ExitCodeForbidden, `(?s)\(403\) Forbidden: You do not have access to this environment\nYou do not have permission`},
+		{[]string{"list", test.SvcLabel}, servicesPath, func(w http.ResponseWriter, r *http.Request) {
+			// the session expires after the command signed in
+			test.Server.ExpireSessions()
+			test.Server.Override("GET", servicesPath, nil)
+			w.WriteHeader(401)
+		}, 0, `ID\s+SERVICE\s+TYPE`},
+		{[]string{"list", test.SvcLabel}, servicesPath, func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(401)
+		}, httpclient.ExitCodeUnauthorized, `(?s)\(401\)\nYour session is no longer valid`},
+	}
+	for _, data := range apiErrorTests {
+		t.Logf("Data: %+v", data)
+		if data.path != "" {
+			test.Server.Override("GET", data.path, data.handler)
+		}
+		args := append([]string{"-E", test.Alias, "jobs"}, data.args...)
+		output, err := test.RunCommand(test.BinaryName, args)
+		if data.path != "" {
+			test.Server.Override("GET", data.path, nil)
+		}
+		exitCode := 0
+		if exitErr, ok := err.(*exec.ExitError); ok {
+			exitCode = exitErr.ExitCode()
+		} else if err != nil {
+			t.Errorf("Unexpected error: %s", err)
+			continue
+		}
+		if exitCode != data.expectedExitCode {
+			t.Errorf("Expected exit code %d. Found: %d %s", data.expectedExitCode, exitCode, output)
+		}
+		if !regexp.MustCompile(data.expectedOutput).MatchString(output) {
+			t.Errorf("Expected: %s. Found: %s", data.expectedOutput, output)
+		}
+	}
+}
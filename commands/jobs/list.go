@@ -0,0 +1,95 @@
+package jobs
+
+import (
+	"sort"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/catalyzeio/cli/commands/services"
+	libjobs "github.com/catalyzeio/cli/lib/jobs"
+	"github.com/catalyzeio/cli/lib/render"
+	"github.com/catalyzeio/cli/models"
+	"github.com/olekukonko/tablewriter"
+)
+
+const listPageSize = 100
+
+// CmdList lists the jobs of the given service, or of every service if no
+// service is given, newest first. Jobs are filtered by type on the server and
+// by status and target locally since the API can not combine those filters
+// with paging.
+func CmdList(svcName, jobType, status, target string, ij libjobs.IJobs, is services.IServices, ir render.IRender) error {
+	svcs, err := targetServices(svcName, is)
+	if err != nil {
+		return err
+	}
+	serviceJobs := []models.ServiceJob{}
+	for _, svc := range svcs {
+		jobs, err := listServiceJobs(svc.ID, jobType, ij)
+		if err != nil {
+			return err
+		}
+		for _, j := range jobs {
+			if (status == "" || j.Status == status) && (target == "" || j.Target == target) {
+				serviceJobs = append(serviceJobs, models.ServiceJob{ServiceID: svc.ID, ServiceLabel: svc.Label, Job: j})
+			}
+		}
+	}
+	sort.SliceStable(serviceJobs, func(i, j int) bool {
+		return serviceJobs[i].Job.CreatedAt > serviceJobs[j].Job.CreatedAt
+	})
+
+	return ir.Render(serviceJobs, func() error {
+		if len(serviceJobs) == 0 {
+			logrus.Println("No jobs found")
+			return nil
+		}
+		data := [][]string{{"ID", "SERVICE", "TYPE", "STATUS", "TARGET", "CREATED AT"}}
+		for _, sj := range serviceJobs {
+			data = append(data, []string{sj.Job.ID, sj.ServiceLabel, sj.Job.Type, sj.Job.Status, sj.Job.Target, formatCreatedAt(sj.Job.CreatedAt)})
+		}
+
+		table := tablewriter.NewWriter(logrus.StandardLogger().Out)
+		table.SetBorder(false)
+		table.SetRowLine(false)
+		table.SetCenterSeparator("")
+		table.SetColumnSeparator("")
+		table.SetRowSeparator("")
+		table.AppendBulk(data)
+		table.Render()
+		return nil
+	})
+}
+
+// listServiceJobs retrieves every job of a service, optionally of a single
+// type, one page at a time
+func listServiceJobs(svcID, jobType string, ij libjobs.IJobs) ([]models.Job, error) {
+	all := []models.Job{}
+	for page := 1; ; page++ {
+		var jobs *[]models.Job
+		var err error
+		if jobType != "" {
+			jobs, err = ij.RetrieveByType(svcID, jobType, page, listPageSize)
+		} else {
+			jobs, err = ij.List(svcID, page, listPageSize)
+		}
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, *jobs...)
+		if len(*jobs) < listPageSize {
+			return all, nil
+		}
+	}
+}
+
+// formatCreatedAt formats the creation date of a job in local time, leaving
+// dates that can not be parsed untouched
+func formatCreatedAt(createdAt string) string {
+	const dateForm = "2006-01-02T15:04:05"
+	t, err := time.Parse(dateForm, createdAt)
+	if err != nil {
+		return createdAt
+	}
+	return t.Local().Format(time.Stamp)
+}
@@ -0,0 +1,22 @@
+package jobs
+
+import (
+	"github.com/Sirupsen/logrus"
+	"github.com/catalyzeio/cli/commands/services"
+	libjobs "github.com/catalyzeio/cli/lib/jobs"
+)
+
+// CmdLogs prints the logs of a job of any type
+func CmdLogs(jobID, svcName string, ij libjobs.IJobs, is services.IServices) error {
+	job, service, err := findJob(jobID, svcName, ij, is)
+	if err != nil {
+		return err
+	}
+	logrus.Printf("Retrieving %s logs for %s job %s...", service.Label, job.Type, job.ID)
+	logrus.Printf("-------------------------- Begin %s logs --------------------------", service.Label)
+	if err = ij.Logs(job, service.ID, logrus.StandardLogger().Out); err != nil {
+		return err
+	}
+	logrus.Printf("--------------------------- End %s logs ---------------------------", service.Label)
+	return nil
+}
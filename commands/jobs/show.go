@@ -0,0 +1,61 @@
+package jobs
+
+import (
+	"sort"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/catalyzeio/cli/commands/services"
+	libjobs "github.com/catalyzeio/cli/lib/jobs"
+	"github.com/catalyzeio/cli/lib/render"
+	"github.com/catalyzeio/cli/models"
+	"github.com/olekukonko/tablewriter"
+)
+
+// CmdShow prints the details of a job including the environment variables
+// in its spec payload
+func CmdShow(jobID, svcName string, ij libjobs.IJobs, is services.IServices, ir render.IRender) error {
+	job, service, err := findJob(jobID, svcName, ij, is)
+	if err != nil {
+		return err
+	}
+	// the encryption keys of backups and restores are never printed
+	job.Backup = nil
+	job.Restore = nil
+	serviceJob := models.ServiceJob{ServiceID: service.ID, ServiceLabel: service.Label, Job: *job}
+
+	return ir.Render(serviceJob, func() error {
+		logrus.Printf("ID:         %s", job.ID)
+		logrus.Printf("Service:    %s", service.Label)
+		logrus.Printf("Type:       %s", job.Type)
+		logrus.Printf("Status:     %s", job.Status)
+		if job.Target != "" {
+			logrus.Printf("Target:     %s", job.Target)
+		}
+		logrus.Printf("Created At: %s", formatCreatedAt(job.CreatedAt))
+		if job.Spec == nil || job.Spec.Payload == nil || len(job.Spec.Payload.Environment) == 0 {
+			logrus.Println("\nNo environment variables were set for this job")
+			return nil
+		}
+		names := []string{}
+		for name := range job.Spec.Payload.Environment {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		data := [][]string{{"NAME", "VALUE"}}
+		for _, name := range names {
+			data = append(data, []string{name, job.Spec.Payload.Environment[name]})
+		}
+
+		logrus.Println("\nEnvironment:")
+		table := tablewriter.NewWriter(logrus.StandardLogger().Out)
+		table.SetBorder(false)
+		table.SetRowLine(false)
+		table.SetCenterSeparator("")
+		table.SetColumnSeparator("")
+		table.SetRowSeparator("")
+		table.SetAutoWrapText(false)
+		table.AppendBulk(data)
+		table.Render()
+		return nil
+	})
+}
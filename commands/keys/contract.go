@@ -1,9 +1,9 @@
 package keys
 
 import (
-	"github.com/Sirupsen/logrus"
 	"github.com/catalyzeio/cli/commands/deploykeys"
 	"github.com/catalyzeio/cli/lib/auth"
+	"github.com/catalyzeio/cli/lib/httpclient"
 	"github.com/catalyzeio/cli/lib/prompts"
 	"github.com/catalyzeio/cli/models"
 	"github.com/jault3/mow.cli"
@@ -42,12 +42,12 @@ var AddSubCmd = models.Command{
 			name := cmd.StringArg("NAME", "", "The name for the new key, for your own purposes")
 			path := cmd.StringArg("PUBLIC_KEY_PATH", "", "Relative path to the public key file")
 			cmd.Action = func() {
-				if _, err := auth.New(settings, prompts.New()).Signin(); err != nil {
-					logrus.Fatal(err.Error())
+				if _, err := auth.New(settings, prompts.New(settings)).Signin(); err != nil {
+					httpclient.Fatal(err)
 				}
 				err := CmdAdd(*name, *path, New(settings), deploykeys.New(settings))
 				if err != nil {
-					logrus.Fatal(err)
+					httpclient.Fatal(err)
 				}
 			}
 		}
@@ -63,12 +63,12 @@ var ListSubCmd = models.Command{
 	CmdFunc: func(settings *models.Settings) func(cmd *cli.Cmd) {
 		return func(cmd *cli.Cmd) {
 			cmd.Action = func() {
-				if _, err := auth.New(settings, prompts.New()).Signin(); err != nil {
-					logrus.Fatal(err.Error())
+				if _, err := auth.New(settings, prompts.New(settings)).Signin(); err != nil {
+					httpclient.Fatal(err)
 				}
 				err := CmdList(New(settings), deploykeys.New(settings))
 				if err != nil {
-					logrus.Fatal(err)
+					httpclient.Fatal(err)
 				}
 			}
 		}
@@ -85,12 +85,12 @@ var RemoveSubCmd = models.Command{
 		return func(cmd *cli.Cmd) {
 			name := cmd.StringArg("NAME", "", "The name of the key to remove.")
 			cmd.Action = func() {
-				if _, err := auth.New(settings, prompts.New()).Signin(); err != nil {
-					logrus.Fatal(err.Error())
+				if _, err := auth.New(settings, prompts.New(settings)).Signin(); err != nil {
+					httpclient.Fatal(err)
 				}
 				err := CmdRemove(*name, settings.PrivateKeyPath, New(settings), deploykeys.New(settings))
 				if err != nil {
-					logrus.Fatal(err)
+					httpclient.Fatal(err)
 				}
 			}
 		}
@@ -112,7 +112,7 @@ var SetSubCmd = models.Command{
 			cmd.Action = func() {
 				err := CmdSet(*path, settings)
 				if err != nil {
-					logrus.Fatal(err)
+					httpclient.Fatal(err)
 				}
 			}
 		}
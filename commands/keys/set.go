@@ -36,7 +36,7 @@ func CmdSet(path string, settings *models.Settings) error {
 
 	settings.PrivateKeyPath = fullPath
 	settings.SessionToken = ""
-	a := auth.New(settings, prompts.New())
+	a := auth.New(settings, prompts.New(settings))
 	user, err := a.Signin()
 	if err != nil {
 		return err
@@ -0,0 +1,210 @@
+package logs
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"regexp"
+	"runtime"
+	"time"
+)
+
+// ExitCodeMatched is the exit code of `logs --until-match` when the alert
+// fires
+const ExitCodeMatched = 7
+
+// errMatched is returned once the alert fires when logs was run with
+// --until-match
+var errMatched = errors.New("The alert fired")
+
+// webhookClient posts alerts to the --webhook. The webhook is not a Catalyze
+// API so the HTTPManager, which handles upgrades, redirects, and errors for
+// the API, is not used.
+var webhookClient = &http.Client{Timeout: 30 * time.Second}
+
+// Alert watches the printed log lines for messages matching a pattern and
+// fires when Threshold of them were written within Window of each other.
+// Firing runs Exec with the matching log lines on stdin and posts them to
+// Webhook.
+type Alert struct {
+	Pattern   *regexp.Regexp
+	Threshold int
+	Window    time.Duration
+	// Exec is a shell command run when the alert fires
+	Exec string
+	// Webhook is a URL that a JSON alertPayload is posted to when the alert
+	// fires
+	Webhook string
+	// UntilMatch stops the logs once the alert fires
+	UntilMatch bool
+
+	ctx     context.Context
+	matches []alertMatch
+	latest  time.Time
+}
+
+type alertMatch struct {
+	at   time.Time
+	line logLine
+}
+
+// alertPayload is the body posted to the webhook when the alert fires
+type alertPayload struct {
+	Pattern       string            `json:"pattern"`
+	Threshold     int               `json:"threshold"`
+	WindowSeconds int               `json:"windowSeconds"`
+	FiredAt       string            `json:"firedAt"`
+	Lines         []json.RawMessage `json:"lines"`
+}
+
+// newAlert validates the alert flags. No alert is returned if no pattern was
+// given. The hooks are stopped when ctx is done.
+func newAlert(pattern string, threshold, window int, execCmd, webhook string, untilMatch bool, ctx context.Context) (*Alert, error) {
+	if pattern == "" {
+		if execCmd != "" || webhook != "" || untilMatch {
+			return nil, errors.New("--exec, --webhook, and --until-match can only be used with --alert")
+		}
+		return nil, nil
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("Invalid --alert pattern \"%s\": %s", pattern, err)
+	}
+	if threshold < 1 {
+		return nil, errors.New("--threshold must be at least 1")
+	}
+	if window < 1 {
+		return nil, errors.New("--window must be at least 1 second")
+	}
+	if webhook != "" {
+		u, err := url.Parse(webhook)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return nil, fmt.Errorf("Invalid --webhook URL \"%s\". The URL must start with http:// or https://", webhook)
+		}
+	}
+	return &Alert{
+		Pattern:    re,
+		Threshold:  threshold,
+		Window:     time.Duration(window) * time.Second,
+		Exec:       execCmd,
+		Webhook:    webhook,
+		UntilMatch: untilMatch,
+		ctx:        ctx,
+	}, nil
+}
+
+// observe checks a printed log line against the pattern and fires the alert
+// once enough matching log lines were written within the window. The window
+// is measured with the timestamps of the log lines so that older logs
+// retrieved with --hours or --since are alerted on the same way as new ones.
+func (a *Alert) observe(p *Printer, line logLine) error {
+	if !a.Pattern.MatchString(line.get(messageField)) {
+		return nil
+	}
+	at, err := time.Parse(time.RFC3339Nano, line.get(timestampField))
+	if err != nil {
+		at = time.Now()
+	}
+	if at.After(a.latest) {
+		a.latest = at
+	}
+	a.matches = append(a.matches, alertMatch{at: at, line: line})
+	kept := a.matches[:0]
+	for _, m := range a.matches {
+		if a.latest.Sub(m.at) < a.Window {
+			kept = append(kept, m)
+		}
+	}
+	a.matches = kept
+	if len(a.matches) < a.Threshold {
+		return nil
+	}
+	matches := a.matches
+	a.matches = nil
+	a.fire(p, matches)
+	if a.UntilMatch {
+		return errMatched
+	}
+	return nil
+}
+
+// fire reports the alert on stderr and runs the hooks. Hooks that fail are
+// reported but do not stop the logs.
+func (a *Alert) fire(p *Printer, matches []alertMatch) {
+	fmt.Fprintf(events, "Alert: %d log lines matched %s within %s\n", len(matches), a.Pattern, a.Window)
+	if a.Exec != "" {
+		if err := a.runExec(p, matches); err != nil {
+			fmt.Fprintf(events, "The --exec command failed: %s\n", err)
+		}
+	}
+	if a.Webhook != "" {
+		if err := a.postWebhook(p, matches); err != nil {
+			fmt.Fprintf(events, "Could not post the alert to the --webhook: %s\n", err)
+		}
+	}
+}
+
+// runExec runs the --exec command with the matching log lines on stdin, in the
+// same format they were printed in. The command's output goes to stderr so
+// that it is kept apart from the logs.
+func (a *Alert) runExec(p *Printer, matches []alertMatch) error {
+	stdin := &bytes.Buffer{}
+	for _, m := range matches {
+		b, err := p.format(m.line)
+		if err != nil {
+			return err
+		}
+		stdin.Write(b)
+		stdin.WriteString("\n")
+	}
+	cmd := exec.CommandContext(a.ctx, "sh", "-c", a.Exec)
+	if runtime.GOOS == "windows" {
+		cmd = exec.CommandContext(a.ctx, "cmd", "/C", a.Exec)
+	}
+	cmd.Stdin = stdin
+	cmd.Stdout = os.Stderr
+	cmd.Stderr = os.Stderr
+	return cmd.Run()
+}
+
+// postWebhook posts the matching log lines to the webhook as JSON objects with
+// the printed fields
+func (a *Alert) postWebhook(p *Printer, matches []alertMatch) error {
+	payload := alertPayload{
+		Pattern:       a.Pattern.String(),
+		Threshold:     a.Threshold,
+		WindowSeconds: int(a.Window / time.Second),
+		FiredAt:       time.Now().UTC().Format(time.RFC3339),
+	}
+	for _, m := range matches {
+		b, err := p.formatJSON(m.line)
+		if err != nil {
+			return err
+		}
+		payload.Lines = append(payload.Lines, b)
+	}
+	b, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest("POST", a.Webhook, bytes.NewReader(b))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := webhookClient.Do(req.WithContext(a.ctx))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("The webhook responded with %s", resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,212 @@
+package logs
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/catalyzeio/cli/lib/httpclient"
+	"github.com/catalyzeio/cli/models"
+)
+
+func alertLine(seconds int, message string) logLine {
+	timestamp := time.Date(2024, 3, 1, 0, 0, seconds, 0, time.UTC).Format("2006-01-02T15:04:05.000Z07:00")
+	return logLine{
+		timestampField: json.RawMessage(fmt.Sprintf("%q", timestamp)),
+		messageField:   json.RawMessage(fmt.Sprintf("%q", message)),
+	}
+}
+
+var observeTests = []struct {
+	threshold     int
+	window        int
+	seconds       []int
+	expectedFires int
+}{
+	{1, 60, []int{0, 1, 2}, 3},
+	{3, 60, []int{0, 10, 20}, 1},
+	{3, 60, []int{0, 10, 60, 70}, 0},
+	{3, 60, []int{0, 10, 20, 30, 40, 50}, 2},
+	{2, 5, []int{0, 4, 100, 200, 204}, 2},
+	// log lines from different hosts can arrive slightly out of order
+	{3, 10, []int{5, 3, 4}, 1},
+}
+
+func TestObserve(t *testing.T) {
+	defer func() {
+		events = os.Stderr
+	}()
+	for _, data := range observeTests {
+		t.Logf("Data: %+v", data)
+		stderr := &bytes.Buffer{}
+		events = stderr
+		a, err := newAlert("(?i)panic", data.threshold, data.window, "", "", false, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		p := &Printer{Fields: defaultFields(false, ""), Out: ioutil.Discard, Alert: a}
+		for _, s := range data.seconds {
+			if err = p.print(alertLine(s, "PANIC: out of memory")); err != nil {
+				t.Errorf("Unexpected error: %s", err)
+			}
+			if err = p.print(alertLine(s, "GET /health")); err != nil {
+				t.Errorf("Unexpected error: %s", err)
+			}
+		}
+		if fires := strings.Count(stderr.String(), "Alert: "); fires != data.expectedFires {
+			t.Errorf("Expected %d alerts. Found: %d\n%s", data.expectedFires, fires, stderr.String())
+		}
+	}
+}
+
+var newAlertTests = []struct {
+	pattern    string
+	threshold  int
+	window     int
+	execCmd    string
+	webhook    string
+	untilMatch bool
+	expectErr  bool
+}{
+	{"", 1, 60, "", "", false, false},
+	{"", 1, 60, "notify.sh", "", false, true},
+	{"", 1, 60, "", "", true, true},
+	{"error(", 1, 60, "", "", false, true},
+	{"error", 0, 60, "", "", false, true},
+	{"error", 1, 0, "", "", false, true},
+	{"error", 5, 60, "", "hooks.example.com/alert", false, true},
+	{"error", 5, 60, "notify.sh", "https://hooks.example.com/alert", true, false},
+}
+
+func TestNewAlert(t *testing.T) {
+	for _, data := range newAlertTests {
+		t.Logf("Data: %+v", data)
+		a, err := newAlert(data.pattern, data.threshold, data.window, data.execCmd, data.webhook, data.untilMatch, nil)
+		if err != nil != data.expectErr {
+			t.Errorf("Unexpected error: %v", err)
+			continue
+		}
+		if err == nil && (a == nil) != (data.pattern == "") {
+			t.Errorf("Expected an alert only when a pattern is given. Found: %+v", a)
+		}
+	}
+}
+
+func TestAlertHooks(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("The --exec command in this test uses sh")
+	}
+	stderr := &bytes.Buffer{}
+	events = stderr
+	defer func() {
+		events = os.Stderr
+	}()
+	dir, err := ioutil.TempDir("", "alert")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	stdin := filepath.Join(dir, "stdin")
+
+	var payload alertPayload
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" || r.Header.Get("Content-Type") != "application/json" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		json.NewDecoder(r.Body).Decode(&payload)
+	}))
+	defer ts.Close()
+
+	a, err := newAlert("timeout", 2, 60, "cat > "+stdin, ts.URL, true, context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := &Printer{Fields: []string{timestampField, messageField}, JSON: true, Out: ioutil.Discard, Alert: a}
+	if err = p.print(alertLine(0, "upstream timeout")); err != nil {
+		t.Fatalf("Unexpected error before the alert fired: %s", err)
+	}
+	if err = p.print(alertLine(1, "healthy")); err != nil {
+		t.Fatalf("Unexpected error before the alert fired: %s", err)
+	}
+	if err = p.print(alertLine(2, "db timeout")); err != errMatched {
+		t.Fatalf("Expected the alert to fire and stop the logs. Found: %v", err)
+	}
+
+	expected := `{"@timestamp":"2024-03-01T00:00:00.000Z","message":"upstream timeout"}` + "\n" +
+		`{"@timestamp":"2024-03-01T00:00:02.000Z","message":"db timeout"}` + "\n"
+	if b, err := ioutil.ReadFile(stdin); err != nil || string(b) != expected {
+		t.Errorf("Expected the matching log lines on stdin of --exec: %s. Found: %s %v", expected, string(b), err)
+	}
+	if payload.Pattern != "timeout" || payload.Threshold != 2 || payload.WindowSeconds != 60 || len(payload.Lines) != 2 {
+		t.Errorf("Unexpected webhook payload: %+v", payload)
+	} else if string(payload.Lines[1]) != `{"@timestamp":"2024-03-01T00:00:02.000Z","message":"db timeout"}` {
+		t.Errorf("Unexpected log line in the webhook payload: %s", payload.Lines[1])
+	}
+	if !strings.Contains(stderr.String(), "Alert: 2 log lines matched timeout within 1m0s") {
+		t.Errorf("Expected the alert to be reported on stderr. Found: %s", stderr.String())
+	}
+}
+
+func TestOutputUntilMatch(t *testing.T) {
+	events = ioutil.Discard
+	defer func() {
+		events = os.Stderr
+	}()
+	start := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	es := &fakeElasticsearch{}
+	for i := 0; i < 10; i++ {
+		es.add(start.Add(time.Duration(i)*time.Second), 1)
+	}
+	ts := httptest.NewTLSServer(es)
+	defer ts.Close()
+
+	a, err := newAlert("log-000[357]", 2, 5, "", "", true, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := &bytes.Buffer{}
+	p := &Printer{Fields: defaultFields(false, ""), Out: out, Alert: a}
+	l := New(&models.Settings{HTTPManager: httpclient.NewTLSHTTPManager(true, nil)})
+	err = l.Output(&Query{Text: "*", Since: start.Add(-time.Second)}, "token", strings.TrimPrefix(ts.URL, "https://"), 3, &Cursor{}, start.Add(time.Hour), p)
+	if err != errMatched {
+		t.Fatalf("Expected the alert to stop the logs. Found: %v", err)
+	}
+	if lines := strings.Count(out.String(), "\n"); lines != 6 || !strings.HasSuffix(out.String(), "message log-0005\n") {
+		t.Errorf("Expected the logs to stop at the log line that fired the alert. Found:\n%s", out.String())
+	}
+}
+
+func TestWebhookFailure(t *testing.T) {
+	stderr := &bytes.Buffer{}
+	events = stderr
+	defer func() {
+		events = os.Stderr
+	}()
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusPreconditionFailed)
+	}))
+	defer ts.Close()
+
+	a, err := newAlert("timeout", 1, 60, "", ts.URL, false, context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := &Printer{Fields: defaultFields(false, ""), Out: ioutil.Discard, Alert: a}
+	if err = p.print(alertLine(0, "upstream timeout")); err != nil {
+		t.Fatalf("Expected a failed webhook not to stop the logs. Found: %s", err)
+	}
+	if !strings.Contains(stderr.String(), "Could not post the alert to the --webhook: The webhook responded with 412 Precondition Failed") {
+		t.Errorf("Expected the failed webhook to be reported on stderr. Found: %s", stderr.String())
+	}
+}
@@ -0,0 +1,92 @@
+package logs
+
+import (
+	"compress/gzip"
+	"fmt"
+	"os"
+	"sync"
+)
+
+// defaultRotateSize is the number of megabytes of log lines written to each
+// --out file before the next one is started
+const defaultRotateSize = 100
+
+// archive writes log lines to numbered gzip files, starting the next file
+// once the current one holds maxSize bytes of uncompressed log lines. Files
+// are named after the prefix, such as incident.log.0001.gz, and existing files
+// are never overwritten.
+type archive struct {
+	mu      sync.Mutex
+	prefix  string
+	maxSize int64
+	file    *os.File
+	gz      *gzip.Writer
+	written int64
+	// Files are the names of every file written to so far
+	Files []string
+	// Lines is the number of log lines written so far
+	Lines int
+}
+
+func newArchive(prefix string, maxSize int64) *archive {
+	return &archive{prefix: prefix, maxSize: maxSize}
+}
+
+// Write writes a log line to the current file. A log line is never split
+// across files.
+func (a *archive) Write(p []byte) (int, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.gz == nil || (a.written > 0 && a.written+int64(len(p)) > a.maxSize) {
+		if err := a.rotate(); err != nil {
+			return 0, err
+		}
+	}
+	n, err := a.gz.Write(p)
+	a.written += int64(n)
+	if err == nil {
+		a.Lines++
+	}
+	return n, err
+}
+
+// rotate closes the current file and starts the next one
+func (a *archive) rotate() error {
+	if err := a.close(); err != nil {
+		return err
+	}
+	name := fmt.Sprintf("%s.%04d.gz", a.prefix, len(a.Files)+1)
+	file, err := os.OpenFile(name, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		if os.IsExist(err) {
+			return fmt.Errorf("The file %s already exists. Please give a different --out or move the existing files", name)
+		}
+		return err
+	}
+	a.file = file
+	a.gz = gzip.NewWriter(file)
+	a.written = 0
+	a.Files = append(a.Files, name)
+	return nil
+}
+
+// Close finishes the current file so that it is a complete gzip file. It is
+// safe to call Close more than once, such as when the command is interrupted.
+func (a *archive) Close() error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.close()
+}
+
+func (a *archive) close() error {
+	if a.gz == nil {
+		return nil
+	}
+	err := a.gz.Close()
+	if closeErr := a.file.Close(); err == nil {
+		err = closeErr
+	}
+	a.gz = nil
+	a.file = nil
+	return err
+}
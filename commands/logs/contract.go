@@ -1,14 +1,16 @@
 package logs
 
 import (
+	"fmt"
+	"os"
 	"time"
 
-	"github.com/Sirupsen/logrus"
 	"github.com/catalyzeio/cli/commands/environments"
 	"github.com/catalyzeio/cli/commands/services"
 	"github.com/catalyzeio/cli/commands/sites"
 	"github.com/catalyzeio/cli/config"
 	"github.com/catalyzeio/cli/lib/auth"
+	"github.com/catalyzeio/cli/lib/httpclient"
 	"github.com/catalyzeio/cli/lib/prompts"
 	"github.com/catalyzeio/cli/models"
 	"github.com/jault3/mow.cli"
@@ -23,39 +25,78 @@ var Cmd = models.Command{
 		"If you do not see your logs, try adjusting the number of hours, minutes, or seconds of logs that are retrieved with the `--hours`, `--minutes`, and `--seconds` options respectively. " +
 		"You can also follow the logs with the `-f` option. " +
 		"When using `-f` all logs will be printed to the console within the given time frame as well as any new logs that are sent to the logging Dashboard for the duration of the command. " +
-		"When using the `-f` option, hit ctrl-c to stop. Here are some sample commands\n\n" +
+		"When using the `-f` option, hit ctrl-c to stop. " +
+		"Instead of a number of hours, minutes, and seconds before now, an absolute time range can be given in the RFC3339 format with `--since` and `--until`. " +
+		"The `QUERY` uses the Lucene query string syntax and is matched against the message of each log line, so `error AND NOT timeout`, `\"connection refused\"`, and `status:500` all work. " +
+		"Logs can also be narrowed down to a log `--source`, which defaults to your application logs, and to any of the given `--host` and `--service` names. " +
+		"If the connection to the log stream drops while following, it is reconnected to and the logs written while disconnected are printed before streaming resumes. Reconnects are reported on stderr. " +
+		"When following logs with a query or filters, new logs are polled for instead of streamed. " +
+		"Logs are retrieved in pages of `--page-size` log lines, up to 10000 at a time, and every log line in the time range is printed exactly once. " +
+		"Choose the fields printed for each log line with `--fields`, and use `--json` to print each log line as a JSON object with those fields, which include the source of the log line by default. " +
+		"To archive logs, `--out` writes them to gzip files named after the given path, such as `incident.log.0001.gz`, starting a new file after every `--rotate-size` megabytes of logs. " +
+		"To watch for problems, `--alert` takes a regular expression and fires when it matches the message of `--threshold` log lines written within `--window` seconds. " +
+		"When the alert fires it is reported on stderr, the `--exec` shell command is run with the matching log lines on stdin, and the matching log lines are posted as JSON to the `--webhook` URL. " +
+		"With `--until-match`, the command stops once the alert fires and exits with code 7. Here are some sample commands\n\n" +
 		"```\ncatalyze -E \"<your_env_alias>\" logs --hours=6 --minutes=30\n" +
+		"catalyze -E \"<your_env_alias>\" logs \"error AND NOT timeout\" --since 2024-03-01T00:00:00Z --until 2024-03-02T00:00:00Z\n" +
+		"catalyze -E \"<your_env_alias>\" logs --service code-1 --host worker01\n" +
+		"catalyze -E \"<your_env_alias>\" logs --hours 24 --json --fields @timestamp,host,service,message --out incident.log\n" +
+		"catalyze -E \"<your_env_alias>\" logs -f --alert \"(?i)panic|out of memory\" --threshold 5 --window 60 --exec ./page-oncall.sh --until-match\n" +
 		"catalyze -E \"<your_env_alias>\" logs -f\n```",
 	CmdFunc: func(settings *models.Settings) func(cmd *cli.Cmd) {
 		return func(cmd *cli.Cmd) {
-			query := cmd.StringArg("QUERY", "*", "The query to send to your logging dashboard's elastic search (regex is supported)")
+			query := cmd.StringArg("QUERY", "*", "The Lucene query string to search the messages of your logs for")
 			follow := cmd.BoolOpt("f follow", false, "Tail/follow the logs (Equivalent to -t)")
 			tail := cmd.BoolOpt("t tail", false, "Tail/follow the logs (Equivalent to -f)")
 			hours := cmd.IntOpt("hours", 0, "The number of hours before now (in combination with minutes and seconds) to retrieve logs")
 			mins := cmd.IntOpt("minutes", 0, "The number of minutes before now (in combination with hours and seconds) to retrieve logs")
 			secs := cmd.IntOpt("seconds", 0, "The number of seconds before now (in combination with hours and minutes) to retrieve logs")
+			since := cmd.StringOpt("since", "", "The RFC3339 time to retrieve logs from, such as 2006-01-02T15:04:05Z")
+			until := cmd.StringOpt("until", "", "The RFC3339 time to retrieve logs until, such as 2006-01-02T15:04:05Z")
+			source := cmd.StringOpt("source", "", "The source of the logs to retrieve. Defaults to your application logs")
+			hosts := cmd.StringsOpt("host", nil, "Only retrieve logs from this host. May be given more than once")
+			svcs := cmd.StringsOpt("service", nil, "Only retrieve logs from this service. May be given more than once")
+			pageSize := cmd.IntOpt("page-size", defaultPageSize, "The number of log lines to retrieve per request")
+			fields := cmd.StringOpt("fields", "", "A comma separated list of the fields of each log line to print, such as @timestamp,host,message")
+			jsonOutput := cmd.BoolOpt("json", false, "Print each log line as a JSON object on its own line")
+			out := cmd.StringOpt("out", "", "Write the logs to gzip files starting with this path instead of printing them")
+			alertPattern := cmd.StringOpt("alert", "", "A regular expression to alert on when it matches the message of --threshold log lines within --window seconds")
+			threshold := cmd.IntOpt("threshold", 1, "The number of log lines matching --alert within --window seconds that fires the alert")
+			window := cmd.IntOpt("window", 60, "The number of seconds the --threshold log lines matching --alert must be written within")
+			execCmd := cmd.StringOpt("exec", "", "A shell command to run with the matching log lines on stdin when the alert fires")
+			webhook := cmd.StringOpt("webhook", "", "A URL to POST the matching log lines to as JSON when the alert fires")
+			untilMatch := cmd.BoolOpt("until-match", false, fmt.Sprintf("Stop and exit with code %d once the alert fires", ExitCodeMatched))
+			rotateSize := cmd.IntOpt("rotate-size", defaultRotateSize, "The number of megabytes of logs written to each --out file before starting the next one")
 			cmd.Action = func() {
-				if _, err := auth.New(settings, prompts.New()).Signin(); err != nil {
-					logrus.Fatal(err.Error())
+				if _, err := auth.New(settings, prompts.New(settings)).Signin(); err != nil {
+					httpclient.Fatal(err)
 				}
 				if err := config.CheckRequiredAssociation(true, true, settings); err != nil {
-					logrus.Fatal(err.Error())
+					httpclient.Fatal(err)
 				}
-				err := CmdLogs(*query, *follow || *tail, *hours, *mins, *secs, settings.EnvironmentID, settings, New(settings), prompts.New(), environments.New(settings), services.New(settings), sites.New(settings))
+				alert, err := newAlert(*alertPattern, *threshold, *window, *execCmd, *webhook, *untilMatch, settings.HTTPManager.Context())
 				if err != nil {
-					logrus.Fatal(err.Error())
+					httpclient.Fatal(err)
+				}
+				q := &Query{Text: *query, Source: *source, Hosts: *hosts, Services: *svcs}
+				err = CmdLogs(q, *follow || *tail, *since, *until, *hours, *mins, *secs, *pageSize, *fields, *jsonOutput, *out, *rotateSize, alert, settings.EnvironmentID, settings, New(settings), prompts.New(settings), environments.New(settings), services.New(settings), sites.New(settings))
+				if err == errMatched {
+					os.Exit(ExitCodeMatched)
+				}
+				if err != nil {
+					httpclient.Fatal(err)
 				}
 			}
-			cmd.Spec = "[QUERY] [(-f | -t)] [--hours] [--minutes] [--seconds]"
+			cmd.Spec = "[QUERY] [(-f | -t)] [--hours] [--minutes] [--seconds] [--since] [--until] [--source] [--host]... [--service]... [--page-size] [--fields] [--json] [--out [--rotate-size]] [--alert [--threshold] [--window] [--exec] [--webhook] [--until-match]]"
 		}
 	},
 }
 
 // ILogs ...
 type ILogs interface {
-	Output(queryString, sessionToken, domain string, follow bool, hours, minutes, seconds, from int, startTimestamp time.Time, endTimestamp time.Time, env *models.Environment) (int, time.Time, error)
-	Stream(queryString, sessionToken, domain string, follow bool, hours, minutes, seconds, from int, timestamp time.Time, env *models.Environment) error
-	Watch(queryString, domain, sessionToken string) error
+	Output(query *Query, sessionToken, domain string, pageSize int, cursor *Cursor, endTimestamp time.Time, p *Printer) error
+	Stream(query *Query, sessionToken, domain string, pageSize int, cursor *Cursor, p *Printer) error
+	Watch(query *Query, domain, sessionToken string, pageSize int, p *Printer) error
 }
 
 // SLogs is a concrete implementation of ILogs
@@ -0,0 +1,148 @@
+package logs
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/Sirupsen/logrus"
+)
+
+// logLine holds the fields of a single log line. Values are kept as the raw
+// JSON they were received as so that numbers and other non-string fields are
+// written as they are.
+type logLine map[string]json.RawMessage
+
+// get returns the value of a field as text, or an empty string if the log line
+// does not have the field
+func (l logLine) get(name string) string {
+	raw, ok := l[name]
+	if !ok {
+		return ""
+	}
+	var s string
+	if err := json.Unmarshal(raw, &s); err == nil {
+		return s
+	}
+	return string(raw)
+}
+
+// Printer writes log lines as text or as JSON to the terminal, or to the files
+// given with --out
+type Printer struct {
+	// Fields are the fields of each log line that are written, in order
+	Fields []string
+	// JSON writes every log line as a JSON object on its own line instead of
+	// separating the fields with dashes
+	JSON bool
+	// Out is where log lines are written instead of the terminal when set
+	Out io.Writer
+	// Alert is checked against every log line written when set
+	Alert *Alert
+}
+
+// defaultFields returns the fields written when no --fields are given. The
+// source of every log line is only included in JSON, which leaves the text
+// output as it has always been.
+func defaultFields(jsonOutput bool, domain string) []string {
+	if jsonOutput {
+		sourceField, _ := appLogsSource(domain)
+		return []string{timestampField, messageField, sourceField}
+	}
+	return []string{timestampField, messageField}
+}
+
+// parseFields splits the comma separated list of fields given with --fields
+func parseFields(fields string) ([]string, error) {
+	if strings.TrimSpace(fields) == "" {
+		return nil, nil
+	}
+	var parsed []string
+	for _, f := range strings.Split(fields, ",") {
+		f = strings.TrimSpace(f)
+		if f == "" {
+			return nil, fmt.Errorf("Invalid --fields \"%s\". Fields must be separated by a single comma, such as @timestamp,host,message", fields)
+		}
+		parsed = append(parsed, f)
+	}
+	return parsed, nil
+}
+
+// header prints the names of the fields above text output in the terminal
+func (p *Printer) header() {
+	if p.JSON || p.Out != nil {
+		return
+	}
+	if len(p.Fields) == 2 && p.Fields[0] == timestampField && p.Fields[1] == messageField {
+		logrus.Println("        @timestamp       -        message")
+		return
+	}
+	logrus.Println(strings.Join(p.Fields, " - "))
+}
+
+// status prints a message about the logs rather than a log line. Messages are
+// printed on stderr when the terminal output is JSON so that it stays valid.
+func (p *Printer) status(msg string) {
+	if p.JSON && p.Out == nil {
+		fmt.Fprintln(events, msg)
+		return
+	}
+	logrus.Println(msg)
+}
+
+// print writes a single log line and checks it against the alert
+func (p *Printer) print(line logLine) error {
+	b, err := p.format(line)
+	if err != nil {
+		return err
+	}
+	if p.Out == nil {
+		logrus.Println(string(b))
+	} else if _, err = p.Out.Write(append(b, '\n')); err != nil {
+		return err
+	}
+	if p.Alert != nil {
+		return p.Alert.observe(p, line)
+	}
+	return nil
+}
+
+// format returns a log line as text or JSON without a trailing newline
+func (p *Printer) format(line logLine) ([]byte, error) {
+	if p.JSON {
+		return p.formatJSON(line)
+	}
+	values := make([]string, len(p.Fields))
+	for i, f := range p.Fields {
+		values[i] = line.get(f)
+	}
+	return []byte(strings.Join(values, " - ")), nil
+}
+
+// formatJSON returns a log line as a JSON object with the fields in order.
+// Fields the log line does not have are left out.
+func (p *Printer) formatJSON(line logLine) ([]byte, error) {
+	buf := &bytes.Buffer{}
+	buf.WriteString("{")
+	written := 0
+	for _, f := range p.Fields {
+		raw, ok := line[f]
+		if !ok {
+			continue
+		}
+		if written > 0 {
+			buf.WriteString(",")
+		}
+		name, _ := json.Marshal(f)
+		buf.Write(name)
+		buf.WriteString(":")
+		if err := json.Compact(buf, raw); err != nil {
+			return nil, err
+		}
+		written++
+	}
+	buf.WriteString("}")
+	return buf.Bytes(), nil
+}
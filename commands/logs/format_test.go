@@ -0,0 +1,135 @@
+package logs
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+var testLine = logLine{
+	timestampField: json.RawMessage(`"2024-03-01T12:30:00.000Z"`),
+	messageField:   json.RawMessage(`"GET /health \"ok\""`),
+	"source":       json.RawMessage(`"app"`),
+	"status":       json.RawMessage(`200`),
+	"tags":         json.RawMessage(`[ "web", "prod" ]`),
+}
+
+var printTests = []struct {
+	fields   []string
+	json     bool
+	expected string
+}{
+	{defaultFields(false, "pod02-ns.example.com"), false, `2024-03-01T12:30:00.000Z - GET /health "ok"`},
+	{[]string{timestampField, "status", "missing", messageField}, false, `2024-03-01T12:30:00.000Z - 200 -  - GET /health "ok"`},
+	{defaultFields(true, "pod02-ns.example.com"), true, `{"@timestamp":"2024-03-01T12:30:00.000Z","message":"GET /health \"ok\"","source":"app"}`},
+	{defaultFields(true, "pod01-ns.example.com"), true, `{"@timestamp":"2024-03-01T12:30:00.000Z","message":"GET /health \"ok\""}`},
+	{[]string{"status", "tags", "missing", messageField}, true, `{"status":200,"tags":["web","prod"],"message":"GET /health \"ok\""}`},
+}
+
+func TestPrint(t *testing.T) {
+	for _, data := range printTests {
+		t.Logf("Data: %+v", data)
+		out := &bytes.Buffer{}
+		p := &Printer{Fields: data.fields, JSON: data.json, Out: out}
+		if err := p.print(testLine); err != nil {
+			t.Errorf("Unexpected error: %s", err)
+			continue
+		}
+		if out.String() != data.expected+"\n" {
+			t.Errorf("Expected: %s. Found: %s", data.expected, out.String())
+		}
+	}
+}
+
+var parseFieldsTests = []struct {
+	fields    string
+	expectErr bool
+	expected  []string
+}{
+	{"", false, nil},
+	{"@timestamp, host ,message", false, []string{timestampField, hostField, messageField}},
+	{"host,,message", true, nil},
+	{"host,", true, nil},
+}
+
+func TestParseFields(t *testing.T) {
+	for _, data := range parseFieldsTests {
+		t.Logf("Data: %+v", data)
+		fields, err := parseFields(data.fields)
+		if err != nil != data.expectErr {
+			t.Errorf("Unexpected error: %v", err)
+			continue
+		}
+		if strings.Join(fields, ",") != strings.Join(data.expected, ",") {
+			t.Errorf("Expected: %v. Found: %v", data.expected, fields)
+		}
+	}
+}
+
+func TestArchive(t *testing.T) {
+	dir, err := ioutil.TempDir("", "logs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	prefix := filepath.Join(dir, "incident.log")
+
+	// 100 byte lines with room for 3 lines per file
+	a := newArchive(prefix, 300)
+	var lines []string
+	for i := 0; i < 10; i++ {
+		l := strings.Repeat(string('a'+rune(i)), 99) + "\n"
+		lines = append(lines, l)
+		if _, err = a.Write([]byte(l)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err = a.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err = a.Close(); err != nil {
+		t.Errorf("Unexpected error closing again: %s", err)
+	}
+	if a.Lines != 10 || len(a.Files) != 4 {
+		t.Fatalf("Expected 10 lines in 4 files. Found: %d lines in %v", a.Lines, a.Files)
+	}
+
+	var written string
+	for i, name := range a.Files {
+		expectedName := prefix + []string{".0001.gz", ".0002.gz", ".0003.gz", ".0004.gz"}[i]
+		if name != expectedName {
+			t.Errorf("Expected: %s. Found: %s", expectedName, name)
+		}
+		f, err := os.Open(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		gz, err := gzip.NewReader(f)
+		if err != nil {
+			t.Fatalf("%s is not a gzip file: %s", name, err)
+		}
+		b, err := ioutil.ReadAll(gz)
+		f.Close()
+		if err != nil {
+			t.Fatalf("%s is not a complete gzip file: %s", name, err)
+		}
+		if len(b) > 300 {
+			t.Errorf("Expected %s to hold at most 300 bytes. Found: %d", name, len(b))
+		}
+		written += string(b)
+	}
+	if written != strings.Join(lines, "") {
+		t.Errorf("Expected every line to be written once in order. Found: %q", written)
+	}
+
+	// existing files are never overwritten
+	a = newArchive(prefix, 300)
+	if _, err = a.Write([]byte(lines[0])); err == nil || !strings.Contains(err.Error(), "already exists") {
+		t.Errorf("Expected an error for an existing file. Found: %v", err)
+	}
+}
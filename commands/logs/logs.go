@@ -1,11 +1,12 @@
 package logs
 
 import (
-	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"net/url"
+	"reflect"
 	"strings"
 	"time"
 
@@ -14,21 +15,35 @@ import (
 	"github.com/catalyzeio/cli/commands/services"
 	"github.com/catalyzeio/cli/commands/sites"
 	"github.com/catalyzeio/cli/config"
+	"github.com/catalyzeio/cli/lib/interrupt"
 	"github.com/catalyzeio/cli/lib/prompts"
 	"github.com/catalyzeio/cli/models"
 )
 
-const size = 50
-
 // CmdLogs is a way to stream logs from Kibana to your local terminal. This is
 // useful because Kibana is hard to look at because it splits every single
 // log statement into a separate block that spans multiple lines so it's
 // not very cohesive. This is intended to be similar to the `heroku logs`
 // command.
-func CmdLogs(queryString string, follow bool, hours, minutes, seconds int, envID string, settings *models.Settings, il ILogs, ip prompts.IPrompts, ie environments.IEnvironments, is services.IServices, isites sites.ISites) error {
-	if follow && (hours > 0 || minutes > 0 || seconds > 0) {
-		logrus.Warnln("Specifying \"logs -f\" in combination with \"--hours\", \"--minutes\", or \"--seconds\" has been deprecated!")
-		logrus.Warnln("Please specify either \"-f\" or use \"--hours\", \"--minutes\", \"--seconds\" but not both. Support for \"-f\" and a specified time frame will be removed in a later version.")
+func CmdLogs(query *Query, follow bool, since, until string, hours, minutes, seconds, pageSize int, fields string, jsonOutput bool, out string, rotateSize int, alert *Alert, envID string, settings *models.Settings, il ILogs, ip prompts.IPrompts, ie environments.IEnvironments, is services.IServices, isites sites.ISites) error {
+	if follow && (hours > 0 || minutes > 0 || seconds > 0 || since != "") {
+		logrus.Warnln("Specifying \"logs -f\" in combination with \"--hours\", \"--minutes\", \"--seconds\", or \"--since\" has been deprecated!")
+		logrus.Warnln("Please specify either \"-f\" or use \"--hours\", \"--minutes\", \"--seconds\", or \"--since\" but not both. Support for \"-f\" and a specified time frame will be removed in a later version.")
+	}
+	if pageSize < 1 || pageSize > maxPageSize {
+		return fmt.Errorf("--page-size must be between 1 and %d", maxPageSize)
+	}
+	if rotateSize < 1 {
+		return errors.New("--rotate-size must be at least 1")
+	}
+	p := &Printer{JSON: jsonOutput, Alert: alert}
+	var err error
+	if p.Fields, err = parseFields(fields); err != nil {
+		return err
+	}
+	query.Since, query.Until, err = timeRange(since, until, hours, minutes, seconds, follow, time.Now())
+	if err != nil {
+		return err
 	}
 	env, err := ie.Retrieve(envID)
 	if err != nil {
@@ -52,110 +67,158 @@ func CmdLogs(queryString string, follow bool, hours, minutes, seconds int, envID
 	if domain == "" {
 		return errors.New("Could not determine the fully qualified domain name of your environment. Please contact Catalyze Support at support@catalyze.io with this error message to resolve this issue.")
 	}
-	if follow {
-		if err := il.Watch(queryString, domain, settings.SessionToken); err != nil {
-			logrus.Debugf("Error attempting to stream logs from logwatch: %s", err)
-		} else {
-			return nil
+	if len(p.Fields) == 0 {
+		p.Fields = defaultFields(jsonOutput, domain)
+	}
+	query.Fields = p.Fields
+	if out == "" {
+		return printLogs(query, follow, pageSize, domain, settings, il, p)
+	}
+
+	a := newArchive(out, int64(rotateSize)*1024*1024)
+	p.Out = a
+	// the current file is finished when interrupted so that every file
+	// written is a complete gzip file
+	defer interrupt.OnInterrupt(interrupt.Files, func(ctx context.Context) error {
+		return a.Close()
+	})()
+	err = printLogs(query, follow, pageSize, domain, settings, il, p)
+	if closeErr := a.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil && err != errMatched {
+		return err
+	}
+	if a.Lines == 0 {
+		logrus.Println("No logs were found so no files were written")
+	} else {
+		logrus.Printf("Wrote %d log lines to %s", a.Lines, strings.Join(a.Files, ", "))
+	}
+	return err
+}
+
+// printLogs prints the logs matching the query and follows them if asked to
+func printLogs(query *Query, follow bool, pageSize int, domain string, settings *models.Settings, il ILogs, p *Printer) error {
+	if follow && query.watchable() {
+		err := il.Watch(query, domain, settings.SessionToken, pageSize, p)
+		if !errors.Is(err, errUnavailable) {
+			return err
 		}
+		logrus.Debugf("Error attempting to stream logs from logwatch: %s", err)
 	}
-	from := 0
-	offset := time.Duration(hours)*time.Hour + time.Duration(minutes)*time.Minute + time.Duration(seconds)*time.Second
-	timestamp := time.Now().In(time.UTC).Add(-1 * offset)
-	from, timestamp, err = il.Output(queryString, settings.SessionToken, domain, follow, hours, minutes, seconds, from, timestamp, time.Now(), env)
-	if err != nil {
+	end := query.Until
+	if end.IsZero() {
+		end = time.Now()
+	}
+	cursor := &Cursor{}
+	if err := il.Output(query, settings.SessionToken, domain, pageSize, cursor, end, p); err != nil {
 		return err
 	}
 	if follow {
-		return il.Stream(queryString, settings.SessionToken, domain, follow, hours, minutes, seconds, from, timestamp, env)
+		return il.Stream(query, settings.SessionToken, domain, pageSize, cursor, p)
 	}
 	return nil
 }
 
-func (l *SLogs) Output(queryString, sessionToken, domain string, follow bool, hours, minutes, seconds, from int, startTimestamp, endTimestamp time.Time, env *models.Environment) (int, time.Time, error) {
-	appLogsIdentifier := "source"
-	appLogsValue := "app"
-	if strings.HasPrefix(domain, "pod01") || strings.HasPrefix(domain, "csb01") {
-		appLogsIdentifier = "syslog_program"
-		appLogsValue = "supervisord"
-	}
+// Output prints every log line matching the query that was written up to
+// endTimestamp, starting from the cursor. Pages of logs are retrieved with
+// search_after rather than an offset so that any number of log lines can be
+// paged through, and log lines the cursor has already seen are skipped so that
+// every log line is printed exactly once. The cursor is left after the last
+// log line printed.
+func (l *SLogs) Output(query *Query, sessionToken, domain string, pageSize int, cursor *Cursor, endTimestamp time.Time, p *Printer) error {
+	p.header()
+	return l.search(query, sessionToken, domain, pageSize, cursor, endTimestamp, p.print)
+}
 
+// search retrieves every log line matching the query that was written up to
+// endTimestamp, starting from the cursor, and passes the log lines the cursor
+// has not seen yet to print
+func (l *SLogs) search(query *Query, sessionToken, domain string, pageSize int, cursor *Cursor, endTimestamp time.Time, print func(line logLine) error) error {
 	urlString := fmt.Sprintf("https://%s/__es", domain)
 
 	headers := map[string][]string{"Cookie": {"sessionToken=" + url.QueryEscape(sessionToken)}}
 
-	logrus.Println("        @timestamp       -        message")
+	bounded := *query
+	if bounded.Until.IsZero() || bounded.Until.After(endTimestamp) {
+		bounded.Until = endTimestamp
+	}
+
 	for {
-		queryBytes := generateQuery(queryString, appLogsIdentifier, appLogsValue, startTimestamp, from)
+		queryBytes, err := generateQuery(&bounded, domain, pageSize, cursor)
+		if err != nil {
+			return err
+		}
 
 		resp, statusCode, err := l.Settings.HTTPManager.Get(queryBytes, fmt.Sprintf("%s/_search", urlString), headers)
 		if err != nil {
-			return from, startTimestamp, err
+			return err
 		}
 		var logs models.Logs
 		err = l.Settings.HTTPManager.ConvertResp(resp, statusCode, &logs)
 		if err != nil {
-			return from, startTimestamp, err
+			return err
 		}
-
-		end := time.Time{}
-		for _, lh := range *logs.Hits.Hits {
-			logrus.Printf("%s - %s", lh.Fields["@timestamp"][0], lh.Fields["message"][0])
-			end, _ = time.Parse(time.RFC3339Nano, lh.Fields["@timestamp"][0])
+		if logs.Hits == nil || logs.Hits.Hits == nil {
+			return nil
 		}
-		amount := len(*logs.Hits.Hits)
 
-		from += len(*logs.Hits.Hits)
-		// TODO this will infinite loop if it always retrieves `size` hits
-		// and it fails to parse the end timestamp. very small window of opportunity.
-		if amount < size || end.After(endTimestamp) {
-			break
+		hits := *logs.Hits.Hits
+		previous := cursor.SearchAfter
+		for _, lh := range hits {
+			if !cursor.advance(lh) {
+				continue
+			}
+			if err = print(hitLine(lh)); err != nil {
+				return err
+			}
+		}
+		if len(hits) < pageSize {
+			return nil
+		}
+		if len(hits[len(hits)-1].Sort) == 0 || reflect.DeepEqual(previous, cursor.SearchAfter) {
+			return errors.New("The logging server did not return the position of the last log line so the rest of the logs could not be retrieved")
 		}
-		time.Sleep(config.JobPollTime * time.Second)
 	}
-	return from, startTimestamp, nil
 }
 
-func (l *SLogs) Stream(queryString, sessionToken, domain string, follow bool, hours, minutes, seconds, from int, timestamp time.Time, env *models.Environment) error {
-	for {
-		f, t, err := l.Output(queryString, sessionToken, domain, follow, hours, minutes, seconds, from, timestamp, time.Now(), env)
-		if err != nil {
-			return err
+// hitLine returns the fields of a log line. Fields with a dot in their name,
+// such as kubernetes.pod, are nested in objects in _source and are added to
+// the log line under their full name as well.
+func hitLine(lh models.LogHits) logLine {
+	line := logLine{}
+	flatten(line, "", lh.Source)
+	return line
+}
+
+func flatten(line logLine, prefix string, source map[string]json.RawMessage) {
+	for name, raw := range source {
+		line[prefix+name] = raw
+		var nested map[string]json.RawMessage
+		if err := json.Unmarshal(raw, &nested); err == nil {
+			flatten(line, prefix+name+".", nested)
 		}
-		from = f
-		timestamp = t
-		time.Sleep(config.LogPollTime * time.Second)
 	}
 }
 
-func generateQuery(queryString, appLogsIdentifier, appLogsValue string, timestamp time.Time, from int) []byte {
-	query := `{
-	"fields": ["@timestamp", "message", "` + appLogsIdentifier + `"],
-	"query": {
-		"wildcard": {
-			"message": "` + queryString + `"
-		}
-	},
-	"filter": {
-		"bool": {
-			"must": [
-				{"term": {"` + appLogsIdentifier + `": "` + appLogsValue + `"}},
-				{"range": {"@timestamp": {"gt": "` + fmt.Sprintf("%04d-%02d-%02dT%02d:%02d:%02dZ", timestamp.Year(), timestamp.Month(), timestamp.Day(), timestamp.Hour(), timestamp.Minute(), timestamp.Second()) + `"}}}
-			]
+// field returns the value of a top level field of a log line as text
+func field(lh models.LogHits, name string) string {
+	if raw, ok := lh.Source[name]; ok {
+		return logLine{name: raw}.get(name)
+	}
+	return ""
+}
+
+// Stream polls for new logs matching the query forever, starting from the
+// cursor
+func (l *SLogs) Stream(query *Query, sessionToken, domain string, pageSize int, cursor *Cursor, p *Printer) error {
+	for {
+		if err := interrupt.Sleep(l.Settings.HTTPManager.Context(), config.LogPollTime*time.Second); err != nil {
+			return err
 		}
-	},
-	"sort": {
-		"@timestamp": {
-			"order": "asc"
-		},
-		"message": {
-			"order": "asc"
+		cursor.Rewind()
+		if err := l.Output(query, sessionToken, domain, pageSize, cursor, time.Now(), p); err != nil {
+			return err
 		}
-	},
-	"from": ` + fmt.Sprintf("%d", from) + `,
-	"size": ` + fmt.Sprintf("%d", size) + `
-	}`
-	var buf bytes.Buffer
-	json.Compact(&buf, []byte(query))
-	return buf.Bytes()
+	}
 }
@@ -0,0 +1,269 @@
+package logs
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/catalyzeio/cli/lib/httpclient"
+	"github.com/catalyzeio/cli/models"
+)
+
+// fakeLog is a log line stored by fakeElasticsearch
+type fakeLog struct {
+	id        string
+	timestamp time.Time
+	message   string
+}
+
+// fakeElasticsearch answers search requests the way Elasticsearch does for
+// the queries generateQuery builds
+type fakeElasticsearch struct {
+	mu   sync.Mutex
+	logs []fakeLog
+}
+
+// source returns the requested fields of the log line the way Elasticsearch
+// filters _source, with fields named with a dot nested in objects
+func (l fakeLog) source(requested []string) map[string]json.RawMessage {
+	values := map[string]interface{}{
+		timestampField: l.timestamp.Format("2006-01-02T15:04:05.000Z07:00"),
+		messageField:   l.message,
+		"source":       "app",
+		hostField:      "web01",
+		"status":       200,
+		"kubernetes":   map[string]string{"pod": "web-1"},
+	}
+	source := map[string]json.RawMessage{}
+	for _, f := range requested {
+		if v, ok := values[f]; ok {
+			b, _ := json.Marshal(v)
+			source[f] = b
+		} else if parts := strings.SplitN(f, ".", 2); len(parts) == 2 && values[parts[0]] != nil {
+			b, _ := json.Marshal(values[parts[0]])
+			source[parts[0]] = b
+		}
+	}
+	return source
+}
+
+// validateSearch returns the error Elasticsearch 5 and 6 respond with for a
+// search request they do not accept, such as one using the top level filter
+// and fields of Elasticsearch 1 or sorting on _id
+func validateSearch(body []byte) error {
+	var req map[string]json.RawMessage
+	if err := json.Unmarshal(body, &req); err != nil {
+		return err
+	}
+	for key := range req {
+		if !contains([]string{"query", "_source", "sort", "search_after", "size"}, key) {
+			return fmt.Errorf("Unknown key [%s] in the search request", key)
+		}
+	}
+	var query map[string]map[string]json.RawMessage
+	if err := json.Unmarshal(req["query"], &query); err != nil || len(query) != 1 || query["bool"] == nil {
+		return fmt.Errorf("Expected a bool query. Found: %s", req["query"])
+	}
+	for key := range query["bool"] {
+		if !contains([]string{"must", "filter", "should", "must_not"}, key) {
+			return fmt.Errorf("[bool] query does not support [%s]", key)
+		}
+	}
+	var sorts []map[string]json.RawMessage
+	if err := json.Unmarshal(req["sort"], &sorts); err != nil {
+		return err
+	}
+	for _, s := range sorts {
+		if _, ok := s["_id"]; ok {
+			return errors.New("Fielddata access on the _id field is disallowed")
+		}
+	}
+	return nil
+}
+
+func (es *fakeElasticsearch) add(timestamp time.Time, count int) {
+	es.mu.Lock()
+	defer es.mu.Unlock()
+	for i := 0; i < count; i++ {
+		id := fmt.Sprintf("log-%04d", len(es.logs))
+		es.logs = append(es.logs, fakeLog{id: id, timestamp: timestamp, message: "message " + id})
+	}
+	sort.Slice(es.logs, func(i, j int) bool {
+		if !es.logs[i].timestamp.Equal(es.logs[j].timestamp) {
+			return es.logs[i].timestamp.Before(es.logs[j].timestamp)
+		}
+		return es.logs[i].id < es.logs[j].id
+	})
+}
+
+func (es *fakeElasticsearch) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, err := ioutil.ReadAll(r.Body)
+	if err == nil {
+		err = validateSearch(body)
+	}
+	var req searchRequest
+	if err == nil {
+		err = json.Unmarshal(body, &req)
+	}
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprintf(w, `{"error":%q}`, err.Error())
+		return
+	}
+	var bound rangeBound
+	for _, clause := range req.Query.Bool.Filter {
+		if b, ok := clause.Range[timestampField]; ok {
+			bound = b
+		}
+	}
+	var afterMillis int64
+	var afterUID string
+	if len(req.SearchAfter) == 2 {
+		json.Unmarshal(req.SearchAfter[0], &afterMillis)
+		json.Unmarshal(req.SearchAfter[1], &afterUID)
+	}
+
+	es.mu.Lock()
+	defer es.mu.Unlock()
+	hits := []models.LogHits{}
+	for _, l := range es.logs {
+		if len(hits) == req.Size {
+			break
+		}
+		if !inRange(l.timestamp, bound) {
+			continue
+		}
+		millis := l.timestamp.UnixNano() / int64(time.Millisecond)
+		uid := "logs#" + l.id
+		if req.SearchAfter != nil && (millis < afterMillis || millis == afterMillis && uid <= afterUID) {
+			continue
+		}
+		hits = append(hits, models.LogHits{
+			ID:     l.id,
+			Source: l.source(req.Source),
+			Sort:   []json.RawMessage{json.RawMessage(fmt.Sprintf("%d", millis)), json.RawMessage(fmt.Sprintf("%q", uid))},
+		})
+	}
+	json.NewEncoder(w).Encode(models.Logs{Hits: &models.Hits{Hits: &hits}})
+}
+
+func inRange(t time.Time, bound rangeBound) bool {
+	parse := func(s string) time.Time {
+		parsed, _ := time.Parse(time.RFC3339Nano, s)
+		return parsed
+	}
+	return (bound.GT == "" || t.After(parse(bound.GT))) &&
+		(bound.GTE == "" || !t.Before(parse(bound.GTE))) &&
+		(bound.LTE == "" || !t.After(parse(bound.LTE)))
+}
+
+// messageFormatter writes only the message of each log entry
+type messageFormatter struct{}
+
+func (messageFormatter) Format(entry *logrus.Entry) ([]byte, error) {
+	return []byte(entry.Message + "\n"), nil
+}
+
+// printedMessages returns how many times each log message was printed
+func printedMessages(out *bytes.Buffer) map[string]int {
+	printed := map[string]int{}
+	for _, line := range strings.Split(out.String(), "\n") {
+		if i := strings.Index(line, " - message "); i >= 0 {
+			printed[line[i+len(" - "):]]++
+		}
+	}
+	out.Reset()
+	return printed
+}
+
+var outputTests = []struct {
+	pageSize int
+	count    int
+}{
+	{50, 1234},
+	{500, 1234},
+	{7, 49},
+	{1, 5},
+}
+
+func TestOutput(t *testing.T) {
+	out := &bytes.Buffer{}
+	logrus.SetOutput(out)
+	logrus.SetFormatter(messageFormatter{})
+	start := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	for _, data := range outputTests {
+		t.Logf("Data: %+v", data)
+		es := &fakeElasticsearch{}
+		// most log lines share their timestamp with many others, so pages
+		// regularly end in the middle of a timestamp
+		for i := 0; i < data.count; i += 97 {
+			count := 97
+			if data.count-i < count {
+				count = data.count - i
+			}
+			es.add(start.Add(time.Duration(i)*time.Second), count)
+		}
+		ts := httptest.NewTLSServer(es)
+		l := New(&models.Settings{HTTPManager: httpclient.NewTLSHTTPManager(true, nil)})
+		query := &Query{Text: "*", Since: start.Add(-time.Second)}
+		domain := strings.TrimPrefix(ts.URL, "https://")
+		end := start.Add(24 * time.Hour)
+
+		printer := &Printer{Fields: defaultFields(false, domain)}
+		cursor := &Cursor{}
+		if err := l.Output(query, "token", domain, data.pageSize, cursor, end, printer); err != nil {
+			t.Errorf("Unexpected error: %s", err)
+			ts.Close()
+			continue
+		}
+		printed := printedMessages(out)
+		if len(printed) != data.count {
+			t.Errorf("Expected %d log lines. Found: %d", data.count, len(printed))
+		}
+		for message, times := range printed {
+			if times != 1 {
+				t.Errorf("Expected %s to be printed once. Found: %d times", message, times)
+			}
+		}
+
+		// log lines indexed late with the same timestamp as the last one are
+		// picked up after rewinding, without printing the others again
+		last := es.logs[len(es.logs)-1].timestamp
+		es.add(last, 3)
+		cursor.Rewind()
+		if err := l.Output(query, "token", domain, data.pageSize, cursor, end, printer); err != nil {
+			t.Errorf("Unexpected error: %s", err)
+		}
+		if printed = printedMessages(out); len(printed) != 3 {
+			t.Errorf("Expected the 3 late log lines. Found: %v", printed)
+		}
+		ts.Close()
+	}
+}
+
+func TestHitLine(t *testing.T) {
+	l := fakeLog{id: "log-0001", timestamp: time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC), message: "message log-0001"}
+	hit := models.LogHits{ID: l.id, Source: l.source([]string{timestampField, messageField, "status", "kubernetes.pod"})}
+	p := &Printer{Fields: []string{timestampField, "status", "kubernetes.pod", messageField}, JSON: true}
+	b, err := p.format(hitLine(hit))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `{"@timestamp":"2024-03-01T00:00:00.000Z","status":200,"kubernetes.pod":"web-1","message":"message log-0001"}`
+	if string(b) != expected {
+		t.Errorf("Expected: %s. Found: %s", expected, string(b))
+	}
+	if timestamp := field(hit, timestampField); timestamp != "2024-03-01T00:00:00.000Z" {
+		t.Errorf("Expected the timestamp of the hit. Found: %s", timestamp)
+	}
+}
@@ -1,72 +1,254 @@
 package logs
 
 import (
+	"context"
 	"crypto/tls"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"net/http"
 	"net/url"
 	"os"
-	"os/signal"
-	"regexp"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/Sirupsen/logrus"
+	"github.com/catalyzeio/cli/config"
+	"github.com/catalyzeio/cli/lib/interrupt"
 	"github.com/gorilla/websocket"
 )
 
 const (
 	writeTimeout = 5 * time.Second
+	// minReconnectWait and maxReconnectWait bound the time waited between
+	// attempts to reconnect to logwatch
+	minReconnectWait = time.Second
+	maxReconnectWait = 30 * time.Second
+	// streamBuffer is the number of streamed log lines held while the log
+	// lines written while disconnected are retrieved
+	streamBuffer = 1000
 )
 
-type LogMessage struct {
-	Message   string `json:"message"`
-	Timestamp string `json:"@timestamp"`
-	Source    string `json:"source"`
+// errUnavailable is returned by Watch when logwatch can not be connected to,
+// in which case new logs are polled for instead
+var errUnavailable = errors.New("logwatch is unavailable")
+
+// events is where reconnecting to logwatch is reported, keeping it apart from
+// the logs
+var events io.Writer = os.Stderr
+
+// watchable returns whether logwatch can stream the logs matching the query.
+// Logwatch streams every log line as it is written so it can not search them
+// like Elasticsearch does.
+func (q *Query) watchable() bool {
+	text := strings.TrimSpace(q.Text)
+	return (text == "" || text == "*") && q.Source == "" && len(q.Hosts) == 0 && len(q.Services) == 0
 }
 
-func (l *SLogs) Watch(queryString, domain, sessionToken string) error {
-	if queryString == "*" {
-		queryString = ""
-	}
-	query, err := regexp.Compile(queryString)
-	if err != nil {
-		return err
-	}
-	logrus.Println("Streaming logs...")
+// Watch streams every new log line from logwatch until interrupted. The query
+// must be watchable. When the connection drops, Watch reconnects with backoff
+// and retrieves the log lines written while disconnected from Elasticsearch
+// before streaming again, so no log line is lost or printed twice. An error
+// wrapping errUnavailable is returned if logwatch can not be connected to at
+// all.
+func (l *SLogs) Watch(query *Query, domain, sessionToken string, pageSize int, p *Printer) error {
+	skip, _ := strconv.ParseBool(os.Getenv(config.SkipVerifyEnvVar))
 	dialer := &websocket.Dialer{
 		Proxy: http.ProxyFromEnvironment,
 		TLSClientConfig: &tls.Config{
-			MinVersion: tls.VersionTLS12,
+			MinVersion:         tls.VersionTLS12,
+			InsecureSkipVerify: skip,
 		},
 	}
 	headers := http.Header{"Cookie": {"sessionToken=" + url.QueryEscape(sessionToken)}}
 	urlString := fmt.Sprintf("wss://%s/stream/", domain)
 	c, _, err := dialer.Dial(urlString, headers)
 	if err != nil {
+		return fmt.Errorf("%w: %s", errUnavailable, err)
+	}
+	p.status("Streaming logs...")
+
+	ctx := l.Settings.HTTPManager.Context()
+	state := &streamState{since: time.Now().UTC(), printer: p}
+	reconnected := false
+	for {
+		lines := make(chan []byte, streamBuffer)
+		go readWS(ctx, c, lines)
+		if reconnected {
+			if err = l.backfill(query, domain, sessionToken, pageSize, state); errors.Is(err, errMatched) {
+				c.Close()
+				return err
+			} else if err != nil {
+				fmt.Fprintf(events, "Could not retrieve the logs written while disconnected: %s\n", err)
+			}
+		}
+		err = state.watch(ctx, lines)
+		c.Close()
+		if err != nil {
+			return err
+		}
+		if ctx.Err() != nil {
+			break
+		}
+		fmt.Fprintln(events, "Lost the connection to the log stream")
+		if c, err = reconnect(ctx, dialer, urlString, headers); err != nil {
+			if ctx.Err() != nil {
+				break
+			}
+			return err
+		}
+		reconnected = true
+	}
+	p.status("Disconnected")
+	return nil
+}
+
+// reconnect dials logwatch until it succeeds, waiting twice as long after
+// every failed attempt
+func reconnect(ctx context.Context, dialer *websocket.Dialer, urlString string, headers http.Header) (*websocket.Conn, error) {
+	wait := minReconnectWait
+	for attempt := 1; ; attempt++ {
+		fmt.Fprintf(events, "Reconnecting to the log stream in %s (attempt %d)\n", wait, attempt)
+		if err := interrupt.Sleep(ctx, wait); err != nil {
+			return nil, err
+		}
+		c, resp, err := dialer.Dial(urlString, headers)
+		if err == nil {
+			fmt.Fprintln(events, "Reconnected to the log stream")
+			return c, nil
+		}
+		if resp != nil && (resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden) {
+			return nil, fmt.Errorf("Could not reconnect to the log stream: %s", resp.Status)
+		}
+		fmt.Fprintf(events, "Could not reconnect to the log stream: %s\n", err)
+		wait *= 2
+		if wait > maxReconnectWait {
+			wait = maxReconnectWait
+		}
+	}
+}
+
+// backfill prints the log lines written since the last one printed, which
+// were missed while disconnected from logwatch
+func (l *SLogs) backfill(query *Query, domain, sessionToken string, pageSize int, state *streamState) error {
+	gap := *query
+	gap.Since = state.since
+	if latest, err := time.Parse(time.RFC3339Nano, state.latest); err == nil {
+		// the cursor starts the first page at the latest timestamp and the
+		// pages after it continue from the cursor, so Since only needs to be
+		// before the latest timestamp
+		gap.Since = latest.Add(-time.Second)
+	}
+	streamed := map[string]int{}
+	for key, count := range state.printed {
+		streamed[key] = count
+	}
+	cursor := &Cursor{Timestamp: state.latest, Streamed: streamed}
+	state.backfilled = map[string]int{}
+	err := l.search(&gap, sessionToken, domain, pageSize, cursor, time.Now(), func(line logLine) error {
+		if err := state.printer.print(line); err != nil {
+			return err
+		}
+		timestamp, message := line.get(timestampField), line.get(messageField)
+		state.backfilled[lineKey(timestamp, message)]++
+		state.record(timestamp, message)
+		return nil
+	})
+	state.backfilledUntil = state.latest
+	return err
+}
+
+// streamState tracks the log lines printed while watching the logs so that the
+// log lines written while disconnected are printed exactly once
+type streamState struct {
+	printer *Printer
+	// since is when logwatch was first connected to. The first backfill starts
+	// there if no log line was printed before it.
+	since time.Time
+	// latest is the latest timestamp printed and printed counts the log lines
+	// printed with it
+	latest  string
+	printed map[string]int
+	// backfilled counts the log lines printed by the last backfill that have
+	// not been streamed yet. Logwatch streams the log lines written while
+	// backfilling as well, and they are skipped until the stream passes
+	// backfilledUntil.
+	backfilled      map[string]int
+	backfilledUntil string
+}
+
+// watch prints the log lines streamed from logwatch until the connection
+// drops or ctx is done. An error is only returned if a log line could not be
+// written.
+func (s *streamState) watch(ctx context.Context, lines <-chan []byte) error {
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case msg, ok := <-lines:
+			if !ok {
+				return nil
+			}
+			if err := s.stream(msg); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// stream prints a log line from logwatch unless the last backfill already
+// printed it
+func (s *streamState) stream(msg []byte) error {
+	var line logLine
+	if err := json.Unmarshal(msg, &line); err != nil {
+		logrus.StandardLogger().Out.Write(msg)
+		return nil
+	}
+	timestamp, message := line.get(timestampField), line.get(messageField)
+	if s.backfilled != nil {
+		key := lineKey(timestamp, message)
+		if s.backfilled[key] > 0 {
+			s.backfilled[key]--
+			return nil
+		}
+		if after(timestamp, s.backfilledUntil) {
+			s.backfilled = nil
+		}
+	}
+	if err := s.printer.print(line); err != nil {
 		return err
 	}
-	defer c.Close()
-
-	interrupt := make(chan os.Signal, 1)
-	signal.Notify(interrupt, os.Interrupt)
-
-	done := make(chan struct{}, 2)
-	go func() {
-		<-interrupt
-		done <- struct{}{}
-	}()
-	go readWS(c, query, done)
-	<-done
-	logrus.Println("Disconnected")
+	s.record(timestamp, message)
 	return nil
 }
 
-// Reads incoming data from the websocket and forwards it to stdout.
-func readWS(ws *websocket.Conn, query *regexp.Regexp, done chan struct{}) {
-	defer func() {
-		done <- struct{}{}
-	}()
+// record keeps track of a printed log line if it is the latest one
+func (s *streamState) record(timestamp, message string) {
+	if s.latest == "" || after(timestamp, s.latest) {
+		s.latest = timestamp
+		s.printed = map[string]int{}
+	}
+	if timestamp == s.latest {
+		s.printed[lineKey(timestamp, message)]++
+	}
+}
+
+// after returns whether timestamp a is later than timestamp b
+func after(a, b string) bool {
+	ta, errA := time.Parse(time.RFC3339Nano, a)
+	tb, errB := time.Parse(time.RFC3339Nano, b)
+	if errA != nil || errB != nil {
+		return a > b
+	}
+	return ta.After(tb)
+}
+
+// Reads incoming data from the websocket and forwards it until the connection
+// drops or ctx is done. lines is closed when the connection drops.
+func readWS(ctx context.Context, ws *websocket.Conn, lines chan<- []byte) {
+	defer close(lines)
 	ws.SetPingHandler(func(string) error {
 		ws.SetWriteDeadline(time.Now().Add(writeTimeout))
 		return ws.WriteMessage(websocket.PongMessage, []byte{})
@@ -76,14 +258,10 @@ func readWS(ws *websocket.Conn, query *regexp.Regexp, done chan struct{}) {
 		if err != nil {
 			return
 		}
-		var log LogMessage
-		err = json.Unmarshal(msg, &log)
-		if err == nil {
-			if query == nil || query.MatchString(log.Message) {
-				logrus.Printf("%s - %s", log.Timestamp, log.Message)
-			}
-		} else {
-			logrus.StandardLogger().Out.Write(msg)
+		select {
+		case lines <- msg:
+		case <-ctx.Done():
+			return
 		}
 	}
 }
@@ -0,0 +1,152 @@
+package logs
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/catalyzeio/cli/config"
+	"github.com/catalyzeio/cli/lib/httpclient"
+	"github.com/catalyzeio/cli/models"
+	"github.com/gorilla/websocket"
+)
+
+// lockedBuffer is a buffer that can be written to while it is read
+type lockedBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *lockedBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *lockedBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+// fakeLogwatch streams a fixed set of log lines on each connection and then
+// drops it, except for the last connection which is kept open
+type fakeLogwatch struct {
+	mu          sync.Mutex
+	connections [][]fakeLog
+}
+
+func (lw *fakeLogwatch) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	lw.mu.Lock()
+	if len(lw.connections) == 0 {
+		lw.mu.Unlock()
+		w.WriteHeader(http.StatusServiceUnavailable)
+		return
+	}
+	lines := lw.connections[0]
+	lw.connections = lw.connections[1:]
+	last := len(lw.connections) == 0
+	lw.mu.Unlock()
+
+	c, err := (&websocket.Upgrader{}).Upgrade(w, r, nil)
+	if err != nil {
+		return
+	}
+	defer c.Close()
+	for _, l := range lines {
+		msg := fmt.Sprintf(`{"@timestamp":%q,"message":%q}`, l.timestamp.Format("2006-01-02T15:04:05.000Z07:00"), l.message)
+		if err = c.WriteMessage(websocket.TextMessage, []byte(msg)); err != nil {
+			return
+		}
+	}
+	if last {
+		for {
+			if _, _, err = c.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}
+}
+
+func TestWatchReconnect(t *testing.T) {
+	out := &lockedBuffer{}
+	logrus.SetOutput(out)
+	logrus.SetFormatter(messageFormatter{})
+	stderr := &lockedBuffer{}
+	events = stderr
+	defer func() {
+		events = os.Stderr
+	}()
+	os.Setenv(config.SkipVerifyEnvVar, "true")
+	defer os.Unsetenv(config.SkipVerifyEnvVar)
+
+	start := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	es := &fakeElasticsearch{}
+	es.add(start, 3)
+	es.add(start.Add(time.Second), 2)
+	es.add(start.Add(2*time.Second), 1)
+	logs := es.logs
+	written := fakeLog{id: "log-0006", timestamp: start.Add(3 * time.Second), message: "message log-0006"}
+	// the first connection drops before log-0002 to log-0005 are streamed,
+	// and the second connection streams log-0004 and log-0005 again
+	lw := &fakeLogwatch{connections: [][]fakeLog{logs[:2], {logs[4], logs[5], written}}}
+
+	mux := http.NewServeMux()
+	mux.Handle("/__es/_search", es)
+	mux.Handle("/stream/", lw)
+	ts := httptest.NewTLSServer(mux)
+	defer ts.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	l := New(&models.Settings{HTTPManager: httpclient.NewTLSHTTPManager(true, nil).WithContext(ctx)})
+	done := make(chan error, 1)
+	go func() {
+		done <- l.Watch(&Query{Text: "*"}, strings.TrimPrefix(ts.URL, "https://"), "token", 2, &Printer{Fields: defaultFields(false, "")})
+	}()
+
+	deadline := time.Now().Add(10 * time.Second)
+	for !strings.Contains(out.String(), written.message) && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	cancel()
+	if err := <-done; err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	for i := 0; i <= 6; i++ {
+		message := fmt.Sprintf("message log-%04d", i)
+		if count := strings.Count(out.String(), message+"\n"); count != 1 {
+			t.Errorf("Expected %s to be printed once. Found: %d times\n%s", message, count, out.String())
+		}
+	}
+	for _, event := range []string{"Lost the connection to the log stream", "Reconnected to the log stream"} {
+		if !strings.Contains(stderr.String(), event) {
+			t.Errorf("Expected %q to be reported. Found: %s", event, stderr.String())
+		}
+		if strings.Contains(out.String(), event) {
+			t.Errorf("Expected %q to be kept apart from the logs", event)
+		}
+	}
+}
+
+func TestWatchUnavailable(t *testing.T) {
+	ts := httptest.NewTLSServer(&fakeLogwatch{})
+	defer ts.Close()
+	os.Setenv(config.SkipVerifyEnvVar, "true")
+	defer os.Unsetenv(config.SkipVerifyEnvVar)
+	l := New(&models.Settings{HTTPManager: httpclient.NewTLSHTTPManager(true, nil)})
+	err := l.Watch(&Query{Text: "*"}, strings.TrimPrefix(ts.URL, "https://"), "token", 2, &Printer{Fields: defaultFields(false, "")})
+	if !errors.Is(err, errUnavailable) {
+		t.Errorf("Expected errUnavailable. Found: %v", err)
+	}
+}
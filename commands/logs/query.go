@@ -0,0 +1,263 @@
+package logs
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/catalyzeio/cli/models"
+)
+
+const (
+	// messageField is the field holding the text of a log line
+	messageField = "message"
+	// timestampField is the field holding the time a log line was written
+	timestampField = "@timestamp"
+	// hostField and serviceField are the fields --host and --service filter on
+	hostField    = "host"
+	serviceField = "service"
+	// tiebreakerField orders log lines written at the same time so that every
+	// log line has a unique position to page from. Elasticsearch 5 and 6 can
+	// not sort on _id, but can sort on _uid, which is the type and ID of the
+	// log line.
+	tiebreakerField = "_uid"
+	// defaultPageSize is the number of log lines retrieved per request
+	defaultPageSize = 500
+	// maxPageSize is the largest page Elasticsearch returns
+	maxPageSize = 10000
+)
+
+// Query describes the logs to search for. Every field narrows down the logs
+// that are returned.
+type Query struct {
+	// Text is a Lucene query string searched for in the message of each log
+	// line. An empty string or * matches every log line.
+	Text string
+	// Source is the source of the logs, which defaults to the application logs
+	// of the pod when empty
+	Source string
+	// Hosts and Services only keep logs from any of the given hosts and
+	// services when not empty
+	Hosts    []string
+	Services []string
+	// Since and Until are the time range of the logs. Logs written exactly at
+	// Since are not included. A zero Until has no end.
+	Since time.Time
+	Until time.Time
+	// Fields are retrieved for each log line along with its timestamp,
+	// message, and source
+	Fields []string
+}
+
+// Cursor is the position in the logs the next page is retrieved from. The
+// zero value starts at the beginning of the query's time range.
+type Cursor struct {
+	// SearchAfter holds the sort values of the last log line retrieved,
+	// exactly as Elasticsearch returned them
+	SearchAfter []json.RawMessage
+	// Timestamp is the timestamp of the last log line retrieved and Seen holds
+	// the IDs of every log line retrieved with that timestamp
+	Timestamp string
+	Seen      map[string]bool
+	// Streamed counts the log lines with the cursor's timestamp that were
+	// already printed from logwatch, by timestamp and message. Log lines
+	// retrieved from Elasticsearch that match one of them are skipped.
+	Streamed map[string]int
+}
+
+// Rewind moves the cursor back to the start of the timestamp of the last log
+// line retrieved. Log lines written at the same time as the last one can be
+// indexed after it was retrieved, so following the logs picks up from the
+// timestamp again and skips the log lines that were already seen.
+func (c *Cursor) Rewind() {
+	if c.Timestamp != "" {
+		c.SearchAfter = nil
+	}
+}
+
+// advance moves the cursor past the given log line and returns false if the
+// log line was already retrieved
+func (c *Cursor) advance(hit models.LogHits) bool {
+	if len(hit.Sort) > 0 {
+		c.SearchAfter = hit.Sort
+	}
+	timestamp := field(hit, timestampField)
+	if timestamp != c.Timestamp || c.Seen == nil {
+		c.Timestamp = timestamp
+		c.Seen = map[string]bool{}
+	}
+	if c.Seen[hit.ID] {
+		return false
+	}
+	c.Seen[hit.ID] = true
+	if key := lineKey(timestamp, field(hit, messageField)); c.Streamed[key] > 0 {
+		c.Streamed[key]--
+		return false
+	}
+	return true
+}
+
+// lineKey identifies a log line by its content for log lines that do not have
+// an ID, such as those streamed from logwatch
+func lineKey(timestamp, message string) string {
+	return timestamp + "\x00" + message
+}
+
+// searchRequest is the body of an Elasticsearch 5 or 6 search request. The
+// fields of each log line are taken from its _source, since log fields are
+// not stored separately.
+type searchRequest struct {
+	Source      []string               `json:"_source"`
+	Query       searchQuery            `json:"query"`
+	Sort        []map[string]sortOrder `json:"sort"`
+	SearchAfter []json.RawMessage      `json:"search_after,omitempty"`
+	Size        int                    `json:"size"`
+}
+
+type searchQuery struct {
+	Bool boolQuery `json:"bool"`
+}
+
+// boolQuery matches the text of the query and narrows the log lines down with
+// filters, which do not affect scoring
+type boolQuery struct {
+	Must   textQuery      `json:"must"`
+	Filter []filterClause `json:"filter"`
+}
+
+// textQuery is the text of the query of which only one field is set
+type textQuery struct {
+	MatchAll    *struct{}         `json:"match_all,omitempty"`
+	QueryString *queryStringQuery `json:"query_string,omitempty"`
+}
+
+type queryStringQuery struct {
+	Query           string `json:"query"`
+	DefaultField    string `json:"default_field"`
+	AnalyzeWildcard bool   `json:"analyze_wildcard"`
+}
+
+// filterClause is a single filter of which only one field is set
+type filterClause struct {
+	Term  map[string]string     `json:"term,omitempty"`
+	Terms map[string][]string   `json:"terms,omitempty"`
+	Range map[string]rangeBound `json:"range,omitempty"`
+}
+
+type rangeBound struct {
+	GT  string `json:"gt,omitempty"`
+	GTE string `json:"gte,omitempty"`
+	LTE string `json:"lte,omitempty"`
+}
+
+type sortOrder struct {
+	Order string `json:"order"`
+}
+
+// appLogsSource returns the field and value that identify application logs on
+// the pod the given domain belongs to
+func appLogsSource(domain string) (string, string) {
+	if strings.HasPrefix(domain, "pod01") || strings.HasPrefix(domain, "csb01") {
+		return "syslog_program", "supervisord"
+	}
+	return "source", "app"
+}
+
+// generateQuery builds the Elasticsearch search request for a page of logs
+// matching the query, starting after the cursor
+func generateQuery(query *Query, domain string, pageSize int, cursor *Cursor) ([]byte, error) {
+	sourceField, sourceValue := appLogsSource(domain)
+	if query.Source != "" {
+		sourceValue = query.Source
+	}
+	fields := []string{timestampField, messageField, sourceField}
+	for _, f := range query.Fields {
+		if !contains(fields, f) {
+			fields = append(fields, f)
+		}
+	}
+	req := searchRequest{
+		Source: fields,
+		Query: searchQuery{Bool: boolQuery{Filter: []filterClause{
+			{Term: map[string]string{sourceField: sourceValue}},
+		}}},
+		Sort: []map[string]sortOrder{
+			{timestampField: {Order: "asc"}},
+			{tiebreakerField: {Order: "asc"}},
+		},
+		SearchAfter: cursor.SearchAfter,
+		Size:        pageSize,
+	}
+	if text := strings.TrimSpace(query.Text); text == "" || text == "*" {
+		req.Query.Bool.Must.MatchAll = &struct{}{}
+	} else {
+		req.Query.Bool.Must.QueryString = &queryStringQuery{Query: text, DefaultField: messageField, AnalyzeWildcard: true}
+	}
+	if len(query.Hosts) > 0 {
+		req.Query.Bool.Filter = append(req.Query.Bool.Filter, filterClause{Terms: map[string][]string{hostField: query.Hosts}})
+	}
+	if len(query.Services) > 0 {
+		req.Query.Bool.Filter = append(req.Query.Bool.Filter, filterClause{Terms: map[string][]string{serviceField: query.Services}})
+	}
+	bound := rangeBound{GT: formatTimestamp(query.Since)}
+	if cursor.SearchAfter == nil && cursor.Timestamp != "" {
+		bound = rangeBound{GTE: cursor.Timestamp}
+	}
+	if !query.Until.IsZero() {
+		bound.LTE = formatTimestamp(query.Until)
+	}
+	req.Query.Bool.Filter = append(req.Query.Bool.Filter, filterClause{Range: map[string]rangeBound{timestampField: bound}})
+	return json.Marshal(req)
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// formatTimestamp formats a time the way log timestamps are compared
+func formatTimestamp(t time.Time) string {
+	return t.UTC().Format(time.RFC3339)
+}
+
+// timeRange returns the start and end of the logs to retrieve. The start is
+// either an absolute RFC3339 time given with --since or the given duration
+// before now, and the end is an absolute time given with --until or no end.
+func timeRange(since, until string, hours, minutes, seconds int, follow bool, now time.Time) (time.Time, time.Time, error) {
+	if hours < 0 || minutes < 0 || seconds < 0 {
+		return time.Time{}, time.Time{}, errors.New("--hours, --minutes, and --seconds can not be negative")
+	}
+	offset := time.Duration(hours)*time.Hour + time.Duration(minutes)*time.Minute + time.Duration(seconds)*time.Second
+	start := now.UTC().Add(-1 * offset)
+	var end time.Time
+	if since != "" {
+		if offset > 0 {
+			return time.Time{}, time.Time{}, errors.New("Specify either --since or --hours, --minutes, and --seconds but not both")
+		}
+		t, err := time.Parse(time.RFC3339, since)
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("Invalid --since time \"%s\". Times must be in the RFC3339 format, such as 2006-01-02T15:04:05Z", since)
+		}
+		start = t.UTC()
+	}
+	if until != "" {
+		if follow {
+			return time.Time{}, time.Time{}, errors.New("--until can not be used when following the logs")
+		}
+		t, err := time.Parse(time.RFC3339, until)
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("Invalid --until time \"%s\". Times must be in the RFC3339 format, such as 2006-01-02T15:04:05Z", until)
+		}
+		end = t.UTC()
+		if !end.After(start) {
+			return time.Time{}, time.Time{}, errors.New("--until must be after the start of the logs")
+		}
+	}
+	return start, end, nil
+}
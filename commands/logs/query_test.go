@@ -0,0 +1,114 @@
+package logs
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+var since = time.Date(2024, 3, 1, 12, 30, 0, 0, time.UTC)
+
+var generateQueryTests = []struct {
+	query        Query
+	domain       string
+	pageSize     int
+	cursor       Cursor
+	expectedJSON string
+}{
+	{Query{Text: "*", Since: since}, "pod02-ns.example.com", 50, Cursor{},
+		`{"_source":["@timestamp","message","source"],"query":{"bool":{"must":{"match_all":{}},"filter":[{"term":{"source":"app"}},{"range":{"@timestamp":{"gt":"2024-03-01T12:30:00Z"}}}]}},"sort":[{"@timestamp":{"order":"asc"}},{"_uid":{"order":"asc"}}],"size":50}`},
+	{Query{Text: "", Since: since}, "pod01-ns.example.com", 100, Cursor{SearchAfter: []json.RawMessage{json.RawMessage("1709296200000"), json.RawMessage(`"logs#log-0100"`)}, Timestamp: "2024-03-01T12:30:00.000Z"},
+		`{"_source":["@timestamp","message","syslog_program"],"query":{"bool":{"must":{"match_all":{}},"filter":[{"term":{"syslog_program":"supervisord"}},{"range":{"@timestamp":{"gt":"2024-03-01T12:30:00Z"}}}]}},"sort":[{"@timestamp":{"order":"asc"}},{"_uid":{"order":"asc"}}],"search_after":[1709296200000,"logs#log-0100"],"size":100}`},
+	{Query{Text: "error AND NOT timeout", Since: since, Until: since.Add(time.Hour)}, "pod02-ns.example.com", 50, Cursor{},
+		`{"_source":["@timestamp","message","source"],"query":{"bool":{"must":{"query_string":{"query":"error AND NOT timeout","default_field":"message","analyze_wildcard":true}},"filter":[{"term":{"source":"app"}},{"range":{"@timestamp":{"gt":"2024-03-01T12:30:00Z","lte":"2024-03-01T13:30:00Z"}}}]}},"sort":[{"@timestamp":{"order":"asc"}},{"_uid":{"order":"asc"}}],"size":50}`},
+	// quotes and JSON in the query can not break out of the query string
+	{Query{Text: `"}}, "size": 10000, "x": {"`, Since: since}, "pod02-ns.example.com", 50, Cursor{},
+		`{"_source":["@timestamp","message","source"],"query":{"bool":{"must":{"query_string":{"query":"\"}}, \"size\": 10000, \"x\": {\"","default_field":"message","analyze_wildcard":true}},"filter":[{"term":{"source":"app"}},{"range":{"@timestamp":{"gt":"2024-03-01T12:30:00Z"}}}]}},"sort":[{"@timestamp":{"order":"asc"}},{"_uid":{"order":"asc"}}],"size":50}`},
+	{Query{Text: "*", Source: `nginx"`, Hosts: []string{"web01", "web02"}, Services: []string{"code-1"}, Since: since.In(time.FixedZone("EST", -5*60*60))}, "pod02-ns.example.com", 50, Cursor{},
+		`{"_source":["@timestamp","message","source"],"query":{"bool":{"must":{"match_all":{}},"filter":[{"term":{"source":"nginx\""}},{"terms":{"host":["web01","web02"]}},{"terms":{"service":["code-1"]}},{"range":{"@timestamp":{"gt":"2024-03-01T12:30:00Z"}}}]}},"sort":[{"@timestamp":{"order":"asc"}},{"_uid":{"order":"asc"}}],"size":50}`},
+	// a rewound cursor starts at the timestamp of the last log line seen
+	{Query{Text: "*", Since: since}, "pod02-ns.example.com", 50, Cursor{Timestamp: "2024-03-01T12:45:00.123Z"},
+		`{"_source":["@timestamp","message","source"],"query":{"bool":{"must":{"match_all":{}},"filter":[{"term":{"source":"app"}},{"range":{"@timestamp":{"gte":"2024-03-01T12:45:00.123Z"}}}]}},"sort":[{"@timestamp":{"order":"asc"}},{"_uid":{"order":"asc"}}],"size":50}`},
+}
+
+func TestGenerateQuery(t *testing.T) {
+	for _, data := range generateQueryTests {
+		t.Logf("Data: %+v", data)
+		b, err := generateQuery(&data.query, data.domain, data.pageSize, &data.cursor)
+		if err != nil {
+			t.Errorf("Unexpected error: %s", err)
+			continue
+		}
+		if string(b) != data.expectedJSON {
+			t.Errorf("Expected: %s. Found: %s", data.expectedJSON, string(b))
+		}
+	}
+}
+
+// TestGenerateQueryShape checks every generated query against the search
+// requests Elasticsearch 5 and 6 accept rather than against the JSON of
+// searchRequest alone
+func TestGenerateQueryShape(t *testing.T) {
+	for _, data := range generateQueryTests {
+		t.Logf("Data: %+v", data)
+		b, err := generateQuery(&data.query, data.domain, data.pageSize, &data.cursor)
+		if err != nil {
+			t.Errorf("Unexpected error: %s", err)
+			continue
+		}
+		if err = validateSearch(b); err != nil {
+			t.Errorf("Elasticsearch would reject the query: %s\n%s", err, string(b))
+		}
+	}
+	for _, body := range []string{
+		`{"fields":["message"],"query":{"bool":{"must":{"match_all":{}}}},"sort":[],"size":1}`,
+		`{"query":{"match_all":{}},"filter":{"bool":{"must":[]}},"sort":[],"size":1}`,
+		`{"query":{"bool":{"must":{"match_all":{}}}},"sort":[{"_id":{"order":"asc"}}],"size":1}`,
+	} {
+		if validateSearch([]byte(body)) == nil {
+			t.Errorf("Expected an invalid query to be rejected: %s", body)
+		}
+	}
+}
+
+var now = time.Date(2024, 3, 2, 0, 0, 0, 0, time.UTC)
+
+var timeRangeTests = []struct {
+	since         string
+	until         string
+	hours         int
+	minutes       int
+	seconds       int
+	follow        bool
+	expectErr     bool
+	expectedStart time.Time
+	expectedEnd   time.Time
+}{
+	{"", "", 0, 0, 0, false, false, now, time.Time{}},
+	{"", "", 1, 30, 15, false, false, now.Add(-(90*time.Minute + 15*time.Second)), time.Time{}},
+	{"2024-03-01T12:30:00Z", "", 0, 0, 0, false, false, since, time.Time{}},
+	{"2024-03-01T07:30:00-05:00", "2024-03-01T13:30:00Z", 0, 0, 0, false, false, since, since.Add(time.Hour)},
+	{"", "2024-03-02T00:00:00Z", 0, 0, 0, false, true, time.Time{}, time.Time{}},
+	{"2024-03-01T12:30:00Z", "", 1, 0, 0, false, true, time.Time{}, time.Time{}},
+	{"2024-03-01", "", 0, 0, 0, false, true, time.Time{}, time.Time{}},
+	{"", "yesterday", 1, 0, 0, false, true, time.Time{}, time.Time{}},
+	{"", "2024-03-01T23:00:00Z", 2, 0, 0, true, true, time.Time{}, time.Time{}},
+	{"", "", -1, 0, 0, false, true, time.Time{}, time.Time{}},
+}
+
+func TestTimeRange(t *testing.T) {
+	for _, data := range timeRangeTests {
+		t.Logf("Data: %+v", data)
+		start, end, err := timeRange(data.since, data.until, data.hours, data.minutes, data.seconds, data.follow, now)
+		if err != nil != data.expectErr {
+			t.Errorf("Unexpected error: %v", err)
+			continue
+		}
+		if data.expectErr {
+			continue
+		}
+		if !start.Equal(data.expectedStart) || !end.Equal(data.expectedEnd) {
+			t.Errorf("Expected: %s - %s. Found: %s - %s", data.expectedStart, data.expectedEnd, start, end)
+		}
+	}
+}
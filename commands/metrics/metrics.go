@@ -8,6 +8,7 @@ import (
 
 	"github.com/Sirupsen/logrus"
 	"github.com/catalyzeio/cli/commands/services"
+	"github.com/catalyzeio/cli/lib/httpclient"
 	"github.com/catalyzeio/cli/models"
 	ui "github.com/gizak/termui"
 )
@@ -104,7 +105,7 @@ func CmdEnvironmentMetrics(metricType MetricType, stream, sparkLines bool, mins
 		for {
 			metrics, err := im.RetrieveEnvironmentMetrics(mins)
 			if err != nil {
-				logrus.Fatal(err.Error())
+				httpclient.Fatal(err)
 			}
 			switch metricType {
 			case CPU:
@@ -137,7 +138,7 @@ func CmdServiceMetrics(metricType MetricType, stream, sparkLines bool, mins int,
 		for {
 			metrics, err := im.RetrieveServiceMetrics(mins, service.ID)
 			if err != nil {
-				logrus.Fatal(err.Error())
+				httpclient.Fatal(err)
 			}
 			switch metricType {
 			case CPU:
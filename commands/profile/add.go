@@ -0,0 +1,27 @@
+package profile
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/catalyzeio/cli/config"
+	"github.com/catalyzeio/cli/models"
+)
+
+func CmdAdd(name string, profile models.Profile, ip IProfile) error {
+	if strings.ContainsAny(name, config.InvalidChars) {
+		return fmt.Errorf("Invalid profile name. Names must not contain the following characters: %s", config.InvalidChars)
+	}
+	err := ip.Add(name, profile)
+	if err != nil {
+		return err
+	}
+	logrus.Printf("Profile \"%s\" has been created. Run \"catalyze profile use %s\" to start using it", name, name)
+	return nil
+}
+
+// Add creates a new profile
+func (p *SProfile) Add(name string, profile models.Profile) error {
+	return config.AddProfile(name, profile, p.Settings)
+}
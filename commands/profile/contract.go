@@ -0,0 +1,140 @@
+package profile
+
+import (
+	"github.com/catalyzeio/cli/lib/httpclient"
+	"github.com/catalyzeio/cli/lib/render"
+	"github.com/catalyzeio/cli/models"
+	"github.com/jault3/mow.cli"
+)
+
+// Cmd is the contract between the user and the CLI. This specifies the command
+// name, arguments, and required/optional arguments and flags for the command.
+var Cmd = models.Command{
+	Name:      "profile",
+	ShortHelp: "Manage profiles for different accounts and API hosts",
+	LongHelp: "The `profile` command manages named profiles. " +
+		"Each profile has its own API hosts, session, private key, and associated environments so you can easily switch between accounts or deployments of the platform. " +
+		"A profile can be chosen for a single command with the global `--profile` option or the `CATALYZE_PROFILE` environment variable. " +
+		"Otherwise the active profile set with [profile use](#profile-use) is used. " +
+		"The profile command can not be run directly but has sub commands.",
+	CmdFunc: func(settings *models.Settings) func(cmd *cli.Cmd) {
+		return func(cmd *cli.Cmd) {
+			cmd.CommandLong(AddSubCmd.Name, AddSubCmd.ShortHelp, AddSubCmd.LongHelp, AddSubCmd.CmdFunc(settings))
+			cmd.CommandLong(ListSubCmd.Name, ListSubCmd.ShortHelp, ListSubCmd.LongHelp, ListSubCmd.CmdFunc(settings))
+			cmd.CommandLong(RmSubCmd.Name, RmSubCmd.ShortHelp, RmSubCmd.LongHelp, RmSubCmd.CmdFunc(settings))
+			cmd.CommandLong(UseSubCmd.Name, UseSubCmd.ShortHelp, UseSubCmd.LongHelp, UseSubCmd.CmdFunc(settings))
+		}
+	},
+}
+
+var AddSubCmd = models.Command{
+	Name:      "add",
+	ShortHelp: "Create a new profile",
+	LongHelp: "`profile add` creates a new profile. " +
+		"Any hosts that are not given use the default Catalyze hosts. " +
+		"Environment variables such as `PAAS_HOST` still take precedence over the hosts of a profile. " +
+		"The new profile is not used until it is chosen with [profile use](#profile-use) or the global `--profile` option. Here is a sample command\n\n" +
+		"```\ncatalyze profile add staging --auth-host https://auth.staging.example.com --paas-host https://paas-api.staging.example.com\n```",
+	CmdFunc: func(settings *models.Settings) func(cmd *cli.Cmd) {
+		return func(subCmd *cli.Cmd) {
+			name := subCmd.StringArg("NAME", "", "The name of the new profile")
+			accountsHost := subCmd.StringOpt("accounts-host", "", "The accounts host to use with this profile")
+			authHost := subCmd.StringOpt("auth-host", "", "The auth host to use with this profile")
+			paasHost := subCmd.StringOpt("paas-host", "", "The PaaS host to use with this profile")
+			authHostVersion := subCmd.StringOpt("auth-host-version", "", "The version of the auth API to use with this profile")
+			paasHostVersion := subCmd.StringOpt("paas-host-version", "", "The version of the PaaS API to use with this profile")
+			privateKey := subCmd.StringOpt("private-key", "", "The private key used to sign in with this profile")
+			subCmd.Action = func() {
+				p := models.Profile{
+					AccountsHost:    *accountsHost,
+					AuthHost:        *authHost,
+					PaasHost:        *paasHost,
+					AuthHostVersion: *authHostVersion,
+					PaasHostVersion: *paasHostVersion,
+					PrivateKeyPath:  *privateKey,
+				}
+				err := CmdAdd(*name, p, New(settings))
+				if err != nil {
+					httpclient.Fatal(err)
+				}
+			}
+			subCmd.Spec = "NAME [--accounts-host] [--auth-host] [--paas-host] [--auth-host-version] [--paas-host-version] [--private-key]"
+		}
+	},
+}
+
+var ListSubCmd = models.Command{
+	Name:      "list",
+	ShortHelp: "List all profiles",
+	LongHelp: "`profile list` lists all profiles along with their hosts. The active profile is marked with a `*`. Here is a sample command\n\n" +
+		"```\ncatalyze profile list\n```",
+	CmdFunc: func(settings *models.Settings) func(cmd *cli.Cmd) {
+		return func(subCmd *cli.Cmd) {
+			subCmd.Action = func() {
+				err := CmdList(New(settings), render.New(settings))
+				if err != nil {
+					httpclient.Fatal(err)
+				}
+			}
+		}
+	},
+}
+
+var RmSubCmd = models.Command{
+	Name:      "rm",
+	ShortHelp: "Remove a profile",
+	LongHelp: "`profile rm` removes a profile including its session and associated environments. " +
+		"The active profile can not be removed. Here is a sample command\n\n" +
+		"```\ncatalyze profile rm staging\n```",
+	CmdFunc: func(settings *models.Settings) func(cmd *cli.Cmd) {
+		return func(subCmd *cli.Cmd) {
+			name := subCmd.StringArg("NAME", "", "The name of the profile to remove")
+			subCmd.Action = func() {
+				err := CmdRm(*name, New(settings))
+				if err != nil {
+					httpclient.Fatal(err)
+				}
+			}
+			subCmd.Spec = "NAME"
+		}
+	},
+}
+
+var UseSubCmd = models.Command{
+	Name:      "use",
+	ShortHelp: "Set the active profile",
+	LongHelp: "`profile use` sets the profile used by all commands that do not specify the global `--profile` option. Here is a sample command\n\n" +
+		"```\ncatalyze profile use staging\n```",
+	CmdFunc: func(settings *models.Settings) func(cmd *cli.Cmd) {
+		return func(subCmd *cli.Cmd) {
+			name := subCmd.StringArg("NAME", "", "The name of the profile to use")
+			subCmd.Action = func() {
+				err := CmdUse(*name, New(settings))
+				if err != nil {
+					httpclient.Fatal(err)
+				}
+			}
+			subCmd.Spec = "NAME"
+		}
+	},
+}
+
+// IProfile
+type IProfile interface {
+	List() []models.NamedProfile
+	Add(name string, profile models.Profile) error
+	Use(name string) error
+	Rm(name string) error
+}
+
+// SProfile is a concrete implementation of IProfile
+type SProfile struct {
+	Settings *models.Settings
+}
+
+// New returns an instance of IProfile
+func New(settings *models.Settings) IProfile {
+	return &SProfile{
+		Settings: settings,
+	}
+}
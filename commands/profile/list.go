@@ -0,0 +1,58 @@
+package profile
+
+import (
+	"fmt"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/catalyzeio/cli/lib/render"
+	"github.com/catalyzeio/cli/models"
+	"github.com/olekukonko/tablewriter"
+	"github.com/pmylund/sortutil"
+)
+
+func CmdList(ip IProfile, ir render.IRender) error {
+	profiles := ip.List()
+	return ir.Render(profiles, func() error {
+		data := [][]string{{"NAME", "ACCOUNTS HOST", "AUTH HOST", "PAAS HOST"}}
+		for _, p := range profiles {
+			name := p.Name
+			if p.Active {
+				name = fmt.Sprintf("*%s", p.Name)
+			}
+			data = append(data, []string{name, hostOrDefault(p.AccountsHost), hostOrDefault(p.AuthHost), hostOrDefault(p.PaasHost)})
+		}
+
+		table := tablewriter.NewWriter(logrus.StandardLogger().Out)
+		table.SetBorder(false)
+		table.SetRowLine(false)
+		table.SetCenterSeparator("")
+		table.SetColumnSeparator("")
+		table.SetRowSeparator("")
+		table.AppendBulk(data)
+		table.Render()
+
+		logrus.Println("\n* denotes the active profile")
+		return nil
+	})
+}
+
+func hostOrDefault(host string) string {
+	if host == "" {
+		return "(default)"
+	}
+	return host
+}
+
+// List returns all profiles sorted by name
+func (p *SProfile) List() []models.NamedProfile {
+	profiles := []models.NamedProfile{}
+	for name, profile := range p.Settings.Profiles {
+		profiles = append(profiles, models.NamedProfile{
+			Name:    name,
+			Active:  name == p.Settings.ActiveProfile,
+			Profile: profile,
+		})
+	}
+	sortutil.AscByField(profiles, "Name")
+	return profiles
+}
@@ -0,0 +1,49 @@
+package profile
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/catalyzeio/cli/test"
+)
+
+const commandName = "profile"
+
+var profileTests = []struct {
+	args           []string
+	expectErr      bool
+	expectedOutput string
+}{
+	{[]string{commandName, "add", "staging", "--paas-host", "https://paas-api.staging.example.com"}, false, `Profile "staging" has been created`},
+	{[]string{commandName, "add", "staging"}, true, `A profile named "staging" already exists`},
+	{[]string{commandName, "list"}, false, `\*default\s+\(default\)\s+\(default\)\s+\(default\)\s+staging\s+\(default\)\s+\(default\)\s+https://paas-api.staging.example.com`},
+	{[]string{"--profile", "staging", "associated"}, false, "No environments have been associated"},
+	{[]string{"associated"}, false, test.Alias + ":"},
+	{[]string{"--profile", "missing", "associated"}, true, `No profile named "missing" exists`},
+	{[]string{commandName, "use", "staging"}, false, `Now using the "staging" profile`},
+	{[]string{commandName, "rm", "staging"}, true, `The profile "staging" is in use`},
+	{[]string{commandName, "use", "default"}, false, `Now using the "default" profile`},
+	{[]string{commandName, "rm", "staging"}, false, `Profile "staging" has been removed`},
+	{[]string{commandName, "use", "staging"}, true, `No profile named "staging" exists`},
+}
+
+func TestProfile(t *testing.T) {
+	if err := test.SetUpGitRepo(); err != nil {
+		t.Fatal(err)
+	}
+	if err := test.SetUpAssociation(); err != nil {
+		t.Fatal(err)
+	}
+	for _, data := range profileTests {
+		t.Logf("Data: %+v", data)
+		r := regexp.MustCompile(data.expectedOutput)
+		output, err := test.RunCommand(test.BinaryName, data.args)
+		if err != nil != data.expectErr {
+			t.Errorf("Unexpected error: %s", output)
+			continue
+		}
+		if !r.MatchString(output) {
+			t.Errorf("Expected: %s. Found: %s", data.expectedOutput, output)
+		}
+	}
+}
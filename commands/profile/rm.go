@@ -0,0 +1,20 @@
+package profile
+
+import (
+	"github.com/Sirupsen/logrus"
+	"github.com/catalyzeio/cli/config"
+)
+
+func CmdRm(name string, ip IProfile) error {
+	err := ip.Rm(name)
+	if err != nil {
+		return err
+	}
+	logrus.Printf("Profile \"%s\" has been removed", name)
+	return nil
+}
+
+// Rm removes a profile
+func (p *SProfile) Rm(name string) error {
+	return config.RemoveProfile(name, p.Settings)
+}
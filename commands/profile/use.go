@@ -0,0 +1,20 @@
+package profile
+
+import (
+	"github.com/Sirupsen/logrus"
+	"github.com/catalyzeio/cli/config"
+)
+
+func CmdUse(name string, ip IProfile) error {
+	err := ip.Use(name)
+	if err != nil {
+		return err
+	}
+	logrus.Printf("Now using the \"%s\" profile", name)
+	return nil
+}
+
+// Use sets the active profile
+func (p *SProfile) Use(name string) error {
+	return config.UseProfile(name, p.Settings)
+}
@@ -1,11 +1,12 @@
 package redeploy
 
 import (
-	"github.com/Sirupsen/logrus"
 	"github.com/catalyzeio/cli/commands/environments"
+	"github.com/catalyzeio/cli/commands/releases"
 	"github.com/catalyzeio/cli/commands/services"
 	"github.com/catalyzeio/cli/config"
 	"github.com/catalyzeio/cli/lib/auth"
+	"github.com/catalyzeio/cli/lib/httpclient"
 	"github.com/catalyzeio/cli/lib/jobs"
 	"github.com/catalyzeio/cli/lib/prompts"
 	"github.com/catalyzeio/cli/models"
@@ -23,24 +24,31 @@ var Cmd = models.Command{
 		"All other service types cannot be redeployed with this command. " +
 		"For service proxy redeploys, there will be approximately 5 minutes of downtime. " +
 		"For code service redeploys, there will be approximately 30 seconds of downtime. " +
-		"Here is a sample command\n\n" +
-		"```\ncatalyze -E \"<your_env_alias>\" redeploy app01\n```",
+		"Add `--wait` to wait until the new deploy job is running and exit with an error if it fails or does not start within `--timeout` seconds. " +
+		"For code services, add `--auto-rollback` to also deploy the release the service was running before if the new deploy job fails or does not start in time. " +
+		"Here are some sample commands\n\n" +
+		"```\ncatalyze -E \"<your_env_alias>\" redeploy app01\n" +
+		"catalyze -E \"<your_env_alias>\" redeploy app01 --wait --timeout 300\n" +
+		"catalyze -E \"<your_env_alias>\" redeploy app01 --auto-rollback\n```",
 	CmdFunc: func(settings *models.Settings) func(cmd *cli.Cmd) {
 		return func(cmd *cli.Cmd) {
 			serviceName := cmd.StringArg("SERVICE_NAME", "", "The name of the service to redeploy (i.e. 'app01')")
+			wait := cmd.BoolOpt("wait", false, "Wait until the new deploy job is running and exit with an error if it fails")
+			autoRollback := cmd.BoolOpt("auto-rollback", false, "Wait for the new deploy job like --wait and deploy the previously running release if it fails")
+			timeout := cmd.IntOpt("timeout", config.DeployWaitTimeout, "The number of seconds to wait for the deploy job to be running, or 0 to wait indefinitely")
 			cmd.Action = func() {
-				if _, err := auth.New(settings, prompts.New()).Signin(); err != nil {
-					logrus.Fatal(err.Error())
+				if _, err := auth.New(settings, prompts.New(settings)).Signin(); err != nil {
+					httpclient.Fatal(err)
 				}
 				if err := config.CheckRequiredAssociation(true, true, settings); err != nil {
-					logrus.Fatal(err.Error())
+					httpclient.Fatal(err)
 				}
-				err := CmdRedeploy(settings.EnvironmentID, *serviceName, jobs.New(settings), services.New(settings), environments.New(settings))
+				err := CmdRedeploy(settings.EnvironmentID, *serviceName, *wait, *autoRollback, *timeout, jobs.New(settings), services.New(settings), environments.New(settings), releases.New(settings))
 				if err != nil {
-					logrus.Fatal(err.Error())
+					httpclient.Fatal(err)
 				}
 			}
-			cmd.Spec = "SERVICE_NAME"
+			cmd.Spec = "SERVICE_NAME [--wait] [--auto-rollback] [--timeout]"
 		}
 	},
 }
@@ -2,14 +2,25 @@ package redeploy
 
 import (
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/Sirupsen/logrus"
 	"github.com/catalyzeio/cli/commands/environments"
+	"github.com/catalyzeio/cli/commands/releases"
+	"github.com/catalyzeio/cli/commands/rollback"
 	"github.com/catalyzeio/cli/commands/services"
 	"github.com/catalyzeio/cli/lib/jobs"
+	"github.com/catalyzeio/cli/models"
 )
 
-func CmdRedeploy(envID, svcName string, ij jobs.IJobs, is services.IServices, ie environments.IEnvironments) error {
+// CmdRedeploy redeploys a service. With autoRollback the release the service
+// is currently running is recorded first and deployed again if the new deploy
+// job does not reach running.
+func CmdRedeploy(envID, svcName string, wait, autoRollback bool, timeout int, ij jobs.IJobs, is services.IServices, ie environments.IEnvironments, irs releases.IReleases) error {
+	if timeout < 0 {
+		return fmt.Errorf("--timeout can not be negative")
+	}
 	env, err := ie.Retrieve(envID)
 	if err != nil {
 		return err
@@ -21,7 +32,38 @@ func CmdRedeploy(envID, svcName string, ij jobs.IJobs, is services.IServices, ie
 	if service == nil {
 		return fmt.Errorf("Could not find a service with the label \"%s\". You can list services with the \"catalyze services\" command.", svcName)
 	}
+	previous := service.ReleaseVersion
+	if autoRollback {
+		if previous == "" {
+			return fmt.Errorf("%s is not running a release so it can not be automatically rolled back. Run the command again without --auto-rollback", svcName)
+		}
+		logrus.Printf("%s is currently running release %s", svcName, previous)
+	}
 	logrus.Printf("Redeploying service %s (ID = %s) in environment %s (ID = %s)", svcName, service.ID, env.Name, env.ID)
+	if wait || autoRollback {
+		deployed := false
+		logrus.Println("Waiting for the deploy job to be running...")
+		job, err := ij.WaitForDeploy("deploy", "", service.ID, time.Duration(timeout)*time.Second, func() error {
+			err := ij.Redeploy(service.ID)
+			deployed = err == nil
+			return err
+		})
+		if err != nil {
+			var timedOut *models.Job
+			if job != nil {
+				if jobs.IsTimeout(err) {
+					timedOut = job
+				}
+				err = fmt.Errorf("Redeploy of %s failed. %s. View the job with \"catalyze jobs show %s %s\" and its logs in your logging dashboard", svcName, strings.TrimSuffix(err.Error(), "."), job.ID, svcName)
+			}
+			if autoRollback && deployed {
+				return rollback.AutoRollback(svcName, service.ID, previous, timeout, timedOut, err, ij, irs, is)
+			}
+			return err
+		}
+		logrus.Printf("Redeploy successful! The deploy job %s for %s is running", job.ID, svcName)
+		return nil
+	}
 	err = ij.Redeploy(service.ID)
 	if err != nil {
 		return err
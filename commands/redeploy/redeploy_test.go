@@ -0,0 +1,130 @@
+package redeploy
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"testing"
+
+	"github.com/catalyzeio/cli/models"
+	"github.com/catalyzeio/cli/test"
+)
+
+const redeployCommandName = "redeploy"
+
+func TestRedeployWait(t *testing.T) {
+	if err := test.SetUpGitRepo(); err != nil {
+		t.Fatal(err)
+	}
+	if err := test.SetUpAssociation(); err != nil {
+		t.Fatal(err)
+	}
+	deployPath := fmt.Sprintf("/environments/%s/services/%s/deploy", test.EnvID, test.CodeSvcID)
+	defer test.Server.Override("POST", deployPath, nil)
+	deployWithStatus := func(status string) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			job := test.Server.AddJob(test.CodeSvcID, models.Job{Type: "deploy", Status: status})
+			json.NewEncoder(w).Encode(job)
+		}
+	}
+
+	var redeployTests = []struct {
+		args           []string
+		deployStatus   string
+		expectErr      bool
+		expectedOutput string
+	}{
+		{[]string{test.SvcLabel}, "", false, "Redeploy successful! Check the status"},
+		{[]string{test.SvcLabel, "--wait"}, "", false, `Redeploy successful! The deploy job \S+ for code-1 is running`},
+		{[]string{test.SvcLabel, "--wait"}, "failed", true, `Redeploy of code-1 failed. Error - ended in status 'failed'.*catalyze jobs show \S+ code-1`},
+		{[]string{test.SvcLabel, "--wait", "--timeout", "1"}, "queued", true, `Timed out waiting for job \S+ which is still in status 'queued'`},
+		{[]string{test.SvcLabel, "--wait", "--timeout=-1"}, "", true, "--timeout can not be negative"},
+	}
+	for _, data := range redeployTests {
+		t.Logf("Data: %+v", data)
+		if data.deployStatus != "" {
+			test.Server.Override("POST", deployPath, deployWithStatus(data.deployStatus))
+		} else {
+			test.Server.Override("POST", deployPath, nil)
+		}
+		args := append([]string{"-E", test.Alias, redeployCommandName}, data.args...)
+		output, err := test.RunCommand(test.BinaryName, args)
+		if err != nil != data.expectErr {
+			t.Errorf("Unexpected error: %s", output)
+			continue
+		}
+		if !regexp.MustCompile(data.expectedOutput).MatchString(output) {
+			t.Errorf("Expected: %s. Found: %s", data.expectedOutput, output)
+		}
+	}
+}
+
+// deployFailing returns a deploy handler that starts a failed deploy job for
+// redeploys and for the given releases and a running one otherwise
+func deployFailing(failedReleases ...string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		release := r.URL.Query().Get("release")
+		status := "running"
+		if release == "" {
+			status = "failed"
+		}
+		for _, failed := range failedReleases {
+			if release == failed {
+				status = "failed"
+			}
+		}
+		if status == "running" {
+			setReleaseVersion(release)
+		}
+		job := test.Server.AddJob(test.CodeSvcID, models.Job{Type: "deploy", Status: status})
+		json.NewEncoder(w).Encode(job)
+	}
+}
+
+func setReleaseVersion(release string) {
+	for i, svc := range test.Server.Services[test.EnvID] {
+		if svc.ID == test.CodeSvcID {
+			test.Server.Services[test.EnvID][i].ReleaseVersion = release
+		}
+	}
+}
+
+func TestRedeployAutoRollback(t *testing.T) {
+	if err := test.SetUpGitRepo(); err != nil {
+		t.Fatal(err)
+	}
+	if err := test.SetUpAssociation(); err != nil {
+		t.Fatal(err)
+	}
+	test.Server.Releases[test.CodeSvcID] = []models.Release{{Name: "v1"}, {Name: "v2"}}
+	defer func() { test.Server.Releases[test.CodeSvcID] = nil }()
+	defer setReleaseVersion("")
+	deployPath := fmt.Sprintf("/environments/%s/services/%s/deploy", test.EnvID, test.CodeSvcID)
+	defer test.Server.Override("POST", deployPath, nil)
+
+	var autoRollbackTests = []struct {
+		releaseVersion string
+		handler        http.HandlerFunc
+		expectErr      bool
+		expectedOutput string
+	}{
+		{"v1", nil, false, `(?s)code-1 is currently running release v1.*Redeploy successful! The deploy job \S+ for code-1 is running`},
+		{"v1", deployFailing(), true, `(?s)code-1 is currently running release v1.*Redeploy of code-1 failed. Error - ended in status 'failed'.*Automatically rolling back code-1 to the previous release v1.*Rollback successful! The deploy job \S+ for code-1 is running release v1.*Redeploy of code-1 failed.*code-1 was automatically rolled back to the previous release v1`},
+		{"v1", deployFailing("v1"), true, `The automatic rollback to the previous release v1 also failed: Rollback of code-1 to v1 failed`},
+		{"", nil, true, `code-1 is not running a release so it can not be automatically rolled back`},
+	}
+	for _, data := range autoRollbackTests {
+		t.Logf("Data: %+v", data)
+		setReleaseVersion(data.releaseVersion)
+		test.Server.Override("POST", deployPath, data.handler)
+		output, err := test.RunCommand(test.BinaryName, []string{"-E", test.Alias, redeployCommandName, test.SvcLabel, "--auto-rollback"})
+		if err != nil != data.expectErr {
+			t.Errorf("Unexpected error: %s", output)
+			continue
+		}
+		if !regexp.MustCompile(data.expectedOutput).MatchString(output) {
+			t.Errorf("Expected: %s. Found: %s", data.expectedOutput, output)
+		}
+	}
+}
@@ -1,11 +1,12 @@
 package releases
 
 import (
-	"github.com/Sirupsen/logrus"
 	"github.com/catalyzeio/cli/commands/services"
 	"github.com/catalyzeio/cli/config"
 	"github.com/catalyzeio/cli/lib/auth"
+	"github.com/catalyzeio/cli/lib/httpclient"
 	"github.com/catalyzeio/cli/lib/prompts"
+	"github.com/catalyzeio/cli/lib/render"
 	"github.com/catalyzeio/cli/models"
 	"github.com/jault3/mow.cli"
 )
@@ -40,15 +41,15 @@ var ListSubCmd = models.Command{
 		return func(cmd *cli.Cmd) {
 			serviceName := cmd.StringArg("SERVICE_NAME", "", "The name of the service to list releases for")
 			cmd.Action = func() {
-				if _, err := auth.New(settings, prompts.New()).Signin(); err != nil {
-					logrus.Fatal(err.Error())
+				if _, err := auth.New(settings, prompts.New(settings)).Signin(); err != nil {
+					httpclient.Fatal(err)
 				}
 				if err := config.CheckRequiredAssociation(true, true, settings); err != nil {
-					logrus.Fatal(err.Error())
+					httpclient.Fatal(err)
 				}
-				err := CmdList(*serviceName, New(settings), services.New(settings))
+				err := CmdList(*serviceName, New(settings), services.New(settings), render.New(settings))
 				if err != nil {
-					logrus.Fatal(err)
+					httpclient.Fatal(err)
 				}
 			}
 		}
@@ -66,15 +67,15 @@ var RmSubCmd = models.Command{
 			serviceName := cmd.StringArg("SERVICE_NAME", "", "The name of the service to remove a release from")
 			releaseName := cmd.StringArg("RELEASE_NAME", "", "The name of the release to remove")
 			cmd.Action = func() {
-				if _, err := auth.New(settings, prompts.New()).Signin(); err != nil {
-					logrus.Fatal(err.Error())
+				if _, err := auth.New(settings, prompts.New(settings)).Signin(); err != nil {
+					httpclient.Fatal(err)
 				}
 				if err := config.CheckRequiredAssociation(true, true, settings); err != nil {
-					logrus.Fatal(err.Error())
+					httpclient.Fatal(err)
 				}
 				err := CmdRm(*serviceName, *releaseName, New(settings), services.New(settings))
 				if err != nil {
-					logrus.Fatal(err)
+					httpclient.Fatal(err)
 				}
 			}
 		}
@@ -95,15 +96,15 @@ var UpdateSubCmd = models.Command{
 			notes := cmd.StringOpt("n notes", "", "The new notes to save on the release. If omitted, notes will be unchanged.")
 			newReleaseName := cmd.StringOpt("r release", "", "The new name of the release. If omitted, the release name will be unchanged.")
 			cmd.Action = func() {
-				if _, err := auth.New(settings, prompts.New()).Signin(); err != nil {
-					logrus.Fatal(err.Error())
+				if _, err := auth.New(settings, prompts.New(settings)).Signin(); err != nil {
+					httpclient.Fatal(err)
 				}
 				if err := config.CheckRequiredAssociation(true, true, settings); err != nil {
-					logrus.Fatal(err.Error())
+					httpclient.Fatal(err)
 				}
 				err := CmdUpdate(*serviceName, *releaseName, *notes, *newReleaseName, New(settings), services.New(settings))
 				if err != nil {
-					logrus.Fatal(err)
+					httpclient.Fatal(err)
 				}
 			}
 			cmd.Spec = "SERVICE_NAME RELEASE_NAME [--notes] [--release]"
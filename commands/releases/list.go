@@ -7,6 +7,7 @@ import (
 
 	"github.com/Sirupsen/logrus"
 	"github.com/catalyzeio/cli/commands/services"
+	"github.com/catalyzeio/cli/lib/render"
 	"github.com/catalyzeio/cli/models"
 	"github.com/olekukonko/tablewriter"
 )
@@ -26,7 +27,7 @@ func (rls SortedReleases) Less(i, j int) bool {
 	return rls[i].CreatedAt > rls[j].CreatedAt
 }
 
-func CmdList(svcName string, ir IReleases, is services.IServices) error {
+func CmdList(svcName string, ir IReleases, is services.IServices, irender render.IRender) error {
 	service, err := is.RetrieveByLabel(svcName)
 	if err != nil {
 		return err
@@ -40,34 +41,38 @@ func CmdList(svcName string, ir IReleases, is services.IServices) error {
 		return err
 	}
 
-	if rls == nil || len(*rls) == 0 {
-		logrus.Println("No releases found")
-		return nil
+	if rls == nil {
+		rls = &[]models.Release{}
 	}
-
 	sort.Sort(SortedReleases(*rls))
-	const dateForm = "2006-01-02T15:04:05"
-	data := [][]string{{"Release Name", "Created At", "Notes"}}
-	for _, r := range *rls {
-		name := r.Name
-		if r.Name == service.ReleaseVersion {
-			name = fmt.Sprintf("*%s", r.Name)
+	return irender.Render(rls, func() error {
+		if len(*rls) == 0 {
+			logrus.Println("No releases found")
+			return nil
+		}
+		const dateForm = "2006-01-02T15:04:05"
+		data := [][]string{{"Release Name", "Created At", "Notes"}}
+		for _, r := range *rls {
+			name := r.Name
+			if r.Name == service.ReleaseVersion {
+				name = fmt.Sprintf("*%s", r.Name)
+			}
+			t, _ := time.Parse(dateForm, r.CreatedAt)
+			data = append(data, []string{name, t.Local().Format(time.Stamp), r.Notes})
 		}
-		t, _ := time.Parse(dateForm, r.CreatedAt)
-		data = append(data, []string{name, t.Local().Format(time.Stamp), r.Notes})
-	}
 
-	table := tablewriter.NewWriter(logrus.StandardLogger().Out)
-	table.SetBorder(false)
-	table.SetRowLine(false)
-	table.SetCenterSeparator("")
-	table.SetColumnSeparator("")
-	table.SetRowSeparator("")
-	table.AppendBulk(data)
-	table.Render()
+		table := tablewriter.NewWriter(logrus.StandardLogger().Out)
+		table.SetBorder(false)
+		table.SetRowLine(false)
+		table.SetCenterSeparator("")
+		table.SetColumnSeparator("")
+		table.SetRowSeparator("")
+		table.AppendBulk(data)
+		table.Render()
 
-	logrus.Println("\n* denotes the current release")
-	return nil
+		logrus.Println("\n* denotes the current release")
+		return nil
+	})
 }
 
 func (r *SReleases) List(svcID string) (*[]models.Release, error) {
@@ -1,11 +1,11 @@
 package rollback
 
 import (
-	"github.com/Sirupsen/logrus"
 	"github.com/catalyzeio/cli/commands/releases"
 	"github.com/catalyzeio/cli/commands/services"
 	"github.com/catalyzeio/cli/config"
 	"github.com/catalyzeio/cli/lib/auth"
+	"github.com/catalyzeio/cli/lib/httpclient"
 	"github.com/catalyzeio/cli/lib/jobs"
 	"github.com/catalyzeio/cli/lib/prompts"
 	"github.com/catalyzeio/cli/models"
@@ -19,25 +19,32 @@ var Cmd = models.Command{
 	ShortHelp: "Rollback a code service to a specific release",
 	LongHelp: "`rollback` is a way to redeploy older versions of your code service. " +
 		"You must specify the name of the service to rollback and the name of an existing release to rollback to. " +
-		"Releases can be found with the [releases list](#releases-list) command. Here are some sample commands\n\n" +
-		"```\ncatalyze -E \"<your_env_alias>\" rollback code-1 f93ced037f828dcaabccfc825e6d8d32cc5a1883\n```",
+		"Releases can be found with the [releases list](#releases-list) command. " +
+		"Add `--wait` to wait until the new deploy job is running and exit with an error if it fails or does not start within `--timeout` seconds. " +
+		"Add `--auto-rollback` to also deploy the release the service was running before if the new deploy job fails or does not start in time. " +
+		"Here are some sample commands\n\n" +
+		"```\ncatalyze -E \"<your_env_alias>\" rollback code-1 f93ced037f828dcaabccfc825e6d8d32cc5a1883\n" +
+		"catalyze -E \"<your_env_alias>\" rollback code-1 f93ced037f828dcaabccfc825e6d8d32cc5a1883 --auto-rollback --timeout 300\n```",
 	CmdFunc: func(settings *models.Settings) func(cmd *cli.Cmd) {
 		return func(cmd *cli.Cmd) {
 			serviceName := cmd.StringArg("SERVICE_NAME", "", "The name of the service to rollback")
 			releaseName := cmd.StringArg("RELEASE_NAME", "", "The name of the release to rollback to")
+			wait := cmd.BoolOpt("wait", false, "Wait until the new deploy job is running and exit with an error if it fails")
+			autoRollback := cmd.BoolOpt("auto-rollback", false, "Wait for the new deploy job like --wait and deploy the previously running release if it fails")
+			timeout := cmd.IntOpt("timeout", config.DeployWaitTimeout, "The number of seconds to wait for the deploy job to be running, or 0 to wait indefinitely")
 			cmd.Action = func() {
-				if _, err := auth.New(settings, prompts.New()).Signin(); err != nil {
-					logrus.Fatal(err.Error())
+				if _, err := auth.New(settings, prompts.New(settings)).Signin(); err != nil {
+					httpclient.Fatal(err)
 				}
 				if err := config.CheckRequiredAssociation(true, true, settings); err != nil {
-					logrus.Fatal(err.Error())
+					httpclient.Fatal(err)
 				}
-				err := CmdRollback(*serviceName, *releaseName, jobs.New(settings), releases.New(settings), services.New(settings))
+				err := CmdRollback(*serviceName, *releaseName, *wait, *autoRollback, *timeout, jobs.New(settings), releases.New(settings), services.New(settings))
 				if err != nil {
-					logrus.Fatal(err.Error())
+					httpclient.Fatal(err)
 				}
 			}
-			cmd.Spec = "SERVICE_NAME RELEASE_NAME"
+			cmd.Spec = "SERVICE_NAME RELEASE_NAME [--wait] [--auto-rollback] [--timeout]"
 		}
 	},
 }
@@ -3,15 +3,23 @@ package rollback
 import (
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/Sirupsen/logrus"
 	"github.com/catalyzeio/cli/commands/releases"
 	"github.com/catalyzeio/cli/commands/services"
 	"github.com/catalyzeio/cli/config"
 	"github.com/catalyzeio/cli/lib/jobs"
+	"github.com/catalyzeio/cli/models"
 )
 
-func CmdRollback(svcName, releaseName string, ij jobs.IJobs, irs releases.IReleases, is services.IServices) error {
+// CmdRollback deploys an existing release of a code service. With
+// autoRollback the release the service is currently running is recorded first
+// and deployed again if the new deploy job does not reach running.
+func CmdRollback(svcName, releaseName string, wait, autoRollback bool, timeout int, ij jobs.IJobs, irs releases.IReleases, is services.IServices) error {
+	if timeout < 0 {
+		return fmt.Errorf("--timeout can not be negative")
+	}
 	if strings.ContainsAny(releaseName, config.InvalidChars) {
 		return fmt.Errorf("Invalid release name. Names must not contain the following characters: %s", config.InvalidChars)
 	}
@@ -22,6 +30,13 @@ func CmdRollback(svcName, releaseName string, ij jobs.IJobs, irs releases.IRelea
 	if service == nil {
 		return fmt.Errorf("Could not find a service with the label \"%s\". You can list services with the \"catalyze services\" command.", svcName)
 	}
+	previous := service.ReleaseVersion
+	if autoRollback {
+		if previous == "" {
+			return fmt.Errorf("%s is not running a release so it can not be automatically rolled back. Run the command again without --auto-rollback", svcName)
+		}
+		logrus.Printf("%s is currently running release %s", svcName, previous)
+	}
 	logrus.Printf("Rolling back %s to %s", svcName, releaseName)
 	release, err := irs.Retrieve(releaseName, service.ID)
 	if err != nil {
@@ -30,6 +45,30 @@ func CmdRollback(svcName, releaseName string, ij jobs.IJobs, irs releases.IRelea
 	if release == nil {
 		return fmt.Errorf("Could not find a release with the name \"%s\". You can list releases for this code service with the \"catalyze releases list %s\" command.", releaseName, svcName)
 	}
+	if wait || autoRollback {
+		deployed := false
+		logrus.Println("Waiting for the deploy job to be running...")
+		job, err := ij.WaitForDeploy("deploy", "", service.ID, time.Duration(timeout)*time.Second, func() error {
+			err := ij.DeployRelease(releaseName, service.ID)
+			deployed = err == nil
+			return err
+		})
+		if err != nil {
+			var timedOut *models.Job
+			if job != nil {
+				if jobs.IsTimeout(err) {
+					timedOut = job
+				}
+				err = fmt.Errorf("Rollback of %s to %s failed. %s. View the job with \"catalyze jobs show %s %s\" and its logs in your logging dashboard", svcName, releaseName, strings.TrimSuffix(err.Error(), "."), job.ID, svcName)
+			}
+			if autoRollback && deployed {
+				return AutoRollback(svcName, service.ID, previous, timeout, timedOut, err, ij, irs, is)
+			}
+			return err
+		}
+		logrus.Printf("Rollback successful! The deploy job %s for %s is running release %s", job.ID, svcName, releaseName)
+		return nil
+	}
 	err = ij.DeployRelease(releaseName, service.ID)
 	if err != nil {
 		return err
@@ -37,3 +76,23 @@ func CmdRollback(svcName, releaseName string, ij jobs.IJobs, irs releases.IRelea
 	logrus.Println("Rollback successful! Check the status with \"catalyze status\" and your logging dashboard for updates.")
 	return nil
 }
+
+// AutoRollback deploys the release a service was running before a deploy that
+// failed with deployErr and waits for it to be running. A deploy job that was
+// still pending when the wait timed out is given as timedOut and is deleted
+// first so that it can not start after the rollback. The returned error
+// reports the failed deploy along with the outcome of the rollback.
+func AutoRollback(svcName, svcID, previous string, timeout int, timedOut *models.Job, deployErr error, ij jobs.IJobs, irs releases.IReleases, is services.IServices) error {
+	logrus.Printf("%s", deployErr)
+	if timedOut != nil {
+		logrus.Printf("Deleting the timed out deploy job %s", timedOut.ID)
+		if err := ij.Delete(timedOut.ID, svcID); err != nil {
+			return fmt.Errorf("%s. %s was not rolled back because the timed out deploy job %s could not be deleted: %s", strings.TrimSuffix(deployErr.Error(), "."), svcName, timedOut.ID, err)
+		}
+	}
+	logrus.Printf("Automatically rolling back %s to the previous release %s", svcName, previous)
+	if err := CmdRollback(svcName, previous, true, false, timeout, ij, irs, is); err != nil {
+		return fmt.Errorf("%s. The automatic rollback to the previous release %s also failed: %s", strings.TrimSuffix(deployErr.Error(), "."), previous, err)
+	}
+	return fmt.Errorf("%s. %s was automatically rolled back to the previous release %s", strings.TrimSuffix(deployErr.Error(), "."), svcName, previous)
+}
@@ -0,0 +1,77 @@
+package rollback
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"testing"
+
+	"github.com/catalyzeio/cli/models"
+	"github.com/catalyzeio/cli/test"
+)
+
+const rollbackCommandName = "rollback"
+
+func setReleaseVersion(release string) {
+	for i, svc := range test.Server.Services[test.EnvID] {
+		if svc.ID == test.CodeSvcID {
+			test.Server.Services[test.EnvID][i].ReleaseVersion = release
+		}
+	}
+}
+
+func TestRollbackAutoRollback(t *testing.T) {
+	if err := test.SetUpGitRepo(); err != nil {
+		t.Fatal(err)
+	}
+	if err := test.SetUpAssociation(); err != nil {
+		t.Fatal(err)
+	}
+	test.Server.Releases[test.CodeSvcID] = []models.Release{{Name: "v1"}, {Name: "v2"}, {Name: "v3"}, {Name: "v4"}}
+	defer func() { test.Server.Releases[test.CodeSvcID] = nil }()
+	defer setReleaseVersion("")
+	deployPath := fmt.Sprintf("/environments/%s/services/%s/deploy", test.EnvID, test.CodeSvcID)
+	defer test.Server.Override("POST", deployPath, nil)
+	// v3 never starts and v4 stays queued while every other release deploys
+	// successfully
+	test.Server.Override("POST", deployPath, func(w http.ResponseWriter, r *http.Request) {
+		release := r.URL.Query().Get("release")
+		status := "running"
+		switch release {
+		case "v3":
+			status = "failed"
+		case "v4":
+			status = "queued"
+		default:
+			setReleaseVersion(release)
+		}
+		job := test.Server.AddJob(test.CodeSvcID, models.Job{Type: "deploy", Status: status})
+		json.NewEncoder(w).Encode(job)
+	})
+
+	var rollbackTests = []struct {
+		releaseVersion string
+		release        string
+		timeout        string
+		expectErr      bool
+		expectedOutput string
+	}{
+		{"v1", "v2", "0", false, `(?s)code-1 is currently running release v1.*Rollback successful! The deploy job \S+ for code-1 is running release v2`},
+		{"v2", "v3", "0", true, `(?s)Rollback of code-1 to v3 failed. Error - ended in status 'failed'.*Automatically rolling back code-1 to the previous release v2.*Rollback successful! The deploy job \S+ for code-1 is running release v2.*code-1 was automatically rolled back to the previous release v2`},
+		{"v2", "v4", "1", true, `(?s)Timed out waiting for job (\S+) which is still in status 'queued'.*Deleting the timed out deploy job \S+.*Rollback successful! The deploy job \S+ for code-1 is running release v2.*code-1 was automatically rolled back to the previous release v2`},
+		{"", "v2", "0", true, "code-1 is not running a release so it can not be automatically rolled back"},
+	}
+	for _, data := range rollbackTests {
+		t.Logf("Data: %+v", data)
+		setReleaseVersion(data.releaseVersion)
+		output, err := test.RunCommand(test.BinaryName, []string{"-E", test.Alias, rollbackCommandName, test.SvcLabel, data.release, "--auto-rollback", "--timeout", data.timeout})
+		if err != nil != data.expectErr {
+			t.Errorf("Unexpected error: %s", output)
+			continue
+		}
+		if !regexp.MustCompile(data.expectedOutput).MatchString(output) {
+			t.Errorf("Expected: %s. Found: %s", data.expectedOutput, output)
+		}
+	}
+}
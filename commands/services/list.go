@@ -4,34 +4,40 @@ import (
 	"fmt"
 
 	"github.com/Sirupsen/logrus"
+	"github.com/catalyzeio/cli/lib/render"
 	"github.com/catalyzeio/cli/models"
 	"github.com/olekukonko/tablewriter"
 )
 
 // CmdServices lists the names of all services for an environment.
-func CmdServices(is IServices) error {
+func CmdServices(is IServices, ir render.IRender) error {
 	svcs, err := is.List()
 	if err != nil {
 		return err
 	}
-	if svcs == nil || len(*svcs) == 0 {
-		logrus.Println("No services found")
-		return nil
-	}
-	data := [][]string{{"NAME", "DNS", "RAM (GB)", "CPU", "STORAGE (GB)", "WORKER LIMIT"}}
-	for _, s := range *svcs {
-		data = append(data, []string{s.Label, s.DNS, fmt.Sprintf("%d", s.Size.RAM), fmt.Sprintf("%d", s.Size.CPU), fmt.Sprintf("%d", s.Size.Storage), fmt.Sprintf("%d", s.WorkerScale)})
+	if svcs == nil {
+		svcs = &[]models.Service{}
 	}
+	return ir.Render(svcs, func() error {
+		if len(*svcs) == 0 {
+			logrus.Println("No services found")
+			return nil
+		}
+		data := [][]string{{"NAME", "DNS", "RAM (GB)", "CPU", "STORAGE (GB)", "WORKER LIMIT"}}
+		for _, s := range *svcs {
+			data = append(data, []string{s.Label, s.DNS, fmt.Sprintf("%d", s.Size.RAM), fmt.Sprintf("%d", s.Size.CPU), fmt.Sprintf("%d", s.Size.Storage), fmt.Sprintf("%d", s.WorkerScale)})
+		}
 
-	table := tablewriter.NewWriter(logrus.StandardLogger().Out)
-	table.SetBorder(false)
-	table.SetRowLine(false)
-	table.SetCenterSeparator("")
-	table.SetColumnSeparator("")
-	table.SetRowSeparator("")
-	table.AppendBulk(data)
-	table.Render()
-	return nil
+		table := tablewriter.NewWriter(logrus.StandardLogger().Out)
+		table.SetBorder(false)
+		table.SetRowLine(false)
+		table.SetCenterSeparator("")
+		table.SetColumnSeparator("")
+		table.SetRowSeparator("")
+		table.AppendBulk(data)
+		table.Render()
+		return nil
+	})
 }
 
 func (s *SServices) List() (*[]models.Service, error) {
@@ -5,11 +5,12 @@ import (
 
 	"github.com/Sirupsen/logrus"
 	"github.com/catalyzeio/cli/commands/services"
+	"github.com/catalyzeio/cli/lib/render"
 	"github.com/catalyzeio/cli/models"
 	"github.com/olekukonko/tablewriter"
 )
 
-func CmdList(is ISites, iservices services.IServices) error {
+func CmdList(is ISites, iservices services.IServices, ir render.IRender) error {
 	serviceProxy, err := iservices.RetrieveByLabel("service_proxy")
 	if err != nil {
 		return err
@@ -18,30 +19,38 @@ func CmdList(is ISites, iservices services.IServices) error {
 	if err != nil {
 		return err
 	}
-	if sites == nil || len(*sites) == 0 {
-		logrus.Println("No sites found")
-		return nil
-	}
-	svcs, err := iservices.List()
-	svcMap := map[string]string{}
-	for _, s := range *svcs {
-		svcMap[s.ID] = s.Label
+	if sites == nil {
+		sites = &[]models.Site{}
 	}
+	return ir.Render(sites, func() error {
+		if len(*sites) == 0 {
+			logrus.Println("No sites found")
+			return nil
+		}
+		svcs, err := iservices.List()
+		if err != nil {
+			return err
+		}
+		svcMap := map[string]string{}
+		for _, s := range *svcs {
+			svcMap[s.ID] = s.Label
+		}
 
-	data := [][]string{{"NAME", "CERT", "UPSTREAM SERVICE"}}
-	for _, s := range *sites {
-		data = append(data, []string{s.Name, s.Cert, svcMap[s.UpstreamService]})
-	}
+		data := [][]string{{"NAME", "CERT", "UPSTREAM SERVICE"}}
+		for _, s := range *sites {
+			data = append(data, []string{s.Name, s.Cert, svcMap[s.UpstreamService]})
+		}
 
-	table := tablewriter.NewWriter(logrus.StandardLogger().Out)
-	table.SetBorder(false)
-	table.SetRowLine(false)
-	table.SetCenterSeparator("")
-	table.SetColumnSeparator("")
-	table.SetRowSeparator("")
-	table.AppendBulk(data)
-	table.Render()
-	return nil
+		table := tablewriter.NewWriter(logrus.StandardLogger().Out)
+		table.SetBorder(false)
+		table.SetRowLine(false)
+		table.SetCenterSeparator("")
+		table.SetColumnSeparator("")
+		table.SetRowSeparator("")
+		table.AppendBulk(data)
+		table.Render()
+		return nil
+	})
 }
 
 func (s *SSites) List(svcID string) (*[]models.Site, error) {
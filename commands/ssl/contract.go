@@ -3,7 +3,7 @@ package ssl
 import (
 	"fmt"
 
-	"github.com/Sirupsen/logrus"
+	"github.com/catalyzeio/cli/lib/httpclient"
 	"github.com/catalyzeio/cli/models"
 	"github.com/jault3/mow.cli"
 )
@@ -81,7 +81,7 @@ var ResolveSubCmd = models.Command{
 			subCmd.Action = func() {
 				err := CmdResolve(*chain, *privateKey, *hostname, *output, *force, New(settings))
 				if err != nil {
-					logrus.Fatal(err.Error())
+					httpclient.Fatal(err)
 				}
 			}
 			subCmd.Spec = "CHAIN PRIVATE_KEY HOSTNAME [OUTPUT] [-f]"
@@ -125,7 +125,7 @@ var VerifySubCmd = models.Command{
 			subCmd.Action = func() {
 				err := CmdVerify(*chain, *privateKey, *hostname, *selfSigned, New(settings))
 				if err != nil {
-					logrus.Fatal(err.Error())
+					httpclient.Fatal(err)
 				}
 			}
 			subCmd.Spec = "CHAIN PRIVATE_KEY HOSTNAME [-s]"
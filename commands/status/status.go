@@ -8,11 +8,12 @@ import (
 
 	"github.com/catalyzeio/cli/commands/environments"
 	"github.com/catalyzeio/cli/commands/services"
+	"github.com/catalyzeio/cli/lib/render"
 	"github.com/catalyzeio/cli/models"
 	"github.com/pmylund/sortutil"
 )
 
-func CmdStatus(envID string, is IStatus, ie environments.IEnvironments, iservices services.IServices) error {
+func CmdStatus(envID string, is IStatus, ie environments.IEnvironments, iservices services.IServices, ir render.IRender) error {
 	env, err := ie.Retrieve(envID)
 	if err != nil {
 		return err
@@ -21,70 +22,102 @@ func CmdStatus(envID string, is IStatus, ie environments.IEnvironments, iservice
 	if err != nil {
 		return err
 	}
-	return is.Status(env, svcs)
+	status, err := is.Status(env, svcs)
+	if err != nil {
+		return err
+	}
+	return ir.Render(status, func() error {
+		printStatus(status)
+		return nil
+	})
 }
 
-// Status prints out all of the non-utility services and their running jobs
-func (s *SStatus) Status(env *models.Environment, services *[]models.Service) error {
-	w := &tabwriter.Writer{}
-	w.Init(os.Stdout, 0, 8, 4, '\t', 0)
-
-	fmt.Fprintln(w, env.Name+" (environment ID = "+env.ID+"):")
-	fmt.Fprintln(w, "Label\tStatus\tCreated At")
+// Status gathers all of the non-utility services along with their running
+// jobs and latest build
+func (s *SStatus) Status(env *models.Environment, services *[]models.Service) (*models.EnvironmentStatus, error) {
+	status := &models.EnvironmentStatus{
+		Environment: env,
+		Services:    []models.ServiceStatus{},
+	}
 
 	sortutil.AscByField(*services, "Label")
 
 	for _, service := range *services {
-		if service.Type != "" {
-			jobs, err := s.Jobs.RetrieveByStatus(service.ID, "running")
+		if service.Type == "" {
+			continue
+		}
+		svcStatus := models.ServiceStatus{
+			ID:             service.ID,
+			Label:          service.Label,
+			Type:           service.Type,
+			ReleaseVersion: service.ReleaseVersion,
+			Jobs:           []models.Job{},
+		}
+		jobs, err := s.Jobs.RetrieveByStatus(service.ID, "running")
+		if err != nil {
+			return nil, err
+		}
+		for _, job := range *jobs {
+			if job.Type == "worker" {
+				// fetch the worker separately to get the procfile target run
+				workerJob, err := s.Jobs.Retrieve(job.ID, service.ID, true)
+				if err != nil {
+					return nil, err
+				}
+				job = *workerJob
+			}
+			svcStatus.Jobs = append(svcStatus.Jobs, job)
+		}
+		if service.Type == "code" {
+			latestBuildJobs, err := s.Jobs.RetrieveByType(service.ID, "build", 1, 1)
 			if err != nil {
-				return err
+				return nil, err
 			}
-			for _, job := range *jobs {
-				displayType := service.Label
-				if job.Type != "deploy" {
-					displayType = fmt.Sprintf("%s (%s)", service.Label, job.Type)
-					if job.Type == "worker" {
-						// fetch the worker separately to get the procfile target run
-						workerJob, err := s.Jobs.Retrieve(job.ID, service.ID, true)
-						if err != nil {
-							return err
-						}
-						if workerJob.Spec != nil && workerJob.Spec.Payload != nil && workerJob.Spec.Payload.Environment != nil {
-							if target, contains := workerJob.Spec.Payload.Environment["PROCFILE_TARGET"]; contains {
-								displayType = fmt.Sprintf("%s (%s: target=%s)", service.Label, job.Type, target)
-							}
-						}
-					}
-				} else if len(service.ReleaseVersion) > 0 {
-					displayType = fmt.Sprintf("%s (%s)", service.Label, service.ReleaseVersion)
-				} else {
-					displayType = fmt.Sprintf("%s", service.Label)
-				}
-
-				const dateForm = "2006-01-02T15:04:05"
-				t, _ := time.Parse(dateForm, job.CreatedAt)
-				fmt.Fprintln(w, displayType+"\t"+job.Status+"\t"+t.Local().Format(time.Stamp))
+			for _, latestBuildJob := range *latestBuildJobs {
+				build := latestBuildJob
+				svcStatus.LatestBuild = &build
 			}
-			if service.Type == "code" {
-				latestBuildJobs, err := s.Jobs.RetrieveByType(service.ID, "build", 1, 1)
-				if err != nil {
-					return err
-				}
-				for _, latestBuildJob := range *latestBuildJobs {
-					if latestBuildJob.ID == "" {
-						fmt.Fprintln(w, "--------"+"\t"+service.Label+"\t"+"-------"+"\t"+"---------------")
-					} else if latestBuildJob.ID != "" {
-						const dateForm = "2006-01-02T15:04:05"
-						t, _ := time.Parse(dateForm, latestBuildJob.CreatedAt)
-						displayType := service.Label
-						displayType = fmt.Sprintf("%s (%s)", displayType, latestBuildJob.Type)
-						fmt.Fprintln(w, displayType+"\t"+latestBuildJob.Status+"\t"+t.Local().Format(time.Stamp))
+		}
+		status.Services = append(status.Services, svcStatus)
+	}
+	return status, nil
+}
+
+// printStatus prints out the environment status as a table
+func printStatus(status *models.EnvironmentStatus) {
+	w := &tabwriter.Writer{}
+	w.Init(os.Stdout, 0, 8, 4, '\t', 0)
+
+	fmt.Fprintln(w, status.Environment.Name+" (environment ID = "+status.Environment.ID+"):")
+	fmt.Fprintln(w, "Label\tStatus\tCreated At")
+
+	const dateForm = "2006-01-02T15:04:05"
+	for _, service := range status.Services {
+		for _, job := range service.Jobs {
+			displayType := service.Label
+			if job.Type != "deploy" {
+				displayType = fmt.Sprintf("%s (%s)", service.Label, job.Type)
+				if job.Type == "worker" && job.Spec != nil && job.Spec.Payload != nil && job.Spec.Payload.Environment != nil {
+					if target, contains := job.Spec.Payload.Environment["PROCFILE_TARGET"]; contains {
+						displayType = fmt.Sprintf("%s (%s: target=%s)", service.Label, job.Type, target)
 					}
 				}
+			} else if len(service.ReleaseVersion) > 0 {
+				displayType = fmt.Sprintf("%s (%s)", service.Label, service.ReleaseVersion)
+			}
+
+			t, _ := time.Parse(dateForm, job.CreatedAt)
+			fmt.Fprintln(w, displayType+"\t"+job.Status+"\t"+t.Local().Format(time.Stamp))
+		}
+		if build := service.LatestBuild; build != nil {
+			if build.ID == "" {
+				fmt.Fprintln(w, "--------"+"\t"+service.Label+"\t"+"-------"+"\t"+"---------------")
+			} else {
+				t, _ := time.Parse(dateForm, build.CreatedAt)
+				displayType := fmt.Sprintf("%s (%s)", service.Label, build.Type)
+				fmt.Fprintln(w, displayType+"\t"+build.Status+"\t"+t.Local().Format(time.Stamp))
 			}
 		}
 	}
 	w.Flush()
-	return nil
 }
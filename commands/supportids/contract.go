@@ -1,7 +1,7 @@
 package supportids
 
 import (
-	"github.com/Sirupsen/logrus"
+	"github.com/catalyzeio/cli/lib/httpclient"
 	"github.com/catalyzeio/cli/models"
 	"github.com/jault3/mow.cli"
 )
@@ -20,7 +20,7 @@ var Cmd = models.Command{
 			cmd.Action = func() {
 				err := CmdSupportIDs(New(settings))
 				if err != nil {
-					logrus.Fatal(err.Error())
+					httpclient.Fatal(err)
 				}
 			}
 		}
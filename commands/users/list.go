@@ -5,49 +5,54 @@ import (
 
 	"github.com/Sirupsen/logrus"
 	"github.com/catalyzeio/cli/commands/invites"
+	"github.com/catalyzeio/cli/lib/render"
 	"github.com/catalyzeio/cli/models"
 	"github.com/olekukonko/tablewriter"
 	"github.com/pmylund/sortutil"
 )
 
-func CmdList(myUsersID string, iu IUsers, ii invites.IInvites) error {
+func CmdList(myUsersID string, iu IUsers, ii invites.IInvites, ir render.IRender) error {
 	orgUsers, err := iu.List()
 	if err != nil {
 		return err
 	}
-	if orgUsers == nil || len(*orgUsers) == 0 {
-		logrus.Println("No users found")
-		return nil
-	}
-	roles, err := ii.ListRoles()
-	if err != nil {
-		return err
-	}
-	rolesMap := map[int]string{}
-	for _, r := range *roles {
-		rolesMap[r.ID] = r.Name
+	if orgUsers == nil {
+		orgUsers = &[]models.OrgUser{}
 	}
-
 	sortutil.DescByField(*orgUsers, "RoleID")
+	return ir.Render(orgUsers, func() error {
+		if len(*orgUsers) == 0 {
+			logrus.Println("No users found")
+			return nil
+		}
+		roles, err := ii.ListRoles()
+		if err != nil {
+			return err
+		}
+		rolesMap := map[int]string{}
+		for _, r := range *roles {
+			rolesMap[r.ID] = r.Name
+		}
 
-	data := [][]string{{"EMAIL", "ROLE"}}
-	for _, user := range *orgUsers {
-		if user.ID == myUsersID {
-			data = append(data, []string{user.Email, fmt.Sprintf("%s (you)", rolesMap[user.RoleID])})
-		} else {
-			data = append(data, []string{user.Email, rolesMap[user.RoleID]})
+		data := [][]string{{"EMAIL", "ROLE"}}
+		for _, user := range *orgUsers {
+			if user.ID == myUsersID {
+				data = append(data, []string{user.Email, fmt.Sprintf("%s (you)", rolesMap[user.RoleID])})
+			} else {
+				data = append(data, []string{user.Email, rolesMap[user.RoleID]})
+			}
 		}
-	}
 
-	table := tablewriter.NewWriter(logrus.StandardLogger().Out)
-	table.SetBorder(false)
-	table.SetRowLine(false)
-	table.SetCenterSeparator("")
-	table.SetColumnSeparator("")
-	table.SetRowSeparator("")
-	table.AppendBulk(data)
-	table.Render()
-	return nil
+		table := tablewriter.NewWriter(logrus.StandardLogger().Out)
+		table.SetBorder(false)
+		table.SetRowLine(false)
+		table.SetCenterSeparator("")
+		table.SetColumnSeparator("")
+		table.SetRowSeparator("")
+		table.AppendBulk(data)
+		table.Render()
+		return nil
+	})
 }
 
 func (u *SUsers) List() (*[]models.OrgUser, error) {
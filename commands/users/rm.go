@@ -4,6 +4,7 @@ import (
 	"fmt"
 
 	"github.com/Sirupsen/logrus"
+	"github.com/catalyzeio/cli/lib/httpclient"
 )
 
 func CmdRm(email string, iu IUsers) error {
@@ -23,6 +24,9 @@ func CmdRm(email string, iu IUsers) error {
 	}
 
 	err = iu.Rm(usersID)
+	if httpclient.IsForbidden(err) {
+		return httpclient.WithHint(err, "Only admins of your environment's organization can remove users.")
+	}
 	if err != nil {
 		return err
 	}
@@ -1,11 +1,12 @@
 package vars
 
 import (
-	"github.com/Sirupsen/logrus"
 	"github.com/catalyzeio/cli/commands/services"
 	"github.com/catalyzeio/cli/config"
 	"github.com/catalyzeio/cli/lib/auth"
+	"github.com/catalyzeio/cli/lib/httpclient"
 	"github.com/catalyzeio/cli/lib/prompts"
+	"github.com/catalyzeio/cli/lib/render"
 	"github.com/catalyzeio/cli/models"
 	"github.com/jault3/mow.cli"
 )
@@ -29,7 +30,7 @@ var ListSubCmd = models.Command{
 	Name:      "list",
 	ShortHelp: "List all environment variables",
 	LongHelp: "`vars list` prints out all known environment variables for the given code service. " +
-		"You can print out environment variables in JSON or YAML format through the `--json` or `--yaml` flags. " +
+		"You can print out environment variables in JSON or YAML format through the `--json` or `--yaml` flags, which are shorthand for the global `--output` option. " +
 		"Here are some sample commands\n\n" +
 		"```\ncatalyze -E \"<your_env_alias>\" vars list code-1\n" +
 		"catalyze -E \"<your_env_alias>\" vars list code-1 --json\n```",
@@ -39,23 +40,21 @@ var ListSubCmd = models.Command{
 			json := subCmd.BoolOpt("json", false, "Output environment variables in JSON format")
 			yaml := subCmd.BoolOpt("yaml", false, "Output environment variables in YAML format")
 			subCmd.Action = func() {
-				if _, err := auth.New(settings, prompts.New()).Signin(); err != nil {
-					logrus.Fatal(err.Error())
+				if _, err := auth.New(settings, prompts.New(settings)).Signin(); err != nil {
+					httpclient.Fatal(err)
 				}
 				if err := config.CheckRequiredAssociation(true, true, settings); err != nil {
-					logrus.Fatal(err.Error())
+					httpclient.Fatal(err)
 				}
-				var formatter Formatter
+				ir := render.New(settings)
 				if *json {
-					formatter = &JSONFormatter{}
+					ir = render.NewWithFormat(render.JSON)
 				} else if *yaml {
-					formatter = &YAMLFormatter{}
-				} else {
-					formatter = &PlainFormatter{}
+					ir = render.NewWithFormat(render.YAML)
 				}
-				err := CmdList(*serviceName, settings.ServiceID, formatter, New(settings), services.New(settings))
+				err := CmdList(*serviceName, settings.ServiceID, New(settings), services.New(settings), ir)
 				if err != nil {
-					logrus.Fatal(err.Error())
+					httpclient.Fatal(err)
 				}
 			}
 			subCmd.Spec = "[SERVICE_NAME] [--json | --yaml]"
@@ -81,15 +80,15 @@ var SetSubCmd = models.Command{
 				HideValue: true,
 			})
 			subCmd.Action = func() {
-				if _, err := auth.New(settings, prompts.New()).Signin(); err != nil {
-					logrus.Fatal(err.Error())
+				if _, err := auth.New(settings, prompts.New(settings)).Signin(); err != nil {
+					httpclient.Fatal(err)
 				}
 				if err := config.CheckRequiredAssociation(true, true, settings); err != nil {
-					logrus.Fatal(err.Error())
+					httpclient.Fatal(err)
 				}
 				err := CmdSet(*serviceName, settings.ServiceID, *variables, New(settings), services.New(settings))
 				if err != nil {
-					logrus.Fatal(err.Error())
+					httpclient.Fatal(err)
 				}
 			}
 			subCmd.Spec = "[SERVICE_NAME] -v..."
@@ -110,15 +109,15 @@ var UnsetSubCmd = models.Command{
 			serviceName := subCmd.StringArg("SERVICE_NAME", "", "The name of the service on which the environment variables will be unset. Defaults to the associated service.")
 			variable := subCmd.StringArg("VARIABLE", "", "The name of the environment variable to unset")
 			subCmd.Action = func() {
-				if _, err := auth.New(settings, prompts.New()).Signin(); err != nil {
-					logrus.Fatal(err.Error())
+				if _, err := auth.New(settings, prompts.New(settings)).Signin(); err != nil {
+					httpclient.Fatal(err)
 				}
 				if err := config.CheckRequiredAssociation(true, true, settings); err != nil {
-					logrus.Fatal(err.Error())
+					httpclient.Fatal(err)
 				}
 				err := CmdUnset(*serviceName, settings.ServiceID, *variable, New(settings), services.New(settings))
 				if err != nil {
-					logrus.Fatal(err.Error())
+					httpclient.Fatal(err)
 				}
 			}
 			subCmd.Spec = "[SERVICE_NAME] VARIABLE"
@@ -1,60 +1,15 @@
 package vars
 
 import (
-	"encoding/json"
 	"fmt"
 	"sort"
 
 	"github.com/Sirupsen/logrus"
 	"github.com/catalyzeio/cli/commands/services"
-	"gopkg.in/yaml.v2"
+	"github.com/catalyzeio/cli/lib/render"
 )
 
-type Formatter interface {
-	Output(envVars map[string]string) error
-}
-
-type JSONFormatter struct{}
-
-func (j *JSONFormatter) Output(envVars map[string]string) error {
-	jsonVars := map[string]string{}
-	for k, v := range envVars {
-		jsonVars[k] = v
-	}
-	b, err := json.MarshalIndent(jsonVars, "", "    ")
-	if err != nil {
-		return err
-	}
-	logrus.Println(string(b))
-	return nil
-}
-
-type YAMLFormatter struct{}
-
-func (y *YAMLFormatter) Output(envVars map[string]string) error {
-	b, err := yaml.Marshal(envVars)
-	if err != nil {
-		return err
-	}
-	logrus.Println(string(b))
-	return nil
-}
-
-type PlainFormatter struct{}
-
-func (p *PlainFormatter) Output(envVars map[string]string) error {
-	var keys []string
-	for k := range envVars {
-		keys = append(keys, k)
-	}
-	sort.Strings(keys)
-	for _, key := range keys {
-		logrus.Printf("%s=%s", key, envVars[key])
-	}
-	return nil
-}
-
-func CmdList(svcName, defaultSvcID string, formatter Formatter, iv IVars, is services.IServices) error {
+func CmdList(svcName, defaultSvcID string, iv IVars, is services.IServices, ir render.IRender) error {
 	if svcName != "" {
 		service, err := is.RetrieveByLabel(svcName)
 		if err != nil {
@@ -69,11 +24,24 @@ func CmdList(svcName, defaultSvcID string, formatter Formatter, iv IVars, is ser
 	if err != nil {
 		return err
 	}
-	if len(envVars) == 0 {
-		logrus.Println("No environment variables found")
-		return nil
+	if envVars == nil {
+		envVars = map[string]string{}
 	}
-	return formatter.Output(envVars)
+	return ir.Render(envVars, func() error {
+		if len(envVars) == 0 {
+			logrus.Println("No environment variables found")
+			return nil
+		}
+		var keys []string
+		for k := range envVars {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			logrus.Printf("%s=%s", key, envVars[key])
+		}
+		return nil
+	})
 }
 
 // List lists all environment variables.
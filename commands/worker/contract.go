@@ -1,12 +1,13 @@
 package worker
 
 import (
-	"github.com/Sirupsen/logrus"
 	"github.com/catalyzeio/cli/commands/services"
 	"github.com/catalyzeio/cli/config"
 	"github.com/catalyzeio/cli/lib/auth"
+	"github.com/catalyzeio/cli/lib/httpclient"
 	"github.com/catalyzeio/cli/lib/jobs"
 	"github.com/catalyzeio/cli/lib/prompts"
+	"github.com/catalyzeio/cli/lib/render"
 	"github.com/catalyzeio/cli/models"
 	"github.com/jault3/mow.cli"
 )
@@ -32,25 +33,29 @@ var DeploySubCmd = models.Command{
 	ShortHelp: "Deploy new workers for a given service",
 	LongHelp: "`worker deploy` allows you to start a background process asynchronously. The TARGET must be specified in your Procfile. " +
 		"Once the worker is started, any output can be found in your logging Dashboard or using the [logs](#logs) command. " +
-		"Here is a sample command\n\n" +
-		"```\ncatalyze -E \"<your_env_alias>\" worker deploy code-1 mailer\n```",
+		"Add `--wait` to wait until the new worker is running and exit with an error if it fails or does not start within `--timeout` seconds. " +
+		"Here are some sample commands\n\n" +
+		"```\ncatalyze -E \"<your_env_alias>\" worker deploy code-1 mailer\n" +
+		"catalyze -E \"<your_env_alias>\" worker deploy code-1 mailer --wait\n```",
 	CmdFunc: func(settings *models.Settings) func(cmd *cli.Cmd) {
 		return func(subCmd *cli.Cmd) {
 			serviceName := subCmd.StringArg("SERVICE_NAME", "", "The name of the service to use to deploy a worker")
 			target := subCmd.StringArg("TARGET", "", "The name of the Procfile target to invoke as a worker")
+			wait := subCmd.BoolOpt("wait", false, "Wait until the new worker is running and exit with an error if it fails")
+			timeout := subCmd.IntOpt("timeout", config.DeployWaitTimeout, "The number of seconds to wait for the worker to be running, or 0 to wait indefinitely")
 			subCmd.Action = func() {
-				if _, err := auth.New(settings, prompts.New()).Signin(); err != nil {
-					logrus.Fatal(err.Error())
+				if _, err := auth.New(settings, prompts.New(settings)).Signin(); err != nil {
+					httpclient.Fatal(err)
 				}
 				if err := config.CheckRequiredAssociation(true, true, settings); err != nil {
-					logrus.Fatal(err.Error())
+					httpclient.Fatal(err)
 				}
-				err := CmdDeploy(*serviceName, *target, New(settings), services.New(settings), jobs.New(settings))
+				err := CmdDeploy(*serviceName, *target, *wait, *timeout, New(settings), services.New(settings), jobs.New(settings))
 				if err != nil {
-					logrus.Fatal(err.Error())
+					httpclient.Fatal(err)
 				}
 			}
-			subCmd.Spec = "SERVICE_NAME TARGET"
+			subCmd.Spec = "SERVICE_NAME TARGET [--wait [--timeout]]"
 		}
 	},
 }
@@ -64,15 +69,15 @@ var ListSubCmd = models.Command{
 		return func(subCmd *cli.Cmd) {
 			serviceName := subCmd.StringArg("SERVICE_NAME", "", "The name of the service to list workers for")
 			subCmd.Action = func() {
-				if _, err := auth.New(settings, prompts.New()).Signin(); err != nil {
-					logrus.Fatal(err.Error())
+				if _, err := auth.New(settings, prompts.New(settings)).Signin(); err != nil {
+					httpclient.Fatal(err)
 				}
 				if err := config.CheckRequiredAssociation(true, true, settings); err != nil {
-					logrus.Fatal(err.Error())
+					httpclient.Fatal(err)
 				}
-				err := CmdList(*serviceName, New(settings), services.New(settings), jobs.New(settings))
+				err := CmdList(*serviceName, New(settings), services.New(settings), jobs.New(settings), render.New(settings))
 				if err != nil {
-					logrus.Fatal(err.Error())
+					httpclient.Fatal(err)
 				}
 			}
 			subCmd.Spec = "SERVICE_NAME"
@@ -90,15 +95,15 @@ var RmSubCmd = models.Command{
 			serviceName := subCmd.StringArg("SERVICE_NAME", "", "The name of the service running the workers")
 			target := subCmd.StringArg("TARGET", "", "The worker target to remove")
 			subCmd.Action = func() {
-				if _, err := auth.New(settings, prompts.New()).Signin(); err != nil {
-					logrus.Fatal(err.Error())
+				if _, err := auth.New(settings, prompts.New(settings)).Signin(); err != nil {
+					httpclient.Fatal(err)
 				}
 				if err := config.CheckRequiredAssociation(true, true, settings); err != nil {
-					logrus.Fatal(err.Error())
+					httpclient.Fatal(err)
 				}
-				err := CmdRm(*serviceName, *target, New(settings), services.New(settings), prompts.New(), jobs.New(settings))
+				err := CmdRm(*serviceName, *target, New(settings), services.New(settings), prompts.New(settings), jobs.New(settings))
 				if err != nil {
-					logrus.Fatalln(err.Error())
+					httpclient.Fatal(err)
 				}
 			}
 			subCmd.Spec = "SERVICE_NAME TARGET"
@@ -110,27 +115,32 @@ var ScaleSubCmd = models.Command{
 	Name:      "scale",
 	ShortHelp: "Scale existing workers up or down for a given service and target",
 	LongHelp: "`worker scale` allows you to scale up or down a given worker TARGET. " +
-		"Scaling up will launch new instances of the worker TARGET while scaling down will immediately stop running instances of the worker TARGET if applicable. Here are some sample commands\n\n" +
+		"Scaling up will launch new instances of the worker TARGET while scaling down will immediately stop running instances of the worker TARGET if applicable. " +
+		"When scaling up, add `--wait` to wait until a new worker is running and exit with an error if it fails or does not start within `--timeout` seconds. " +
+		"Here are some sample commands\n\n" +
 		"```\ncatalyze -E \"<your_env_alias>\" worker scale code-1 mailer 1\n" +
+		"catalyze -E \"<your_env_alias>\" worker scale code-1 mailer --wait 3\n" +
 		"catalyze -E \"<your_env_alias>\" worker scale code-1 mailer -- -2\n```",
 	CmdFunc: func(settings *models.Settings) func(cmd *cli.Cmd) {
 		return func(subCmd *cli.Cmd) {
 			serviceName := subCmd.StringArg("SERVICE_NAME", "", "The name of the service running the workers")
 			target := subCmd.StringArg("TARGET", "", "The worker target to scale up or down")
+			wait := subCmd.BoolOpt("wait", false, "Wait until a new worker is running when scaling up and exit with an error if it fails")
+			timeout := subCmd.IntOpt("timeout", config.DeployWaitTimeout, "The number of seconds to wait for a new worker to be running, or 0 to wait indefinitely")
 			scale := subCmd.StringArg("SCALE", "", "The new scale (or change in scale) for the given worker target. This can be a single value (i.e. 2) representing the final number of workers that should be running. Or this can be a change represented by a plus or minus sign followed by the value (i.e. +2 or -1). When using a change in value, be sure to insert the \"--\" operator to signal the end of options. For example, \"catalyze worker scale code-1 worker -- -1\"")
 			subCmd.Action = func() {
-				if _, err := auth.New(settings, prompts.New()).Signin(); err != nil {
-					logrus.Fatal(err.Error())
+				if _, err := auth.New(settings, prompts.New(settings)).Signin(); err != nil {
+					httpclient.Fatal(err)
 				}
 				if err := config.CheckRequiredAssociation(true, true, settings); err != nil {
-					logrus.Fatal(err.Error())
+					httpclient.Fatal(err)
 				}
-				err := CmdScale(*serviceName, *target, *scale, New(settings), services.New(settings), prompts.New(), jobs.New(settings))
+				err := CmdScale(*serviceName, *target, *scale, *wait, *timeout, New(settings), services.New(settings), prompts.New(settings), jobs.New(settings))
 				if err != nil {
-					logrus.Fatal(err.Error())
+					httpclient.Fatal(err)
 				}
 			}
-			subCmd.Spec = "SERVICE_NAME TARGET SCALE"
+			subCmd.Spec = "SERVICE_NAME TARGET [--wait [--timeout]] SCALE"
 		}
 	},
 }
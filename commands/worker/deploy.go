@@ -2,13 +2,19 @@ package worker
 
 import (
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/Sirupsen/logrus"
 	"github.com/catalyzeio/cli/commands/services"
 	"github.com/catalyzeio/cli/lib/jobs"
+	"github.com/catalyzeio/cli/models"
 )
 
-func CmdDeploy(svcName, target string, iw IWorker, is services.IServices, ij jobs.IJobs) error {
+func CmdDeploy(svcName, target string, wait bool, timeout int, iw IWorker, is services.IServices, ij jobs.IJobs) error {
+	if timeout < 0 {
+		return fmt.Errorf("--timeout can not be negative")
+	}
 	service, err := is.RetrieveByLabel(svcName)
 	if err != nil {
 		return err
@@ -29,6 +35,14 @@ func CmdDeploy(svcName, target string, iw IWorker, is services.IServices, ij job
 	if err != nil {
 		return err
 	}
+	if wait {
+		job, err := waitForWorker(svcName, target, time.Duration(timeout)*time.Second, service.ID, ij)
+		if err != nil {
+			return err
+		}
+		logrus.Printf("Successfully deployed a worker for service %s with target %s. The worker job %s is running", svcName, target, job.ID)
+		return nil
+	}
 	err = ij.DeployTarget(target, service.ID)
 	if err != nil {
 		return err
@@ -36,3 +50,19 @@ func CmdDeploy(svcName, target string, iw IWorker, is services.IServices, ij job
 	logrus.Printf("Successfully deployed a worker for service %s with target %s", svcName, target)
 	return nil
 }
+
+// waitForWorker deploys the given target and waits for the new worker job to
+// be running
+func waitForWorker(svcName, target string, timeout time.Duration, svcID string, ij jobs.IJobs) (*models.Job, error) {
+	logrus.Println("Waiting for the worker job to be running...")
+	job, err := ij.WaitForDeploy("worker", target, svcID, timeout, func() error {
+		return ij.DeployTarget(target, svcID)
+	})
+	if err != nil {
+		if job != nil {
+			return nil, fmt.Errorf("The worker for service %s with target %s failed. %s. View the job with \"catalyze jobs show %s %s\" and its logs in your logging dashboard", svcName, target, strings.TrimSuffix(err.Error(), "."), job.ID, svcName)
+		}
+		return nil, err
+	}
+	return job, nil
+}
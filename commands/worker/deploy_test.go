@@ -0,0 +1,39 @@
+package worker
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/catalyzeio/cli/test"
+)
+
+func TestWorkerWait(t *testing.T) {
+	if err := test.SetUpGitRepo(); err != nil {
+		t.Fatal(err)
+	}
+	if err := test.SetUpAssociation(); err != nil {
+		t.Fatal(err)
+	}
+
+	var workerTests = []struct {
+		args           []string
+		expectErr      bool
+		expectedOutput string
+	}{
+		{[]string{"deploy", test.SvcLabel, "mailer", "--wait"}, false, `Successfully deployed a worker for service code-1 with target mailer. The worker job \S+ is running`},
+		{[]string{"scale", test.SvcLabel, "mailer", "--wait", "3"}, false, `Successfully deployed 2 new workers with target mailer for service code-1 and set the scale to 3. The worker job \S+ is running`},
+		{[]string{"deploy", test.SvcLabel, "mailer", "--wait", "--timeout=-5"}, true, "--timeout can not be negative"},
+	}
+	for _, data := range workerTests {
+		t.Logf("Data: %+v", data)
+		args := append([]string{"-E", test.Alias, "worker"}, data.args...)
+		output, err := test.RunCommand(test.BinaryName, args)
+		if err != nil != data.expectErr {
+			t.Errorf("Unexpected error: %s", output)
+			continue
+		}
+		if !regexp.MustCompile(data.expectedOutput).MatchString(output) {
+			t.Errorf("Expected: %s. Found: %s", data.expectedOutput, output)
+		}
+	}
+}
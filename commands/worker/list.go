@@ -6,11 +6,13 @@ import (
 	"github.com/Sirupsen/logrus"
 	"github.com/catalyzeio/cli/commands/services"
 	"github.com/catalyzeio/cli/lib/jobs"
+	"github.com/catalyzeio/cli/lib/render"
 	"github.com/catalyzeio/cli/models"
 	"github.com/olekukonko/tablewriter"
+	"github.com/pmylund/sortutil"
 )
 
-func CmdList(svcName string, iw IWorker, is services.IServices, ij jobs.IJobs) error {
+func CmdList(svcName string, iw IWorker, is services.IServices, ij jobs.IJobs, ir render.IRender) error {
 	service, err := is.RetrieveByLabel(svcName)
 	if err != nil {
 		return err
@@ -23,49 +25,57 @@ func CmdList(svcName string, iw IWorker, is services.IServices, ij jobs.IJobs) e
 		return err
 	}
 
-	jobs, err := ij.RetrieveByType(service.ID, "worker", 1, 1000)
-	if err != nil {
-		return err
-	}
-	type workerJob struct {
-		scale   int
-		running int
+	summary := models.WorkerSummary{
+		Service: svcName,
+		Limit:   service.WorkerScale,
+		Workers: []models.WorkerTarget{},
 	}
-	var workerJobs = map[string]*workerJob{}
+	var workerJobs = map[string]*models.WorkerTarget{}
 	for target, scale := range workers.Workers {
-		workerJobs[target] = &workerJob{scale, 0}
-	}
-	if len(workerJobs) == 0 {
-		logrus.Printf("No running workers found for %s", svcName)
-		logrus.Printf("\nYou are using 0 out of your available %d workers for %s", service.WorkerScale, svcName)
-		return nil
+		workerJobs[target] = &models.WorkerTarget{Target: target, Scale: scale}
 	}
-	for _, j := range *jobs {
-		if _, ok := workerJobs[j.Target]; !ok {
-			workerJobs[j.Target] = &workerJob{0, 0}
+	if len(workerJobs) > 0 {
+		jobs, err := ij.RetrieveByType(service.ID, "worker", 1, 1000)
+		if err != nil {
+			return err
 		}
-		if j.Status == "running" {
-			workerJobs[j.Target].running = 1
+		for _, j := range *jobs {
+			if _, ok := workerJobs[j.Target]; !ok {
+				workerJobs[j.Target] = &models.WorkerTarget{Target: j.Target}
+			}
+			if j.Status == "running" {
+				workerJobs[j.Target].Running = 1
+			}
 		}
 	}
-
-	data := [][]string{{"TARGET", "SCALE", "RUNNING JOBS"}}
-	total := 0
-	for target, wj := range workerJobs {
-		total += wj.scale
-		data = append(data, []string{target, fmt.Sprintf("%d", wj.scale), fmt.Sprintf("%d", wj.running)})
+	for _, wj := range workerJobs {
+		summary.Used += wj.Scale
+		summary.Workers = append(summary.Workers, *wj)
 	}
+	sortutil.AscByField(summary.Workers, "Target")
 
-	table := tablewriter.NewWriter(logrus.StandardLogger().Out)
-	table.SetBorder(false)
-	table.SetRowLine(false)
-	table.SetCenterSeparator("")
-	table.SetColumnSeparator("")
-	table.SetRowSeparator("")
-	table.AppendBulk(data)
-	table.Render()
-	logrus.Printf("\nYou are using %d out of your available %d workers for %s", total, service.WorkerScale, svcName)
-	return nil
+	return ir.Render(summary, func() error {
+		if len(summary.Workers) == 0 {
+			logrus.Printf("No running workers found for %s", svcName)
+			logrus.Printf("\nYou are using 0 out of your available %d workers for %s", summary.Limit, svcName)
+			return nil
+		}
+		data := [][]string{{"TARGET", "SCALE", "RUNNING JOBS"}}
+		for _, wj := range summary.Workers {
+			data = append(data, []string{wj.Target, fmt.Sprintf("%d", wj.Scale), fmt.Sprintf("%d", wj.Running)})
+		}
+
+		table := tablewriter.NewWriter(logrus.StandardLogger().Out)
+		table.SetBorder(false)
+		table.SetRowLine(false)
+		table.SetCenterSeparator("")
+		table.SetColumnSeparator("")
+		table.SetRowSeparator("")
+		table.AppendBulk(data)
+		table.Render()
+		logrus.Printf("\nYou are using %d out of your available %d workers for %s", summary.Used, summary.Limit, svcName)
+		return nil
+	})
 }
 
 func (w *SWorker) Retrieve(svcID string) (*models.Workers, error) {
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/Sirupsen/logrus"
 	"github.com/catalyzeio/cli/commands/services"
@@ -13,7 +14,10 @@ import (
 	"github.com/catalyzeio/cli/models"
 )
 
-func CmdScale(svcName, target, scaleString string, iw IWorker, is services.IServices, ip prompts.IPrompts, ij jobs.IJobs) error {
+func CmdScale(svcName, target, scaleString string, wait bool, timeout int, iw IWorker, is services.IServices, ip prompts.IPrompts, ij jobs.IJobs) error {
+	if timeout < 0 {
+		return fmt.Errorf("--timeout can not be negative")
+	}
 	service, err := is.RetrieveByLabel(svcName)
 	if err != nil {
 		return err
@@ -40,6 +44,14 @@ func CmdScale(svcName, target, scaleString string, iw IWorker, is services.IServ
 		if err != nil {
 			return err
 		}
+		if wait {
+			job, err := waitForWorker(svcName, target, time.Duration(timeout)*time.Second, service.ID, ij)
+			if err != nil {
+				return err
+			}
+			logrus.Printf("Successfully deployed %d new workers with target %s for service %s and set the scale to %d. The worker job %s is running", scale-existingScale, target, svcName, scale, job.ID)
+			return nil
+		}
 		err = ij.DeployTarget(target, service.ID)
 		if err != nil {
 			return err
@@ -18,5 +18,5 @@ func CmdWorker(svcName, defaultSvcID, target string, iw IWorker, is services.ISe
 		}
 		svcName = service.Label
 	}
-	return CmdDeploy(svcName, target, iw, is, ij)
+	return CmdDeploy(svcName, target, false, 0, iw, is, ij)
 }
@@ -26,8 +26,16 @@ const (
 	LogLevel = logrus.InfoLevel
 	// JobPollTime is the amount of time in seconds to wait between polls for a job status
 	JobPollTime = 5
+	// DeployWaitTimeout is the default amount of time in seconds to wait for a deploy job to be running
+	DeployWaitTimeout = 600
 	// LogPollTime is the amount of time in seconds to wait between polls for new logs
 	LogPollTime = 3
+	// HTTPMaxRetries is the maximum number of times a failed request is retried
+	HTTPMaxRetries = 3
+	// HTTPRetryWait is the base amount of time in milliseconds to wait before retrying a failed request
+	HTTPRetryWait = 500
+	// HTTPRetryMaxWait is the maximum amount of time in milliseconds to wait between retries
+	HTTPRetryMaxWait = 30000
 
 	// AccountsHostEnvVar is the env variable used to override AccountsHost
 	AccountsHostEnvVar = "ACCOUNTS_HOST"
@@ -45,10 +53,40 @@ const (
 	CatalyzePasswordEnvVar = "CATALYZE_PASSWORD"
 	// CatalyzeEnvironmentEnvVar is the env variable used to override the environment used in the current command
 	CatalyzeEnvironmentEnvVar = "CATALYZE_ENV"
+	// OutputEnvVar is the env variable used to override the output format
+	OutputEnvVar = "CATALYZE_OUTPUT"
+	// NonInteractiveEnvVar is the env variable used to disable all prompts
+	NonInteractiveEnvVar = "CATALYZE_NON_INTERACTIVE"
+	// AssumeYesEnvVar is the env variable used to automatically confirm all prompts
+	AssumeYesEnvVar = "CATALYZE_YES"
+	// KeyPassphraseEnvVar is the env variable used to supply the private key passphrase in non-interactive mode
+	KeyPassphraseEnvVar = "CATALYZE_KEY_PASSPHRASE"
+	// KeyPassphraseFileEnvVar is the env variable pointing to a file containing the private key passphrase
+	KeyPassphraseFileEnvVar = "CATALYZE_KEY_PASSPHRASE_FILE"
+	// BackupPassphraseEnvVar is the env variable used to supply the passphrase that wraps the key of a downloaded backup in non-interactive mode
+	BackupPassphraseEnvVar = "CATALYZE_BACKUP_PASSPHRASE"
+	// BackupPassphraseFileEnvVar is the env variable pointing to a file containing the passphrase that wraps the key of a downloaded backup
+	BackupPassphraseFileEnvVar = "CATALYZE_BACKUP_PASSPHRASE_FILE"
+	// OTPEnvVar is the env variable used to supply a one-time password in non-interactive mode
+	OTPEnvVar = "CATALYZE_OTP"
+	// OTPFileEnvVar is the env variable pointing to a file containing a one-time password
+	OTPFileEnvVar = "CATALYZE_OTP_FILE"
+	// ProfileEnvVar is the env variable used to override the profile used in the current command
+	ProfileEnvVar = "CATALYZE_PROFILE"
+	// DefaultProfile is the name of the profile used when none has been created or chosen
+	DefaultProfile = "default"
+	// CredentialStoreEnvVar is the env variable used to choose where the session token is stored, either "keyring" or "file"
+	CredentialStoreEnvVar = "CATALYZE_CREDENTIAL_STORE"
 	// LogLevelEnvVar is the env variable used to override the logging level used
 	LogLevelEnvVar = "CATALYZE_LOG_LEVEL"
 	// SkipVerifyEnvVar is the env variable used to accept invalid SSL certificates
 	SkipVerifyEnvVar = "SKIP_VERIFY"
+	// HTTPMaxRetriesEnvVar is the env variable used to override HTTPMaxRetries
+	HTTPMaxRetriesEnvVar = "CATALYZE_HTTP_MAX_RETRIES"
+	// HTTPRetryWaitEnvVar is the env variable used to override HTTPRetryWait
+	HTTPRetryWaitEnvVar = "CATALYZE_HTTP_RETRY_WAIT"
+	// HTTPRetryMaxWaitEnvVar is the env variable used to override HTTPRetryMaxWait
+	HTTPRetryMaxWaitEnvVar = "CATALYZE_HTTP_RETRY_MAX_WAIT"
 
 	// InvalidChars is a string containing all invalid characters for naming
 	InvalidChars = "/?%"
@@ -0,0 +1,137 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/catalyzeio/cli/lib/atomicfile"
+	"github.com/catalyzeio/cli/models"
+	"github.com/mitchellh/go-homedir"
+)
+
+// settingsFile is the format of the SettingsFile on disk
+type settingsFile struct {
+	Profile  string                    `json:"profile"`
+	Profiles map[string]models.Profile `json:"profiles"`
+}
+
+// readSettingsFile reads the settings file at the given path. A missing file
+// results in a single empty default profile. Settings files written before
+// profiles were added are converted into a default profile and any session
+// token they contain is returned so it can be moved into the credential store.
+func readSettingsFile(path string) (*settingsFile, string, error) {
+	sf := &settingsFile{}
+	b, err := ioutil.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, "", err
+	}
+	json.Unmarshal(b, sf)
+	var legacyToken string
+	if sf.Profiles == nil {
+		var legacy models.Settings
+		json.Unmarshal(b, &legacy)
+		legacyToken = legacy.SessionToken
+		sf.Profile = DefaultProfile
+		sf.Profiles = map[string]models.Profile{
+			DefaultProfile: {
+				PrivateKeyPath: legacy.PrivateKeyPath,
+				UsersID:        legacy.UsersID,
+				Environments:   legacy.Environments,
+				Default:        legacy.Default,
+				Pods:           legacy.Pods,
+				PodCheck:       legacy.PodCheck,
+			},
+		}
+	}
+	if sf.Profile == "" {
+		sf.Profile = DefaultProfile
+	}
+	return sf, legacyToken, nil
+}
+
+// updateSettingsFile reads the settings file, applies the given modification,
+// and writes it back while holding the settings file lock.
+func updateSettingsFile(modify func(sf *settingsFile) error) error {
+	HomeDir, err := homedir.Dir()
+	if err != nil {
+		return err
+	}
+	settingsPath := filepath.Join(HomeDir, SettingsFile)
+	unlock, err := atomicfile.Lock(settingsPath)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	sf, _, err := readSettingsFile(settingsPath)
+	if err != nil {
+		return err
+	}
+	if err = modify(sf); err != nil {
+		return err
+	}
+	b, err := json.Marshal(sf)
+	if err != nil {
+		return err
+	}
+	return atomicfile.WriteFile(settingsPath, b, 0600)
+}
+
+// AddProfile creates a new profile with the given name. The profile must not
+// already exist.
+func AddProfile(name string, profile models.Profile, settings *models.Settings) error {
+	return updateSettingsFile(func(sf *settingsFile) error {
+		if _, ok := sf.Profiles[name]; ok {
+			return fmt.Errorf("A profile named \"%s\" already exists", name)
+		}
+		if profile.Environments == nil {
+			profile.Environments = map[string]models.AssociatedEnv{}
+		}
+		sf.Profiles[name] = profile
+		settings.Profiles = sf.Profiles
+		return nil
+	})
+}
+
+// UseProfile sets the profile used by all commands that do not specify one
+// with the --profile option.
+func UseProfile(name string, settings *models.Settings) error {
+	return updateSettingsFile(func(sf *settingsFile) error {
+		if _, ok := sf.Profiles[name]; !ok {
+			return fmt.Errorf("No profile named \"%s\" exists. Run \"catalyze profile list\" to see all profiles", name)
+		}
+		sf.Profile = name
+		settings.ActiveProfile = name
+		return nil
+	})
+}
+
+// RemoveProfile deletes a profile and its stored session token. Neither the
+// active profile nor the profile used for the current command can be removed.
+func RemoveProfile(name string, settings *models.Settings) error {
+	err := updateSettingsFile(func(sf *settingsFile) error {
+		if _, ok := sf.Profiles[name]; !ok {
+			return fmt.Errorf("No profile named \"%s\" exists. Run \"catalyze profile list\" to see all profiles", name)
+		}
+		if name == sf.Profile || name == settings.Profile {
+			return fmt.Errorf("The profile \"%s\" is in use. Switch to another profile with \"catalyze profile use\" before removing it", name)
+		}
+		delete(sf.Profiles, name)
+		settings.Profiles = sf.Profiles
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	HomeDir, err := homedir.Dir()
+	if err != nil {
+		return err
+	}
+	cs, err := credentialStore(HomeDir)
+	if err != nil {
+		return err
+	}
+	return cs.Delete(name)
+}
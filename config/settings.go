@@ -1,14 +1,13 @@
 package config
 
 import (
-	"encoding/json"
 	"errors"
 	"fmt"
-	"io/ioutil"
 	"os"
 	"path/filepath"
 
 	"github.com/Sirupsen/logrus"
+	"github.com/catalyzeio/cli/lib/credentials"
 	"github.com/catalyzeio/cli/models"
 	"github.com/mitchellh/go-homedir"
 )
@@ -21,35 +20,52 @@ const SettingsFile = ".catalyze"
 // for retrieving settings based on the settings file or generating a settings
 // object based on a directly entered environment ID and service ID.
 type SettingsRetriever interface {
-	GetSettings(string, string, string, string, string, string, string, string, string) *models.Settings
+	GetSettings(string, string, string, string, string, string, string, string, string, string) *models.Settings
 }
 
 // FileSettingsRetriever reads in data from the SettingsFile and generates a
 // settings object.
 type FileSettingsRetriever struct{}
 
-// GetSettings returns a Settings object for the current context
-func (s FileSettingsRetriever) GetSettings(envName, svcName, accountsHost, authHost, ignoreAuthHostVersion, paasHost, ignorePaasHostVersion, username, password string) *models.Settings {
+// GetSettings returns a Settings object for the current context. The given
+// profile is used, falling back to the active profile in the settings file.
+// Hosts and host versions are taken from the env variables if set, then from
+// the profile, and finally from the built in defaults.
+func (s FileSettingsRetriever) GetSettings(profileName, envName, svcName, accountsHost, authHost, ignoreAuthHostVersion, paasHost, ignorePaasHostVersion, username, password string) *models.Settings {
 	HomeDir, err := homedir.Dir()
 	if err != nil {
 		logrus.Println(err.Error())
 		os.Exit(1)
 	}
 
-	file, err := os.Open(filepath.Join(HomeDir, SettingsFile))
-	if os.IsNotExist(err) {
-		file, err = os.Create(filepath.Join(HomeDir, SettingsFile))
-	}
-	defer file.Close()
+	sf, legacyToken, err := readSettingsFile(filepath.Join(HomeDir, SettingsFile))
 	if err != nil {
 		logrus.Println(err.Error())
 		os.Exit(1)
 	}
-	var settings models.Settings
-	json.NewDecoder(file).Decode(&settings)
+	if profileName == "" {
+		profileName = sf.Profile
+	}
+	profile, ok := sf.Profiles[profileName]
+	if !ok && profileName != DefaultProfile {
+		logrus.Fatalf("No profile named \"%s\" exists. Run \"catalyze profile list\" to see all profiles or \"catalyze profile add\" to create a new one", profileName)
+	}
+	settings := models.Settings{
+		Profile:        profileName,
+		ActiveProfile:  sf.Profile,
+		Profiles:       sf.Profiles,
+		PrivateKeyPath: profile.PrivateKeyPath,
+		UsersID:        profile.UsersID,
+		Environments:   profile.Environments,
+		Default:        profile.Default,
+		Pods:           profile.Pods,
+		PodCheck:       profile.PodCheck,
+		SessionToken:   legacyToken,
+	}
 	if settings.Environments == nil {
 		settings.Environments = make(map[string]models.AssociatedEnv)
 	}
+	loadSessionToken(HomeDir, &settings)
 
 	// try and set the given env first, if it exists
 	if envName != "" {
@@ -64,29 +80,20 @@ func (s FileSettingsRetriever) GetSettings(envName, svcName, accountsHost, authH
 		setGivenEnv(settings.Default, &settings)
 	}
 
-	settings.AccountsHost = accountsHost
-	settings.AuthHost = authHost
-	settings.PaasHost = paasHost
+	settings.AccountsHost = firstNonEmpty(accountsHost, profile.AccountsHost, AccountsHost)
+	settings.AuthHost = firstNonEmpty(authHost, profile.AuthHost, AuthHost)
+	settings.PaasHost = firstNonEmpty(paasHost, profile.PaasHost, PaasHost)
 	settings.Username = username
 	settings.Password = password
+	settings.AuthHostVersion = firstNonEmpty(os.Getenv(AuthHostVersionEnvVar), profile.AuthHostVersion, AuthHostVersion)
+	settings.PaasHostVersion = firstNonEmpty(os.Getenv(PaasHostVersionEnvVar), profile.PaasHostVersion, PaasHostVersion)
 
-	authHostVersion := os.Getenv(AuthHostVersionEnvVar)
-	if authHostVersion == "" {
-		authHostVersion = AuthHostVersion
-	}
-	settings.AuthHostVersion = authHostVersion
-
-	paasHostVersion := os.Getenv(PaasHostVersionEnvVar)
-	if paasHostVersion == "" {
-		paasHostVersion = PaasHostVersion
-	}
-	settings.PaasHostVersion = paasHostVersion
-
-	logrus.Debugf("Accounts Host: %s", accountsHost)
-	logrus.Debugf("Auth Host: %s", authHost)
-	logrus.Debugf("Paas Host: %s", paasHost)
-	logrus.Debugf("Auth Host Version: %s", authHostVersion)
-	logrus.Debugf("Paas Host Version: %s", paasHostVersion)
+	logrus.Debugf("Profile: %s", settings.Profile)
+	logrus.Debugf("Accounts Host: %s", settings.AccountsHost)
+	logrus.Debugf("Auth Host: %s", settings.AuthHost)
+	logrus.Debugf("Paas Host: %s", settings.PaasHost)
+	logrus.Debugf("Auth Host Version: %s", settings.AuthHostVersion)
+	logrus.Debugf("Paas Host Version: %s", settings.PaasHostVersion)
 	logrus.Debugf("Environment ID: %s", settings.EnvironmentID)
 	logrus.Debugf("Environment Name: %s", settings.EnvironmentName)
 	logrus.Debugf("Pod: %s", settings.Pod)
@@ -97,21 +104,100 @@ func (s FileSettingsRetriever) GetSettings(envName, svcName, accountsHost, authH
 	return &settings
 }
 
-// SaveSettings persists the settings to disk
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// SaveSettings persists the settings of the profile used for the current
+// command to disk. Other profiles in the settings file are left untouched. The
+// session token is kept in the credential store rather than the settings file.
+// The settings file is only readable by its owner and is replaced atomically
+// while holding a lock so concurrent commands never see or write a partial
+// file.
 func SaveSettings(settings *models.Settings) {
 	HomeDir, err := homedir.Dir()
 	if err != nil {
 		logrus.Println(err.Error())
 		os.Exit(1)
 	}
-	b, _ := json.Marshal(&settings)
-	err = ioutil.WriteFile(filepath.Join(HomeDir, SettingsFile), b, 0644)
+	if settings.Profile == "" {
+		settings.Profile = DefaultProfile
+	}
+	if err = saveSessionToken(HomeDir, settings.Profile, settings.SessionToken); err != nil {
+		logrus.Warnf("Could not securely store your session token, you will need to sign in again next time: %s", err)
+	}
+	err = updateSettingsFile(func(sf *settingsFile) error {
+		// hosts are only changed through the profile commands since the
+		// current values may have been overridden by env variables
+		profile := sf.Profiles[settings.Profile]
+		profile.PrivateKeyPath = settings.PrivateKeyPath
+		profile.UsersID = settings.UsersID
+		profile.Environments = settings.Environments
+		profile.Default = settings.Default
+		profile.Pods = settings.Pods
+		profile.PodCheck = settings.PodCheck
+		sf.Profiles[settings.Profile] = profile
+		return nil
+	})
 	if err != nil {
 		logrus.Println(err.Error())
 		os.Exit(1)
 	}
 }
 
+var store credentials.ICredentials
+
+// credentialStore returns the credential store chosen through the
+// CATALYZE_CREDENTIAL_STORE env variable, defaulting to the OS keyring if one
+// is available.
+func credentialStore(homeDir string) (credentials.ICredentials, error) {
+	if store == nil {
+		s, err := credentials.New(homeDir, os.Getenv(CredentialStoreEnvVar))
+		if err != nil {
+			return nil, err
+		}
+		store = s
+	}
+	return store, nil
+}
+
+// loadSessionToken populates the session token on the given settings from the
+// credential store, where it is stored under the name of the profile.
+// Settings files written by older versions of the CLI contain the session
+// token in plain text. These are migrated to the credential store and
+// rewritten without the token.
+func loadSessionToken(homeDir string, settings *models.Settings) {
+	if settings.SessionToken != "" {
+		logrus.Debugln("Migrating the session token from the settings file to the credential store")
+		SaveSettings(settings)
+		return
+	}
+	cs, err := credentialStore(homeDir)
+	if err != nil {
+		logrus.Warnln(err.Error())
+		return
+	}
+	token, err := cs.Get(settings.Profile)
+	if err != nil {
+		logrus.Debugf("Could not read the session token from the %s credential store: %s", cs.Name(), err)
+		return
+	}
+	settings.SessionToken = token
+}
+
+func saveSessionToken(homeDir, profile, token string) error {
+	cs, err := credentialStore(homeDir)
+	if err != nil {
+		return err
+	}
+	return cs.Set(profile, token)
+}
+
 // DeleteBreadcrumb removes the environment in the  global list
 func DeleteBreadcrumb(alias string, settings *models.Settings) error {
 	if _, ok := settings.Environments[alias]; !ok {
@@ -145,12 +231,12 @@ func setGivenEnv(envName string, settings *models.Settings) {
 func defaultEnvPrompt(envName string) error {
 	var answer string
 	for {
-		fmt.Printf("No environment was specified and no default environment was found. Falling back to %s\n", envName)
-		fmt.Print("Do you wish to proceed? (y/n) ")
+		fmt.Fprintf(logrus.StandardLogger().Out, "No environment was specified and no default environment was found. Falling back to %s\n", envName)
+		fmt.Fprint(logrus.StandardLogger().Out, "Do you wish to proceed? (y/n) ")
 		fmt.Scanln(&answer)
-		fmt.Println("")
+		fmt.Fprintln(logrus.StandardLogger().Out, "")
 		if answer != "y" && answer != "n" {
-			fmt.Printf("%s is not a valid option. Please enter 'y' or 'n'\n", answer)
+			fmt.Fprintf(logrus.StandardLogger().Out, "%s is not a valid option. Please enter 'y' or 'n'\n", answer)
 		} else {
 			break
 		}
@@ -168,7 +254,22 @@ func defaultEnvPrompt(envName string) error {
 func CheckRequiredAssociation(required, prompt bool, settings *models.Settings) error {
 	if required && (settings.EnvironmentID == "" || settings.ServiceID == "") {
 		err := ErrEnvRequired
-		if prompt {
+		if prompt && settings.NonInteractive {
+			// the fallback environment is picked at random from the associated
+			// ones, so only assume yes when there is no ambiguity
+			if len(settings.Environments) == 1 && settings.AssumeYes {
+				for alias := range settings.Environments {
+					setGivenEnv(alias, settings)
+				}
+				if settings.EnvironmentID == "" {
+					return ErrEnvRequired
+				}
+				return nil
+			}
+			if len(settings.Environments) > 0 {
+				err = fmt.Errorf("No environment was specified and no default environment was found. Specify one with the global -E option or the %s env variable", CatalyzeEnvironmentEnvVar)
+			}
+		} else if prompt {
 			for _, e := range settings.Environments {
 				err = defaultEnvPrompt(e.Name)
 				if err == nil {
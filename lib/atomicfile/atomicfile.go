@@ -0,0 +1,70 @@
+package atomicfile
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// lockTimeout is how long Lock waits for another process to release a lock
+const lockTimeout = 10 * time.Second
+
+// staleLock is the age after which a lock file is assumed to have been left
+// behind by a process that crashed
+const staleLock = 30 * time.Second
+
+// WriteFile writes data to a temp file in the same directory as the given path
+// and then renames it into place, so readers only ever see the old or the new
+// contents and never a partially written file.
+func WriteFile(path string, data []byte, perm os.FileMode) error {
+	tmp, err := ioutil.TempFile(filepath.Dir(path), "."+filepath.Base(path)+".tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if err = tmp.Chmod(perm); err != nil {
+		tmp.Close()
+		return err
+	}
+	if _, err = tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err = tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err = tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// Lock acquires an exclusive lock guarding the given path by creating a
+// sibling ".lock" file. The returned func releases the lock. Locks older than
+// 30 seconds are considered stale and are taken over.
+func Lock(path string) (func(), error) {
+	lockPath := path + ".lock"
+	deadline := time.Now().Add(lockTimeout)
+	for {
+		f, err := os.OpenFile(lockPath, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+		if err == nil {
+			fmt.Fprintf(f, "%d", os.Getpid())
+			f.Close()
+			return func() { os.Remove(lockPath) }, nil
+		}
+		if !os.IsExist(err) {
+			return nil, err
+		}
+		if info, statErr := os.Stat(lockPath); statErr == nil && time.Since(info.ModTime()) > staleLock {
+			os.Remove(lockPath)
+			continue
+		}
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("Timed out waiting for the lock on %s. If no other catalyze commands are running, remove %s and try again", path, lockPath)
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+}
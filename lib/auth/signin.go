@@ -111,7 +111,10 @@ func (a *SAuth) signInWithKey() (*signinResponse, error) {
 	}
 	bytes = block.Bytes
 	if x509.IsEncryptedPEMBlock(block) {
-		passphrase := a.Prompts.KeyPassphrase(a.Settings.PrivateKeyPath)
+		passphrase, err := a.Prompts.KeyPassphrase(a.Settings.PrivateKeyPath)
+		if err != nil {
+			return nil, err
+		}
 		bytes, err = x509.DecryptPEMBlock(block, []byte(passphrase))
 		if err != nil {
 			return nil, err
@@ -149,7 +152,10 @@ func (a *SAuth) signInWithKey() (*signinResponse, error) {
 }
 
 func (a *SAuth) mfaSignin(mfaID string, preferredMode string) (*models.User, error) {
-	token := a.Prompts.OTP(preferredMode)
+	token, err := a.Prompts.OTP(preferredMode)
+	if err != nil {
+		return nil, err
+	}
 	headers := a.Settings.HTTPManager.GetHeaders(a.Settings.SessionToken, a.Settings.Version, a.Settings.Pod, a.Settings.UsersID)
 	b, err := json.Marshal(struct {
 		OTP string `json:"otp"`
@@ -0,0 +1,108 @@
+package credentials
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/Sirupsen/logrus"
+)
+
+const (
+	// Keyring stores credentials in the OS keyring
+	Keyring = "keyring"
+	// File stores credentials in an encrypted file only readable by the owner
+	File = "file"
+)
+
+// ICredentials is a store for secrets such as session tokens that should never
+// be written to the settings file in plain text. Secrets are identified by an
+// account name. Get returns an empty string if nothing is stored for the given
+// account.
+type ICredentials interface {
+	Name() string
+	Get(account string) (string, error)
+	Set(account, secret string) error
+	Delete(account string) error
+}
+
+// New returns the credential store to use. The given store name may be
+// Keyring, File, or empty. When empty, the OS keyring is used if one is
+// available and the encrypted file in the given directory otherwise.
+func New(dir, store string) (ICredentials, error) {
+	file := NewFile(filepath.Join(dir, ".catalyze.credentials"), filepath.Join(dir, ".catalyze.key"))
+	switch store {
+	case Keyring:
+		kr := NewKeyring()
+		if kr == nil {
+			return nil, fmt.Errorf("No OS keyring is available on this machine")
+		}
+		return newCached(kr), nil
+	case File:
+		return newCached(file), nil
+	case "":
+		if kr := NewKeyring(); kr != nil {
+			return newCached(kr), nil
+		}
+		logrus.Debugf("No OS keyring available, storing credentials in %s", file.(*SFile).Path)
+		return newCached(file), nil
+	}
+	return nil, fmt.Errorf("Invalid credential store \"%s\". Supported stores are keyring and file", store)
+}
+
+// cached remembers secrets that have already been read or written so that
+// saving unchanged settings does not touch the underlying store.
+type cached struct {
+	ICredentials
+	values map[string]string
+}
+
+func newCached(store ICredentials) ICredentials {
+	return &cached{
+		ICredentials: store,
+		values:       map[string]string{},
+	}
+}
+
+func (c *cached) Get(account string) (string, error) {
+	if secret, ok := c.values[account]; ok {
+		return secret, nil
+	}
+	secret, err := c.ICredentials.Get(account)
+	if err != nil {
+		return "", err
+	}
+	c.values[account] = secret
+	return secret, nil
+}
+
+func (c *cached) Set(account, secret string) error {
+	if secret == "" {
+		return c.Delete(account)
+	}
+	if current, ok := c.values[account]; ok && current == secret {
+		return nil
+	}
+	if err := c.ICredentials.Set(account, secret); err != nil {
+		return err
+	}
+	c.values[account] = secret
+	return nil
+}
+
+func (c *cached) Delete(account string) error {
+	if current, ok := c.values[account]; ok && current == "" {
+		return nil
+	}
+	if err := c.ICredentials.Delete(account); err != nil {
+		return err
+	}
+	c.values[account] = ""
+	return nil
+}
+
+// exists reports whether the given path exists
+func exists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
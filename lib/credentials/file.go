@@ -0,0 +1,153 @@
+package credentials
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+
+	"github.com/catalyzeio/cli/lib/atomicfile"
+)
+
+// SFile stores credentials encrypted with AES-256-GCM in a file that is only
+// readable by its owner. The encryption key is kept in a separate file, also
+// only readable by its owner, so that copying or syncing the credentials file
+// alone does not expose any secrets.
+type SFile struct {
+	Path    string
+	KeyPath string
+}
+
+// NewFile returns an ICredentials backed by the encrypted file at the given
+// path using the key at keyPath. The key is generated the first time a secret
+// is stored.
+func NewFile(path, keyPath string) ICredentials {
+	return &SFile{
+		Path:    path,
+		KeyPath: keyPath,
+	}
+}
+
+// Name returns the name of this store
+func (f *SFile) Name() string {
+	return File
+}
+
+// Get retrieves the secret for the given account
+func (f *SFile) Get(account string) (string, error) {
+	secrets, err := f.read()
+	if err != nil {
+		return "", err
+	}
+	encrypted, ok := secrets[account]
+	if !ok {
+		return "", nil
+	}
+	gcm, err := f.cipher(false)
+	if err != nil {
+		return "", err
+	}
+	b, err := base64.StdEncoding.DecodeString(encrypted)
+	if err != nil || len(b) < gcm.NonceSize() {
+		return "", fmt.Errorf("The stored credentials for %s are corrupt. Sign in again to replace them", account)
+	}
+	plain, err := gcm.Open(nil, b[:gcm.NonceSize()], b[gcm.NonceSize():], []byte(account))
+	if err != nil {
+		return "", fmt.Errorf("The stored credentials for %s could not be decrypted. Sign in again to replace them", account)
+	}
+	return string(plain), nil
+}
+
+// Set stores the secret for the given account, replacing any existing value
+func (f *SFile) Set(account, secret string) error {
+	return f.update(func(secrets map[string]string) error {
+		// the key is created while holding the lock so concurrent commands
+		// never encrypt with different keys
+		gcm, err := f.cipher(true)
+		if err != nil {
+			return err
+		}
+		nonce := make([]byte, gcm.NonceSize())
+		if _, err = io.ReadFull(rand.Reader, nonce); err != nil {
+			return err
+		}
+		encrypted := gcm.Seal(nonce, nonce, []byte(secret), []byte(account))
+		secrets[account] = base64.StdEncoding.EncodeToString(encrypted)
+		return nil
+	})
+}
+
+// Delete removes the secret for the given account
+func (f *SFile) Delete(account string) error {
+	if !exists(f.Path) {
+		return nil
+	}
+	return f.update(func(secrets map[string]string) error {
+		delete(secrets, account)
+		return nil
+	})
+}
+
+func (f *SFile) read() (map[string]string, error) {
+	secrets := map[string]string{}
+	b, err := ioutil.ReadFile(f.Path)
+	if os.IsNotExist(err) {
+		return secrets, nil
+	} else if err != nil {
+		return nil, err
+	}
+	if len(b) == 0 {
+		return secrets, nil
+	}
+	return secrets, json.Unmarshal(b, &secrets)
+}
+
+func (f *SFile) update(modify func(secrets map[string]string) error) error {
+	unlock, err := atomicfile.Lock(f.Path)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	secrets, err := f.read()
+	if err != nil {
+		return err
+	}
+	if err = modify(secrets); err != nil {
+		return err
+	}
+	b, err := json.Marshal(secrets)
+	if err != nil {
+		return err
+	}
+	return atomicfile.WriteFile(f.Path, b, 0600)
+}
+
+// cipher loads the encryption key, generating and saving a new one if none
+// exists yet and create is true.
+func (f *SFile) cipher(create bool) (cipher.AEAD, error) {
+	key, err := ioutil.ReadFile(f.KeyPath)
+	if os.IsNotExist(err) && create {
+		key = make([]byte, 32)
+		if _, err = io.ReadFull(rand.Reader, key); err != nil {
+			return nil, err
+		}
+		if err = atomicfile.WriteFile(f.KeyPath, key, 0600); err != nil {
+			return nil, err
+		}
+	} else if err != nil {
+		return nil, err
+	}
+	if len(key) != 32 {
+		return nil, fmt.Errorf("The credentials key %s is invalid. Remove it and %s and sign in again", f.KeyPath, f.Path)
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,82 @@
+package credentials
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "credentials")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	store, err := New(dir, File)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	secret, err := store.Get("default")
+	if err != nil || secret != "" {
+		t.Errorf("Expected no secret before one is set. Found: %q, %v", secret, err)
+	}
+	if err = store.Set("default", "session-token"); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	for _, name := range []string{".catalyze.credentials", ".catalyze.key"} {
+		info, err := os.Stat(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+		if info.Mode().Perm() != 0600 {
+			t.Errorf("Expected %s to have mode 0600. Found: %s", name, info.Mode().Perm())
+		}
+	}
+	b, _ := ioutil.ReadFile(filepath.Join(dir, ".catalyze.credentials"))
+	if strings.Contains(string(b), "session-token") {
+		t.Error("Expected the secret to be encrypted on disk")
+	}
+
+	// read back through a fresh store so the cache is not used
+	fresh := NewFile(filepath.Join(dir, ".catalyze.credentials"), filepath.Join(dir, ".catalyze.key"))
+	if secret, err = fresh.Get("default"); err != nil || secret != "session-token" {
+		t.Errorf("Expected the stored secret. Found: %q, %v", secret, err)
+	}
+	if err = store.Delete("default"); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if secret, err = fresh.Get("default"); err != nil || secret != "" {
+		t.Errorf("Expected the secret to be deleted. Found: %q, %v", secret, err)
+	}
+}
+
+func TestFileConcurrentSet(t *testing.T) {
+	dir, err := ioutil.TempDir("", "credentials")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	accounts := []string{"a", "b", "c", "d", "e", "f", "g", "h"}
+	var wg sync.WaitGroup
+	for _, account := range accounts {
+		wg.Add(1)
+		go func(account string) {
+			defer wg.Done()
+			store := NewFile(filepath.Join(dir, ".catalyze.credentials"), filepath.Join(dir, ".catalyze.key"))
+			if err := store.Set(account, "token-"+account); err != nil {
+				t.Errorf("Unexpected error: %s", err)
+			}
+		}(account)
+	}
+	wg.Wait()
+	store := NewFile(filepath.Join(dir, ".catalyze.credentials"), filepath.Join(dir, ".catalyze.key"))
+	for _, account := range accounts {
+		if secret, err := store.Get(account); err != nil || secret != "token-"+account {
+			t.Errorf("Expected the secret for %s to survive concurrent writes. Found: %q, %v", account, secret, err)
+		}
+	}
+}
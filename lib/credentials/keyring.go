@@ -0,0 +1,145 @@
+package credentials
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// service is the name credentials are stored under in the OS keyring
+const service = "catalyze-cli"
+
+// SKeyring stores credentials in the OS keyring through the platform's
+// keyring command line tool. This is the macOS keychain via `security` or the
+// freedesktop secret service (GNOME Keyring, KWallet) via `secret-tool`.
+type SKeyring struct {
+	tool string
+}
+
+// NewKeyring returns an ICredentials backed by the OS keyring or nil if no
+// keyring is available.
+func NewKeyring() ICredentials {
+	switch runtime.GOOS {
+	case "darwin":
+		if path, err := exec.LookPath("security"); err == nil {
+			return &SKeyring{tool: path}
+		}
+	case "linux", "freebsd", "openbsd":
+		// secret-tool needs a session bus to talk to the secret service
+		if os.Getenv("DBUS_SESSION_BUS_ADDRESS") == "" {
+			return nil
+		}
+		if path, err := exec.LookPath("secret-tool"); err == nil {
+			return &SKeyring{tool: path}
+		}
+	}
+	return nil
+}
+
+// Name returns the name of this store
+func (k *SKeyring) Name() string {
+	return Keyring
+}
+
+// Get retrieves the secret for the given account. A missing entry is not an
+// error.
+func (k *SKeyring) Get(account string) (string, error) {
+	var cmd *exec.Cmd
+	if runtime.GOOS == "darwin" {
+		cmd = exec.Command(k.tool, "find-generic-password", "-s", service, "-a", account, "-w")
+	} else {
+		cmd = exec.Command(k.tool, "lookup", "service", service, "account", account)
+	}
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if notFound(err, stderr.String()) {
+			return "", nil
+		}
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("keyring: %s", msg)
+		}
+		return "", err
+	}
+	return strings.TrimRight(string(out), "\r\n"), nil
+}
+
+// notFound returns whether a failed lookup only failed because the entry does
+// not exist. security exits with errSecItemNotFound (44) and secret-tool exits
+// with 1 without printing anything.
+func notFound(err error, stderr string) bool {
+	exitErr, ok := err.(*exec.ExitError)
+	if !ok {
+		return false
+	}
+	if runtime.GOOS == "darwin" {
+		return exitErr.ExitCode() == 44
+	}
+	return exitErr.ExitCode() == 1 && strings.TrimSpace(stderr) == ""
+}
+
+// Set stores the secret for the given account, replacing any existing value.
+// The secret is always written to the tool's stdin so that it never shows up
+// in the process list.
+func (k *SKeyring) Set(account, secret string) error {
+	if runtime.GOOS != "darwin" {
+		cmd := exec.Command(k.tool, "store", "--label=Catalyze CLI", "service", service, "account", account)
+		cmd.Stdin = strings.NewReader(secret)
+		return run(cmd)
+	}
+	// security only takes the password as an argument, so the command is given
+	// to its interactive mode on stdin instead
+	cmd := exec.Command(k.tool, "-i")
+	cmd.Stdin = strings.NewReader(securityCommand("add-generic-password", "-U", "-s", service, "-a", account, "-w", secret) + "\n")
+	// the interactive mode exits with 0 even when a command fails, so anything
+	// written to stderr is the error
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return err
+	}
+	if msg := strings.TrimSpace(stderr.String()); msg != "" {
+		return fmt.Errorf("keyring: %s", msg)
+	}
+	return nil
+}
+
+// securityCommand quotes the arguments of a command for the interactive mode
+// of security
+func securityCommand(args ...string) string {
+	quoted := make([]string, len(args))
+	for i, arg := range args {
+		arg = strings.Replace(arg, `\`, `\\`, -1)
+		arg = strings.Replace(arg, `"`, `\"`, -1)
+		quoted[i] = `"` + arg + `"`
+	}
+	return strings.Join(quoted, " ")
+}
+
+// Delete removes the secret for the given account
+func (k *SKeyring) Delete(account string) error {
+	var cmd *exec.Cmd
+	if runtime.GOOS == "darwin" {
+		cmd = exec.Command(k.tool, "delete-generic-password", "-s", service, "-a", account)
+	} else {
+		cmd = exec.Command(k.tool, "clear", "service", service, "account", account)
+	}
+	cmd.Run()
+	return nil
+}
+
+func run(cmd *exec.Cmd) error {
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return fmt.Errorf("keyring: %s", msg)
+		}
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,20 @@
+package credentials
+
+import "testing"
+
+var securityCommandTests = []struct {
+	args     []string
+	expected string
+}{
+	{[]string{"add-generic-password", "-w", "token"}, `"add-generic-password" "-w" "token"`},
+	{[]string{"-a", `my "profile"`, "-w", `a\b c`}, `"-a" "my \"profile\"" "-w" "a\\b c"`},
+}
+
+func TestSecurityCommand(t *testing.T) {
+	for _, data := range securityCommandTests {
+		t.Logf("Data: %+v", data)
+		if cmd := securityCommand(data.args...); cmd != data.expected {
+			t.Errorf("Expected: %s. Found: %s", data.expected, cmd)
+		}
+	}
+}
@@ -1,8 +1,10 @@
 package crypto
 
 import (
+	"crypto/rsa"
 	"io"
 
+	"github.com/catalyzeio/cli/models"
 	"github.com/catalyzeio/gcm/gcm"
 )
 
@@ -11,7 +13,10 @@ type ICrypto interface {
 	DecryptFile(encryptedFilePath, key, iv, outputFilePath string) error
 	EncryptFile(plainFilePath string, key, iv []byte) (string, error)
 	NewEncryptReader(reader io.Reader, key, iv []byte) (*gcm.EncryptReader, error)
+	NewEncryptReaderAt(reader io.Reader, key, iv []byte, offset, length int64) (io.Reader, int64, error)
 	NewDecryptWriteCloser(writeCloser io.WriteCloser, key, iv string) (*gcm.DecryptWriteCloser, error)
+	WrapKey(store *models.EncryptionStore, publicKey *rsa.PublicKey, passphrase string) (*models.WrappedKey, error)
+	UnwrapKey(wrapped *models.WrappedKey, privateKey *rsa.PrivateKey, passphrase string) (*models.EncryptionStore, error)
 	Hex(src []byte, maxLen int) []byte
 	Unhex(src []byte, maxLen int) []byte
 	Base64Encode(src []byte, maxLen int) []byte
@@ -15,6 +15,12 @@ const (
 	// AADSize is the size in bytes of the Additional Authenticated Data for the
 	// GCM encryption
 	AADSize = 16
+	// ChunkSize is the number of plaintext bytes encrypted under each IV of a
+	// GCM data stream
+	ChunkSize = 1024 * 1024
+	// TagSize is the size in bytes of the authentication tag added to each
+	// chunk of a GCM data stream
+	TagSize = 16
 )
 
 // Hex encode bytes
@@ -44,3 +44,35 @@ func (c *SCrypto) NewEncryptReader(reader io.Reader, key, iv []byte) (*gcm.Encry
 	}
 	return gcm.NewEncryptReader(reader, key, iv, c.Unhex([]byte(gcm.AAD), AADSize))
 }
+
+// NewEncryptReaderAt returns the part of the encrypted stream for the length
+// plaintext bytes that start at the given offset. The reader must supply
+// exactly those bytes and the offset must be a multiple of ChunkSize. This
+// allows any chunk aligned part of a file to be encrypted on its own without
+// encrypting everything before it. Concatenating the parts of a file gives the
+// same result as encrypting the whole file with NewEncryptReader.
+func (c *SCrypto) NewEncryptReaderAt(reader io.Reader, key, iv []byte, offset, length int64) (io.Reader, int64, error) {
+	if offset%ChunkSize != 0 {
+		return nil, 0, fmt.Errorf("Invalid offset %d. Offsets must be a multiple of %d bytes", offset, ChunkSize)
+	}
+	if len(iv) != IVSize {
+		return nil, 0, fmt.Errorf("Invalid IV length. IVs must be %d bytes", IVSize)
+	}
+	// each chunk of the stream is sealed with the previous chunk's IV plus one
+	ivAt := make([]byte, len(iv))
+	copy(ivAt, iv)
+	carry := uint64(offset / ChunkSize)
+	for i := len(ivAt) - 1; i >= 0 && carry > 0; i-- {
+		sum := uint64(ivAt[i]) + carry&0xff
+		ivAt[i] = byte(sum)
+		carry = carry>>8 + sum>>8
+	}
+	er, err := c.NewEncryptReader(reader, key, ivAt)
+	if err != nil {
+		return nil, 0, err
+	}
+	// a reader that ends on a chunk boundary is followed by an empty sealed
+	// chunk which must not appear in the middle of the stream
+	encryptedLength := int64(er.CalculateTotalSize(int(length)))
+	return io.LimitReader(er, encryptedLength), encryptedLength, nil
+}
@@ -0,0 +1,57 @@
+package crypto
+
+import (
+	"bytes"
+	"crypto/rand"
+	"io/ioutil"
+	"testing"
+)
+
+func TestNewEncryptReaderAt(t *testing.T) {
+	c := New()
+	key := make([]byte, KeySize)
+	rand.Read(key)
+	plain := make([]byte, ChunkSize*3+100)
+	rand.Read(plain)
+
+	var encryptAtTests = []struct {
+		iv      []byte
+		partLen int
+	}{
+		{make([]byte, IVSize), ChunkSize},
+		{make([]byte, IVSize), ChunkSize * 2},
+		// the IV carries over into higher bytes as it is incremented
+		{[]byte{0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0xff, 0xfe}, ChunkSize},
+		{[]byte{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff}, ChunkSize},
+	}
+	for _, data := range encryptAtTests {
+		t.Logf("Data: %+v", data.iv)
+		r, err := c.NewEncryptReader(bytes.NewReader(plain), key, data.iv)
+		if err != nil {
+			t.Fatal(err)
+		}
+		whole, _ := ioutil.ReadAll(r)
+		var parts []byte
+		for off := 0; off < len(plain); off += data.partLen {
+			end := off + data.partLen
+			if end > len(plain) {
+				end = len(plain)
+			}
+			r, length, err := c.NewEncryptReaderAt(bytes.NewReader(plain[off:end]), key, data.iv, int64(off), int64(end-off))
+			if err != nil {
+				t.Fatal(err)
+			}
+			part, _ := ioutil.ReadAll(r)
+			if int64(len(part)) != length {
+				t.Errorf("Expected a part of %d bytes. Found: %d", length, len(part))
+			}
+			parts = append(parts, part...)
+		}
+		if !bytes.Equal(whole, parts) {
+			t.Errorf("Expected the encrypted parts to match the encrypted stream")
+		}
+	}
+	if _, _, err := c.NewEncryptReaderAt(bytes.NewReader(plain), key, make([]byte, IVSize), 100, 100); err == nil {
+		t.Error("Expected an error for an offset that is not chunk aligned")
+	}
+}
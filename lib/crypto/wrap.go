@@ -0,0 +1,130 @@
+package crypto
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/catalyzeio/cli/models"
+	"golang.org/x/crypto/pbkdf2"
+)
+
+const (
+	// WrapRSA wraps key material with an RSA public key using RSA-OAEP with
+	// SHA-256
+	WrapRSA = "rsa-oaep-sha256"
+	// WrapPassphrase wraps key material with AES-GCM under a key derived from a
+	// passphrase with PBKDF2-HMAC-SHA256
+	WrapPassphrase = "pbkdf2-sha256-aes-gcm"
+	// WrapIterations is the number of PBKDF2 iterations used when wrapping
+	// with a passphrase
+	WrapIterations = 600000
+	saltSize       = 16
+)
+
+// WrapKey encrypts the key and IV of the given EncryptionStore with the
+// given RSA public key, or with the passphrase if the public key is nil.
+func (c *SCrypto) WrapKey(store *models.EncryptionStore, publicKey *rsa.PublicKey, passphrase string) (*models.WrappedKey, error) {
+	plain, err := json.Marshal(models.EncryptionStore{Key: store.Key, IV: store.IV})
+	if err != nil {
+		return nil, err
+	}
+	if publicKey != nil {
+		ciphertext, err := rsa.EncryptOAEP(sha256.New(), rand.Reader, publicKey, plain, nil)
+		if err != nil {
+			return nil, err
+		}
+		return &models.WrappedKey{
+			Method:     WrapRSA,
+			Ciphertext: base64.StdEncoding.EncodeToString(ciphertext),
+		}, nil
+	}
+	if passphrase == "" {
+		return nil, errors.New("A public key or passphrase is required to wrap a key")
+	}
+	salt := make([]byte, saltSize)
+	if _, err = io.ReadFull(rand.Reader, salt); err != nil {
+		return nil, err
+	}
+	gcm, err := passphraseGCM(passphrase, salt, WrapIterations)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err = io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+	return &models.WrappedKey{
+		Method:     WrapPassphrase,
+		Salt:       base64.StdEncoding.EncodeToString(salt),
+		Iterations: WrapIterations,
+		Nonce:      base64.StdEncoding.EncodeToString(nonce),
+		Ciphertext: base64.StdEncoding.EncodeToString(gcm.Seal(nil, nonce, plain, nil)),
+	}, nil
+}
+
+// UnwrapKey decrypts key material wrapped by WrapKey. The private key is used
+// for keys wrapped with a public key and the passphrase otherwise.
+func (c *SCrypto) UnwrapKey(wrapped *models.WrappedKey, privateKey *rsa.PrivateKey, passphrase string) (*models.EncryptionStore, error) {
+	ciphertext, err := base64.StdEncoding.DecodeString(wrapped.Ciphertext)
+	if err != nil {
+		return nil, err
+	}
+	var plain []byte
+	switch wrapped.Method {
+	case WrapRSA:
+		if privateKey == nil {
+			return nil, errors.New("The key was wrapped with a public key. The matching private key is required to unwrap it")
+		}
+		plain, err = rsa.DecryptOAEP(sha256.New(), rand.Reader, privateKey, ciphertext, nil)
+		if err != nil {
+			return nil, errors.New("Failed to unwrap the key. Make sure the private key matches the public key it was wrapped with")
+		}
+	case WrapPassphrase:
+		salt, err := base64.StdEncoding.DecodeString(wrapped.Salt)
+		if err != nil {
+			return nil, err
+		}
+		nonce, err := base64.StdEncoding.DecodeString(wrapped.Nonce)
+		if err != nil {
+			return nil, err
+		}
+		if wrapped.Iterations < 1 {
+			return nil, fmt.Errorf("Invalid number of iterations %d", wrapped.Iterations)
+		}
+		gcm, err := passphraseGCM(passphrase, salt, wrapped.Iterations)
+		if err != nil {
+			return nil, err
+		}
+		if len(nonce) != gcm.NonceSize() {
+			return nil, errors.New("Invalid nonce size")
+		}
+		plain, err = gcm.Open(nil, nonce, ciphertext, nil)
+		if err != nil {
+			return nil, errors.New("Failed to unwrap the key. Make sure the passphrase is correct")
+		}
+	default:
+		return nil, fmt.Errorf("Unsupported key wrapping method \"%s\"", wrapped.Method)
+	}
+	var store models.EncryptionStore
+	if err = json.Unmarshal(plain, &store); err != nil {
+		return nil, err
+	}
+	return &store, nil
+}
+
+// passphraseGCM returns an AES-256-GCM cipher keyed by the passphrase
+func passphraseGCM(passphrase string, salt []byte, iterations int) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(pbkdf2.Key([]byte(passphrase), salt, iterations, KeySize, sha256.New))
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
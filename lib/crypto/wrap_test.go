@@ -0,0 +1,55 @@
+package crypto
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"testing"
+
+	"github.com/catalyzeio/cli/models"
+)
+
+func TestWrapKey(t *testing.T) {
+	c := New()
+	store := &models.EncryptionStore{Key: "6b6579", IV: "6976", KeyLogs: "not wrapped"}
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var wrapTests = []struct {
+		publicKey        *rsa.PublicKey
+		passphrase       string
+		unwrapPrivateKey *rsa.PrivateKey
+		unwrapPassphrase string
+		expectErr        bool
+	}{
+		{&privateKey.PublicKey, "", privateKey, "", false},
+		{&privateKey.PublicKey, "", otherKey, "", true},
+		{&privateKey.PublicKey, "", nil, "passphrase", true},
+		{nil, "passphrase", nil, "passphrase", false},
+		{nil, "passphrase", nil, "wrong", true},
+	}
+	for _, data := range wrapTests {
+		t.Logf("Data: %+v", data)
+		wrapped, err := c.WrapKey(store, data.publicKey, data.passphrase)
+		if err != nil {
+			t.Errorf("Unexpected error wrapping: %s", err)
+			continue
+		}
+		unwrapped, err := c.UnwrapKey(wrapped, data.unwrapPrivateKey, data.unwrapPassphrase)
+		if err != nil != data.expectErr {
+			t.Errorf("Unexpected error unwrapping: %v", err)
+			continue
+		}
+		if !data.expectErr && (unwrapped.Key != store.Key || unwrapped.IV != store.IV || unwrapped.KeyLogs != "") {
+			t.Errorf("Expected only the key and IV to be wrapped. Found: %+v", unwrapped)
+		}
+	}
+	if _, err := c.WrapKey(store, nil, ""); err == nil {
+		t.Error("Expected an error wrapping without a public key or passphrase")
+	}
+}
@@ -2,21 +2,24 @@ package httpclient
 
 import (
 	"bytes"
+	"context"
 	"crypto/rand"
 	"crypto/tls"
 	"encoding/base64"
 	"encoding/json"
-	"errors"
 	"fmt"
 	"io"
 	"io/ioutil"
 	"net/http"
+	"net/url"
 	"os"
 	"runtime"
+	"sync/atomic"
 	"time"
 
 	"github.com/Sirupsen/logrus"
 	"github.com/catalyzeio/cli/config"
+	"github.com/catalyzeio/cli/lib/interrupt"
 	"github.com/catalyzeio/cli/lib/updater"
 	"github.com/catalyzeio/cli/models"
 )
@@ -25,11 +28,23 @@ const defaultRedirectLimit = 10
 
 type TLSHTTPManager struct {
 	client *http.Client
+	retry  *RetryPolicy
+	ctx    context.Context
+	// signin signs in again and returns the new session token. It is called
+	// when a request is rejected because the session expired.
+	signin func() (string, error)
+	// signingIn is shared by every copy of the manager and is set while signin
+	// runs so that its own requests never cause another signin
+	signingIn *int32
 }
 
 // NewTLSHTTPManager constructs and returns a new instance of HTTPManager
-// with TLSv1.2 and redirect support.
-func NewTLSHTTPManager(skipVerify bool) models.HTTPManager {
+// with TLSv1.2 and redirect support. Requests are made with the interrupt
+// package's root context so they stop when the CLI is interrupted. If a
+// request fails with a 401 because the session expired, signin is called to
+// sign in again and the request is retried once with the new session token.
+// signin may be nil to never sign in again.
+func NewTLSHTTPManager(skipVerify bool, signin func() (string, error)) models.HTTPManager {
 	var tr = &http.Transport{
 		TLSClientConfig: &tls.Config{
 			MinVersion: tls.VersionTLS12,
@@ -43,9 +58,26 @@ func NewTLSHTTPManager(skipVerify bool) models.HTTPManager {
 			Transport:     tr,
 			CheckRedirect: redirectPolicyFunc,
 		},
+		retry:     NewRetryPolicy(),
+		ctx:       interrupt.Context(),
+		signin:    signin,
+		signingIn: new(int32),
 	}
 }
 
+// Context returns the context requests are made with
+func (m *TLSHTTPManager) Context() context.Context {
+	return m.ctx
+}
+
+// WithContext returns a copy of the manager that makes requests with the
+// given context, such as one with a timeout for a single command
+func (m *TLSHTTPManager) WithContext(ctx context.Context) models.HTTPManager {
+	bound := *m
+	bound.ctx = ctx
+	return &bound
+}
+
 func redirectPolicyFunc(req *http.Request, via []*http.Request) error {
 	if len(via) == 0 {
 		// No redirects
@@ -88,8 +120,8 @@ func (m *TLSHTTPManager) GetHeaders(sessionToken, version, pod, userID string) m
 // pointer your original object will be nil or an empty struct.
 func (m *TLSHTTPManager) ConvertResp(b []byte, statusCode int, s interface{}) error {
 	logrus.Debugf("%d resp: %s", statusCode, string(b))
-	if m.isError(statusCode) {
-		return m.convertError(b, statusCode)
+	if isError(statusCode) {
+		return convertError(b, statusCode)
 	}
 	if b == nil || len(b) == 0 || s == nil {
 		return nil
@@ -98,106 +130,183 @@ func (m *TLSHTTPManager) ConvertResp(b []byte, statusCode int, s interface{}) er
 }
 
 // isError checks if an HTTP response code is outside of the "OK" range.
-func (m *TLSHTTPManager) isError(statusCode int) bool {
+func isError(statusCode int) bool {
 	return statusCode < 200 || statusCode >= 300
 }
 
-// convertError attempts to convert a response into a usable error object.
-func (m *TLSHTTPManager) convertError(b []byte, statusCode int) error {
-	msg := fmt.Sprintf("(%d)", statusCode)
-	if b != nil && len(b) > 0 {
-		var errs models.Error
-		unmarshalErr := json.Unmarshal(b, &errs)
-		if unmarshalErr == nil && errs.Title != "" && errs.Description != "" {
-			msg = fmt.Sprintf("(%d) %s: %s", errs.Code, errs.Title, errs.Description)
-		} else {
-			var reportedErr models.ReportedError
-			unmarshalErr = json.Unmarshal(b, &reportedErr)
-			if unmarshalErr == nil && reportedErr.Message != "" {
-				msg = fmt.Sprintf("(%d) %s", reportedErr.Code, reportedErr.Message)
-			} else {
-				msg = fmt.Sprintf("(%d) %s", statusCode, string(b))
-			}
-		}
-	}
-	return errors.New(msg)
-}
-
-// Get performs a GET request
+// Get performs a GET request. Like every request method, the body and status
+// of an unsuccessful response are returned along with an *APIError.
 func (m *TLSHTTPManager) Get(body []byte, url string, headers map[string][]string) ([]byte, int, error) {
-	reader := bytes.NewReader(body)
-	return m.makeRequest("GET", url, reader, headers)
+	b, statusCode, err := m.makeRequest("GET", url, body, headers)
+	return b, statusCode, checkStatus("GET", url, b, statusCode, err)
 }
 
 // Post performs a POST request
 func (m *TLSHTTPManager) Post(body []byte, url string, headers map[string][]string) ([]byte, int, error) {
-	reader := bytes.NewReader(body)
-	return m.makeRequest("POST", url, reader, headers)
+	b, statusCode, err := m.makeRequest("POST", url, body, headers)
+	return b, statusCode, checkStatus("POST", url, b, statusCode, err)
 }
 
 // PostFile uploads a file with a POST
 func (m *TLSHTTPManager) PostFile(filepath string, url string, headers map[string][]string) ([]byte, int, error) {
-	return m.uploadFile("POST", filepath, url, headers)
+	b, statusCode, err := m.uploadFile("POST", filepath, url, headers)
+	return b, statusCode, checkStatus("POST", url, b, statusCode, err)
 }
 
 // PutFile uploads a file with a PUT
 func (m *TLSHTTPManager) PutFile(filepath string, url string, headers map[string][]string) ([]byte, int, error) {
-	return m.uploadFile("PUT", filepath, url, headers)
+	b, statusCode, err := m.uploadFile("PUT", filepath, url, headers)
+	return b, statusCode, checkStatus("PUT", url, b, statusCode, err)
+}
+
+// checkStatus returns the error of a request, which is an *APIError if the
+// request was sent but its response was unsuccessful
+func checkStatus(method, rawURL string, b []byte, statusCode int, err error) error {
+	if err != nil || !isError(statusCode) {
+		return err
+	}
+	apiErr := convertError(b, statusCode)
+	apiErr.Method = method
+	if u, parseErr := url.Parse(rawURL); parseErr == nil {
+		apiErr.Path = u.Path
+	}
+	logrus.Debugf("%s %s failed: %s", method, apiErr.Path, apiErr)
+	return apiErr
 }
 
 func (m *TLSHTTPManager) uploadFile(method, filepath, url string, headers map[string][]string) ([]byte, int, error) {
 	logrus.Debugf("%s %s", method, url)
 	logrus.Debugf("%+v", headers)
 	logrus.Debugf("%s", filepath)
-	file, err := os.Open(filepath)
-	defer file.Close()
-	if err != nil {
-		return nil, 0, err
-	}
-	info, _ := file.Stat()
-	req, _ := http.NewRequest(method, url, file)
-	req.ContentLength = info.Size()
-
-	resp, err := m.client.Do(req)
-	if err != nil {
-		return nil, 0, err
-	}
-	defer resp.Body.Close()
-	respBody, _ := ioutil.ReadAll(resp.Body)
-	return respBody, resp.StatusCode, nil
+	return m.do(method, url, nil, func() (io.ReadCloser, int64, error) {
+		file, err := os.Open(filepath)
+		if err != nil {
+			return nil, 0, err
+		}
+		info, err := file.Stat()
+		if err != nil {
+			file.Close()
+			return nil, 0, err
+		}
+		return file, info.Size(), nil
+	})
 }
 
 // Put performs a PUT request
 func (m *TLSHTTPManager) Put(body []byte, url string, headers map[string][]string) ([]byte, int, error) {
-	reader := bytes.NewReader(body)
-	return m.makeRequest("PUT", url, reader, headers)
+	b, statusCode, err := m.makeRequest("PUT", url, body, headers)
+	return b, statusCode, checkStatus("PUT", url, b, statusCode, err)
 }
 
 // Delete performs a DELETE request
 func (m *TLSHTTPManager) Delete(body []byte, url string, headers map[string][]string) ([]byte, int, error) {
-	reader := bytes.NewReader(body)
-	return m.makeRequest("DELETE", url, reader, headers)
+	b, statusCode, err := m.makeRequest("DELETE", url, body, headers)
+	return b, statusCode, checkStatus("DELETE", url, b, statusCode, err)
 }
 
 // MakeRequest is a generic HTTP runner that performs a request and returns
 // the result body as a byte array. It's up to the caller to transform them
 // into an object.
-func (m *TLSHTTPManager) makeRequest(method string, url string, body io.Reader, headers map[string][]string) ([]byte, int, error) {
+func (m *TLSHTTPManager) makeRequest(method string, url string, body []byte, headers map[string][]string) ([]byte, int, error) {
 	logrus.Debugf("%s %s", method, url)
 	logrus.Debugf("%+v", headers)
 	logrus.Debugf("%s", body)
-	req, _ := http.NewRequest(method, url, body)
-	req.Header = headers
+	return m.do(method, url, headers, func() (io.ReadCloser, int64, error) {
+		return ioutil.NopCloser(bytes.NewReader(body)), int64(len(body)), nil
+	})
+}
+
+// do sends a request, retrying it according to the manager's RetryPolicy. The
+// body func is called once per attempt so that every attempt starts reading
+// the body from the beginning.
+func (m *TLSHTTPManager) do(method, url string, headers map[string][]string, body func() (io.ReadCloser, int64, error)) ([]byte, int, error) {
+	signedIn := false
+	for attempt := 0; ; attempt++ {
+		reader, length, err := body()
+		if err != nil {
+			return nil, 0, err
+		}
+		req, err := http.NewRequest(method, url, nil)
+		if err != nil {
+			reader.Close()
+			return nil, 0, err
+		}
+		req = req.WithContext(m.ctx)
+		if length > 0 {
+			req.Body = reader
+			req.ContentLength = length
+		} else {
+			reader.Close()
+		}
+		if headers != nil {
+			req.Header = headers
+		}
+
+		resp, err := m.client.Do(req)
+		if ctxErr := interrupt.Err(m.ctx); ctxErr != nil {
+			if err == nil {
+				resp.Body.Close()
+			}
+			return nil, 0, ctxErr
+		}
+		if m.retry.ShouldRetry(method, attempt, resp, err) {
+			wait := m.retry.Backoff(attempt, resp)
+			if err != nil {
+				logrus.Debugf("%s %s failed: %s. Retrying in %s", method, url, err, wait)
+			} else {
+				resp.Body.Close()
+				logrus.Debugf("%s %s returned %d. Retrying in %s", method, url, resp.StatusCode, wait)
+			}
+			if err = interrupt.Sleep(m.ctx, wait); err != nil {
+				return nil, 0, err
+			}
+			continue
+		}
+		if err != nil {
+			return nil, 0, err
+		}
+		if resp.StatusCode == http.StatusUnauthorized && !signedIn {
+			if renewed, ok := m.renewSession(headers); ok {
+				resp.Body.Close()
+				headers = renewed
+				signedIn = true
+				continue
+			}
+		}
+		defer resp.Body.Close()
+		respBody, _ := ioutil.ReadAll(resp.Body)
+		if resp.StatusCode == 412 {
+			updater.AutoUpdater.ForcedUpgrade()
+			return nil, 0, fmt.Errorf("A required update has been applied. Please re-run this command.")
+		}
+		return respBody, resp.StatusCode, nil
+	}
+}
 
-	resp, err := m.client.Do(req)
+// renewSession signs in again after a request was rejected with a 401 and
+// returns a copy of the request's headers with the new session token. It
+// returns false if the request had no session token, or if signing in failed
+// or did not give a new session token.
+func (m *TLSHTTPManager) renewSession(headers map[string][]string) (map[string][]string, bool) {
+	authorization := headers["Authorization"]
+	if m.signin == nil || len(authorization) == 0 || !atomic.CompareAndSwapInt32(m.signingIn, 0, 1) {
+		return nil, false
+	}
+	defer atomic.StoreInt32(m.signingIn, 0)
+	logrus.Debugln("The session was rejected, signing in again")
+	token, err := m.signin()
 	if err != nil {
-		return nil, 0, err
+		logrus.Debugf("Failed to sign in again: %s", err)
+		return nil, false
+	}
+	renewed := fmt.Sprintf("Bearer %s", token)
+	if token == "" || renewed == authorization[0] {
+		return nil, false
 	}
-	defer resp.Body.Close()
-	respBody, _ := ioutil.ReadAll(resp.Body)
-	if resp.StatusCode == 412 {
-		updater.AutoUpdater.ForcedUpgrade()
-		return nil, 0, fmt.Errorf("A required update has been applied. Please re-run this command.")
+	copied := map[string][]string{}
+	for key, val := range headers {
+		copied[key] = val
 	}
-	return respBody, resp.StatusCode, nil
+	copied["Authorization"] = []string{renewed}
+	return copied, true
 }
@@ -0,0 +1,178 @@
+package httpclient
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/catalyzeio/cli/config"
+	"github.com/catalyzeio/cli/lib/interrupt"
+	"github.com/catalyzeio/cli/models"
+)
+
+// Exit codes of commands that fail because of an error returned by the API.
+// Every other failure exits with 1.
+const (
+	ExitCodeUnauthorized = 3
+	ExitCodeForbidden    = 4
+	ExitCodeNotFound     = 5
+	ExitCodeConflict     = 6
+)
+
+// APIError is an unsuccessful response from one of the Catalyze APIs
+type APIError struct {
+	// StatusCode is the HTTP status of the response
+	StatusCode int
+	// Code is the error code given by the API, which is the HTTP status if the
+	// API did not give one
+	Code        int
+	Title       string
+	Description string
+	// Method and Path are the method and URL path of the failed request. They
+	// are empty for errors converted straight from a response body.
+	Method string
+	Path   string
+}
+
+func (e *APIError) Error() string {
+	switch {
+	case e.Title != "" && e.Description != "":
+		return fmt.Sprintf("(%d) %s: %s", e.Code, e.Title, e.Description)
+	case e.Description != "":
+		return fmt.Sprintf("(%d) %s", e.Code, e.Description)
+	}
+	return fmt.Sprintf("(%d)", e.Code)
+}
+
+// IsUnauthorized returns whether err is an API error for a request made
+// without a valid session
+func IsUnauthorized(err error) bool {
+	return hasStatus(err, http.StatusUnauthorized)
+}
+
+// IsForbidden returns whether err is an API error for a request the user does
+// not have permission to make
+func IsForbidden(err error) bool {
+	return hasStatus(err, http.StatusForbidden)
+}
+
+// IsNotFound returns whether err is an API error for something that does not
+// exist, usually because of a wrong label or ID
+func IsNotFound(err error) bool {
+	return hasStatus(err, http.StatusNotFound)
+}
+
+// IsConflict returns whether err is an API error for a request that conflicts
+// with the current state of what it changes
+func IsConflict(err error) bool {
+	return hasStatus(err, http.StatusConflict)
+}
+
+func hasStatus(err error, statusCode int) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.StatusCode == statusCode
+}
+
+// ExitCode returns the exit code for a command that failed with err
+func ExitCode(err error) int {
+	switch {
+	case IsUnauthorized(err):
+		return ExitCodeUnauthorized
+	case IsForbidden(err):
+		return ExitCodeForbidden
+	case IsNotFound(err):
+		return ExitCodeNotFound
+	case IsConflict(err):
+		return ExitCodeConflict
+	}
+	return 1
+}
+
+// Hint returns a suggestion for how to resolve err, or an empty string if
+// there is none. Not found and conflict errors from the API already say what
+// was wrong so they are left to the commands, which know what was asked for.
+func Hint(err error) string {
+	switch {
+	case IsUnauthorized(err):
+		return fmt.Sprintf("Your session is no longer valid and you could not be signed in again. Run the command again to sign in, or check the %s and %s environment variables.", config.CatalyzeUsernameEnvVar, config.CatalyzePasswordEnvVar)
+	case IsForbidden(err):
+		return "You do not have permission to do this. Ask an admin of the environment's organization for access."
+	}
+	return ""
+}
+
+// WithHint adds a hint from a command, which knows what was asked for, to an
+// error. The hint is shown on its own line after the error and the error's
+// exit code is kept.
+func WithHint(err error, hint string) error {
+	return &hintedError{err: err, hint: hint}
+}
+
+type hintedError struct {
+	err  error
+	hint string
+}
+
+func (e *hintedError) Error() string {
+	return fmt.Sprintf("%s\n%s", e.err, e.hint)
+}
+
+func (e *hintedError) Unwrap() error {
+	return e.err
+}
+
+// convertError builds an APIError from the body of an unsuccessful response
+func convertError(b []byte, statusCode int) *APIError {
+	apiErr := &APIError{StatusCode: statusCode, Code: statusCode}
+	if len(b) == 0 {
+		return apiErr
+	}
+	var errs models.Error
+	if err := json.Unmarshal(b, &errs); err == nil && errs.Title != "" && errs.Description != "" {
+		apiErr.Code = errs.Code
+		apiErr.Title = errs.Title
+		apiErr.Description = errs.Description
+		return apiErr
+	}
+	var reportedErr models.ReportedError
+	if err := json.Unmarshal(b, &reportedErr); err == nil && reportedErr.Message != "" {
+		apiErr.Code = reportedErr.Code
+		apiErr.Description = reportedErr.Message
+		return apiErr
+	}
+	apiErr.Description = string(b)
+	return apiErr
+}
+
+// exit is replaced in tests so that Fatal does not end the test binary
+var exit = os.Exit
+
+// Fatal logs the error a command failed with, along with a hint for API
+// errors the command did not already add one to, and exits with the error's
+// exit code. Commands call Fatal rather than logrus.Fatal, which always exits
+// with 1.
+func Fatal(err error) {
+	msg := err.Error()
+	var hinted *hintedError
+	if hint := Hint(err); hint != "" && !errors.As(err, &hinted) {
+		msg = fmt.Sprintf("%s\n%s", msg, hint)
+	}
+	// let the cleanups of an interrupted command finish first, as
+	// logrus.Fatal does
+	interrupt.Wait()
+	// logrus.Fatal always exits with 1, so the message is written at the
+	// fatal level here before exiting with the error's code instead
+	logger := logrus.StandardLogger()
+	entry := logrus.NewEntry(logger)
+	entry.Time = time.Now()
+	entry.Level = logrus.FatalLevel
+	entry.Message = msg
+	if b, formatErr := logger.Formatter.Format(entry); formatErr == nil {
+		logger.Out.Write(b)
+	}
+	exit(ExitCode(err))
+}
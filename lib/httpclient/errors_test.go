@@ -0,0 +1,98 @@
+package httpclient
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/Sirupsen/logrus"
+)
+
+var apiErrorTests = []struct {
+	body             string
+	statusCode       int
+	expectedMessage  string
+	expectedExitCode int
+}{
+	{`{"title":"Service Not Found","description":"No service with the given ID","code":93001}`, 404, "(93001) Service Not Found: No service with the given ID", ExitCodeNotFound},
+	{`{"id":1001,"message":"A valid session token is required"}`, 401, "(1001) A valid session token is required", ExitCodeUnauthorized},
+	{"forbidden", 403, "(403) forbidden", ExitCodeForbidden},
+	{"", 409, "(409)", ExitCodeConflict},
+	{`{"title":"Internal Error","description":"Something went wrong","code":500}`, 500, "(500) Internal Error: Something went wrong", 1},
+}
+
+func TestCheckStatus(t *testing.T) {
+	for _, data := range apiErrorTests {
+		t.Logf("Data: %+v", data)
+		err := checkStatus("GET", "https://paas.example.com/v1/environments/env1/services?page=1", []byte(data.body), data.statusCode, nil)
+		apiErr, ok := err.(*APIError)
+		if !ok {
+			t.Errorf("Expected an *APIError. Found: %#v", err)
+			continue
+		}
+		if apiErr.Error() != data.expectedMessage {
+			t.Errorf("Expected: %s. Found: %s", data.expectedMessage, apiErr.Error())
+		}
+		if apiErr.StatusCode != data.statusCode || apiErr.Method != "GET" || apiErr.Path != "/v1/environments/env1/services" {
+			t.Errorf("Unexpected request details: %+v", apiErr)
+		}
+		wrapped := WithHint(fmt.Errorf("Failed to list services: %w", err), "A hint")
+		if code := ExitCode(wrapped); code != data.expectedExitCode {
+			t.Errorf("Expected exit code %d. Found: %d", data.expectedExitCode, code)
+		}
+	}
+	if err := checkStatus("GET", "https://paas.example.com", []byte("{}"), 200, nil); err != nil {
+		t.Errorf("Unexpected error for a successful response: %s", err)
+	}
+	requestErr := errors.New("connection refused")
+	if err := checkStatus("GET", "https://paas.example.com", nil, 0, requestErr); err != requestErr {
+		t.Errorf("Expected the request error to be passed on. Found: %v", err)
+	}
+	if code := ExitCode(requestErr); code != 1 {
+		t.Errorf("Expected exit code 1 for other errors. Found: %d", code)
+	}
+}
+
+// messageFormatter writes the level and message of log entries
+type messageFormatter struct{}
+
+func (messageFormatter) Format(entry *logrus.Entry) ([]byte, error) {
+	return []byte(fmt.Sprintf("[%s] %s", entry.Level, entry.Message)), nil
+}
+
+var fatalTests = []struct {
+	err              error
+	expectedOutput   string
+	expectedExitCode int
+}{
+	{errors.New("--parallel must be at least 1"), "[fatal] --parallel must be at least 1", 1},
+	{fmt.Errorf("Failed to list services: %w", &APIError{StatusCode: 403, Code: 403, Description: "forbidden"}), "[fatal] Failed to list services: (403) forbidden\nYou do not have permission to do this. Ask an admin of the environment's organization for access.", ExitCodeForbidden},
+	{WithHint(&APIError{StatusCode: 403, Code: 403, Description: "forbidden"}, "Only admins can do this."), "[fatal] (403) forbidden\nOnly admins can do this.", ExitCodeForbidden},
+	{WithHint(&APIError{StatusCode: 404, Code: 404, Description: "missing"}, "A hint"), "[fatal] (404) missing\nA hint", ExitCodeNotFound},
+}
+
+func TestFatal(t *testing.T) {
+	logger := logrus.StandardLogger()
+	out, formatter := logger.Out, logger.Formatter
+	defer func() {
+		logger.Out, logger.Formatter = out, formatter
+		exit = os.Exit
+	}()
+	logger.Formatter = messageFormatter{}
+	for _, data := range fatalTests {
+		t.Logf("Data: %+v", data)
+		buf := &bytes.Buffer{}
+		logger.Out = buf
+		code := 0
+		exit = func(c int) { code = c }
+		Fatal(data.err)
+		if code != data.expectedExitCode {
+			t.Errorf("Expected exit code %d. Found: %d", data.expectedExitCode, code)
+		}
+		if buf.String() != data.expectedOutput {
+			t.Errorf("Expected: %s. Found: %s", data.expectedOutput, buf.String())
+		}
+	}
+}
@@ -0,0 +1,125 @@
+package httpclient
+
+import (
+	"errors"
+	"math/rand"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/catalyzeio/cli/config"
+)
+
+// RetryPolicy determines which failed requests are retried and how long to
+// wait between attempts. Waits grow exponentially from Wait up to MaxWait with
+// random jitter applied. A Retry-After header sent by the server always takes
+// precedence over the computed wait, but is still capped at MaxWait.
+type RetryPolicy struct {
+	MaxRetries int
+	Wait       time.Duration
+	MaxWait    time.Duration
+}
+
+// NewRetryPolicy builds a RetryPolicy from the defaults in the config package,
+// overridden by any of the retry env variables that are set.
+func NewRetryPolicy() *RetryPolicy {
+	return &RetryPolicy{
+		MaxRetries: envInt(config.HTTPMaxRetriesEnvVar, config.HTTPMaxRetries),
+		Wait:       time.Duration(envInt(config.HTTPRetryWaitEnvVar, config.HTTPRetryWait)) * time.Millisecond,
+		MaxWait:    time.Duration(envInt(config.HTTPRetryMaxWaitEnvVar, config.HTTPRetryMaxWait)) * time.Millisecond,
+	}
+}
+
+func envInt(name string, defaultValue int) int {
+	if v, err := strconv.Atoi(os.Getenv(name)); err == nil && v >= 0 {
+		return v
+	}
+	return defaultValue
+}
+
+// isIdempotent reports whether a request with the given method can safely be
+// sent more than once.
+func isIdempotent(method string) bool {
+	switch method {
+	case "GET", "HEAD", "OPTIONS", "PUT", "DELETE":
+		return true
+	}
+	return false
+}
+
+// ShouldRetry decides whether a request that has been attempted attempt+1
+// times should be sent again. Idempotent requests are retried on connection
+// errors and on 429, 502, 503, and 504 responses. Anything else, most notably
+// POSTs such as imports, backups, and consoles, is only retried when the
+// server could not have acted on it: the connection was never established,
+// the request was rate limited, or the service explicitly asked to be retried
+// later with a 503 and a Retry-After header.
+func (p *RetryPolicy) ShouldRetry(method string, attempt int, resp *http.Response, err error) bool {
+	if attempt >= p.MaxRetries {
+		return false
+	}
+	idempotent := isIdempotent(method)
+	if err != nil {
+		if idempotent {
+			return true
+		}
+		var opErr *net.OpError
+		return errors.As(err, &opErr) && opErr.Op == "dial"
+	}
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests:
+		return true
+	case http.StatusServiceUnavailable:
+		return idempotent || resp.Header.Get("Retry-After") != ""
+	case http.StatusBadGateway, http.StatusGatewayTimeout:
+		return idempotent
+	}
+	return false
+}
+
+// Backoff returns how long to wait before sending the next attempt.
+func (p *RetryPolicy) Backoff(attempt int, resp *http.Response) time.Duration {
+	if resp != nil {
+		if wait, ok := retryAfter(resp.Header.Get("Retry-After")); ok {
+			if wait > p.MaxWait {
+				wait = p.MaxWait
+			}
+			return wait
+		}
+	}
+	wait := p.Wait
+	for i := 0; i < attempt && wait < p.MaxWait; i++ {
+		wait *= 2
+	}
+	if wait > p.MaxWait {
+		wait = p.MaxWait
+	}
+	if wait <= 0 {
+		return 0
+	}
+	// wait somewhere between half and all of the computed amount so concurrent
+	// clients don't retry in lockstep
+	half := wait / 2
+	return half + time.Duration(rand.Int63n(int64(wait-half)+1))
+}
+
+// retryAfter parses a Retry-After header value which may either be a number
+// of seconds or an HTTP date.
+func retryAfter(value string) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if t, err := http.ParseTime(value); err == nil {
+		wait := t.Sub(time.Now())
+		if wait < 0 {
+			wait = 0
+		}
+		return wait, true
+	}
+	return 0, false
+}
@@ -0,0 +1,157 @@
+package httpclient
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/catalyzeio/cli/lib/interrupt"
+)
+
+var retryTests = []struct {
+	method           string
+	statusCode       int
+	retryAfter       string
+	expectedAttempts int32
+}{
+	{"GET", 503, "", 4},
+	{"GET", 502, "", 4},
+	{"GET", 504, "", 4},
+	{"GET", 429, "", 4},
+	{"GET", 404, "", 1},
+	{"PUT", 503, "", 4},
+	{"DELETE", 504, "", 4},
+	{"POST", 429, "", 4},
+	{"POST", 502, "", 1},
+	{"POST", 503, "", 1},
+	{"POST", 503, "0", 4},
+	{"POST", 500, "", 1},
+}
+
+func TestRetry(t *testing.T) {
+	for _, data := range retryTests {
+		t.Logf("Data: %+v", data)
+		var attempts int32
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt32(&attempts, 1)
+			if data.retryAfter != "" {
+				w.Header().Set("Retry-After", data.retryAfter)
+			}
+			w.WriteHeader(data.statusCode)
+		}))
+		m := &TLSHTTPManager{
+			client: ts.Client(),
+			retry:  &RetryPolicy{MaxRetries: 3, Wait: time.Millisecond, MaxWait: 5 * time.Millisecond},
+			ctx:    context.Background(),
+		}
+		_, statusCode, err := m.makeRequest(data.method, ts.URL, []byte("{}"), map[string][]string{})
+		ts.Close()
+		if err != nil {
+			t.Errorf("Unexpected error: %s", err)
+			continue
+		}
+		if statusCode != data.statusCode {
+			t.Errorf("Expected status %d. Found: %d", data.statusCode, statusCode)
+		}
+		if attempts != data.expectedAttempts {
+			t.Errorf("Expected %d attempts. Found: %d", data.expectedAttempts, attempts)
+		}
+	}
+}
+
+func TestRetryRecovers(t *testing.T) {
+	var attempts int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&attempts, 1) < 3 {
+			w.WriteHeader(503)
+			return
+		}
+		w.Write([]byte(`{"ok":true}`))
+	}))
+	defer ts.Close()
+	m := &TLSHTTPManager{
+		client: ts.Client(),
+		retry:  &RetryPolicy{MaxRetries: 3, Wait: time.Millisecond, MaxWait: 5 * time.Millisecond},
+		ctx:    context.Background(),
+	}
+	b, statusCode, err := m.Get(nil, ts.URL, map[string][]string{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if statusCode != 200 || string(b) != `{"ok":true}` {
+		t.Errorf("Expected a successful response after retrying. Found: %d %s", statusCode, string(b))
+	}
+}
+
+func TestContextStopsRequests(t *testing.T) {
+	release := make(chan struct{})
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/slow" {
+			<-release
+		}
+		w.WriteHeader(503)
+	}))
+	defer ts.Close()
+	defer close(release)
+	var contextTests = []struct {
+		path        string
+		timeout     time.Duration
+		expectedErr error
+	}{
+		// the request itself is stopped
+		{"/slow", 50 * time.Millisecond, interrupt.ErrTimeout},
+		// the wait between retries is stopped
+		{"/unavailable", 50 * time.Millisecond, interrupt.ErrTimeout},
+	}
+	for _, data := range contextTests {
+		t.Logf("Data: %+v", data)
+		ctx, cancel := context.WithTimeout(context.Background(), data.timeout)
+		m := (&TLSHTTPManager{
+			client: ts.Client(),
+			retry:  &RetryPolicy{MaxRetries: 3, Wait: time.Minute, MaxWait: time.Minute},
+			ctx:    context.Background(),
+		}).WithContext(ctx)
+		start := time.Now()
+		_, _, err := m.Get(nil, ts.URL+data.path, map[string][]string{})
+		cancel()
+		if err != data.expectedErr {
+			t.Errorf("Expected error %v. Found: %v", data.expectedErr, err)
+		}
+		if elapsed := time.Since(start); elapsed > 5*time.Second {
+			t.Errorf("Expected the request to stop with its context. Took %s", elapsed)
+		}
+	}
+}
+
+var backoffTests = []struct {
+	attempt    int
+	retryAfter string
+	min        time.Duration
+	max        time.Duration
+}{
+	{0, "", 50 * time.Millisecond, 100 * time.Millisecond},
+	{1, "", 100 * time.Millisecond, 200 * time.Millisecond},
+	{2, "", 200 * time.Millisecond, 400 * time.Millisecond},
+	{10, "", 500 * time.Millisecond, time.Second},
+	{0, "0", 0, 0},
+	{0, "1", 1 * time.Second, 1 * time.Second},
+	{0, "120", time.Second, time.Second},
+}
+
+func TestBackoff(t *testing.T) {
+	p := &RetryPolicy{MaxRetries: 3, Wait: 100 * time.Millisecond, MaxWait: time.Second}
+	for _, data := range backoffTests {
+		t.Logf("Data: %+v", data)
+		resp := &http.Response{Header: http.Header{}}
+		if data.retryAfter != "" {
+			resp.Header.Set("Retry-After", data.retryAfter)
+		}
+		wait := p.Backoff(data.attempt, resp)
+		if wait < data.min || wait > data.max {
+			t.Errorf("Expected a wait between %s and %s. Found: %s", data.min, data.max, wait)
+		}
+	}
+}
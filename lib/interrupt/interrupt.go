@@ -0,0 +1,202 @@
+// Package interrupt cancels the running command when the CLI receives an
+// interrupt and cleans up after it before exiting.
+//
+// Every request and job poll is made with the root context, or one derived
+// from it, so an interrupt stops the command wherever it is. Cleanups are then
+// run in order: first the jobs phase, which deletes remote work such as
+// console jobs, then the files phase, which removes partially written local
+// files. Finally the process exits with ExitCode.
+package interrupt
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/signal"
+	"sync"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/catalyzeio/cli/models"
+)
+
+// ExitCode is the exit code of a command that was interrupted. It matches the
+// code shells use for processes killed by SIGINT.
+const ExitCode = 130
+
+// cleanupTimeout is the longest the cleanups together may take
+const cleanupTimeout = 30 * time.Second
+
+// Phase is the stage of the cleanup a func runs in
+type Phase int
+
+const (
+	// Jobs cleanups stop remote work started by the command and run first
+	Jobs Phase = iota
+	// Files cleanups remove partially written local files and run after jobs
+	Files
+)
+
+// ErrInterrupted is returned by operations stopped by an interrupt
+var ErrInterrupted = errors.New("Interrupted")
+
+// ErrTimeout is returned by operations stopped by a --timeout
+var ErrTimeout = errors.New("Timed out before the command finished. Use --timeout to allow it more time")
+
+type cleanup struct {
+	f func(ctx context.Context) error
+}
+
+var (
+	root, cancelRoot = context.WithCancel(context.Background())
+	interrupted      = make(chan struct{})
+	mu               sync.Mutex
+	cleanups         = map[Phase][]*cleanup{}
+	listenOnce       sync.Once
+	exit             = os.Exit
+)
+
+// Context returns the root context of the command, which is cancelled when
+// the CLI is interrupted
+func Context() context.Context {
+	return root
+}
+
+// WithTimeout returns a context derived from the root context that is also
+// cancelled after the given number of seconds. A timeout of 0 never expires.
+func WithTimeout(seconds int) (context.Context, context.CancelFunc) {
+	if seconds <= 0 {
+		return context.WithCancel(root)
+	}
+	return context.WithTimeout(root, time.Duration(seconds)*time.Second)
+}
+
+// BindTimeout makes every request made with the given settings stop once
+// the given number of seconds have passed, as set by a command's --timeout
+// flag, as well as when the CLI is interrupted. A timeout of 0 never expires.
+// The returned func releases the timer and should be deferred.
+func BindTimeout(settings *models.Settings, seconds int) (context.CancelFunc, error) {
+	if seconds < 0 {
+		return nil, errors.New("--timeout can not be negative")
+	}
+	ctx, cancel := WithTimeout(seconds)
+	settings.HTTPManager = settings.HTTPManager.WithContext(ctx)
+	return cancel, nil
+}
+
+// Err converts the error of a finished context into an error to show the
+// user. It returns nil if the context is not done.
+func Err(ctx context.Context) error {
+	switch ctx.Err() {
+	case nil:
+		return nil
+	case context.DeadlineExceeded:
+		return ErrTimeout
+	}
+	return ErrInterrupted
+}
+
+// Sleep pauses for the given duration or until the context is done, in which
+// case the context's error is returned as converted by Err
+func Sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return Err(ctx)
+	}
+}
+
+// OnInterrupt registers f to run in the given phase if the command is
+// interrupted. The context given to f is not the cancelled root context but
+// one that allows the cleanup a limited amount of time. Cleanups in the same
+// phase run in the reverse order they were registered in, like defers. The
+// returned func unregisters f once there is nothing left for it to clean up.
+func OnInterrupt(phase Phase, f func(ctx context.Context) error) func() {
+	c := &cleanup{f: f}
+	mu.Lock()
+	cleanups[phase] = append(cleanups[phase], c)
+	mu.Unlock()
+	return func() {
+		mu.Lock()
+		defer mu.Unlock()
+		for i, registered := range cleanups[phase] {
+			if registered == c {
+				cleanups[phase] = append(cleanups[phase][:i], cleanups[phase][i+1:]...)
+				return
+			}
+		}
+	}
+}
+
+// RemoveOnInterrupt registers a partially written file or directory to be
+// removed if the command is interrupted
+func RemoveOnInterrupt(path string) func() {
+	return OnInterrupt(Files, func(ctx context.Context) error {
+		return os.RemoveAll(path)
+	})
+}
+
+// Listen starts handling interrupts. The first interrupt cancels the root
+// context, runs the cleanups, and exits. A second interrupt exits right away
+// without waiting for the cleanups to finish.
+func Listen() {
+	listenOnce.Do(func() {
+		signals := make(chan os.Signal, 2)
+		signal.Notify(signals, os.Interrupt)
+		logrus.AddHook(fatalHook{})
+		go func() {
+			<-signals
+			go func() {
+				<-signals
+				exit(ExitCode)
+			}()
+			handle()
+		}()
+	})
+}
+
+// handle cancels the command, runs every cleanup, and exits
+func handle() {
+	close(interrupted)
+	cancelRoot()
+	logrus.Println("\nInterrupted, cleaning up...")
+	ctx, cancel := context.WithTimeout(context.Background(), cleanupTimeout)
+	defer cancel()
+	for _, phase := range []Phase{Jobs, Files} {
+		mu.Lock()
+		phaseCleanups := append([]*cleanup{}, cleanups[phase]...)
+		mu.Unlock()
+		for i := len(phaseCleanups) - 1; i >= 0; i-- {
+			if err := phaseCleanups[i].f(ctx); err != nil {
+				logrus.Warnf("Failed to clean up: %s", err)
+			}
+		}
+	}
+	exit(ExitCode)
+}
+
+// Wait blocks if the command was interrupted so that the cleanups finish and
+// exit the process with ExitCode instead of the command exiting first
+func Wait() {
+	select {
+	case <-interrupted:
+		select {}
+	default:
+	}
+}
+
+// fatalHook holds back logrus.Fatal, which exits with 1, while an interrupt
+// is being cleaned up
+type fatalHook struct{}
+
+func (fatalHook) Levels() []logrus.Level {
+	return []logrus.Level{logrus.FatalLevel}
+}
+
+func (fatalHook) Fire(*logrus.Entry) error {
+	Wait()
+	return nil
+}
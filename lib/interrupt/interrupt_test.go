@@ -0,0 +1,105 @@
+package interrupt
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+var errTests = []struct {
+	timeout     time.Duration
+	cancel      bool
+	expectedErr error
+}{
+	{time.Hour, false, nil},
+	{time.Hour, true, ErrInterrupted},
+	{time.Millisecond, false, ErrTimeout},
+}
+
+func TestSleep(t *testing.T) {
+	for _, data := range errTests {
+		t.Logf("Data: %+v", data)
+		ctx, cancel := context.WithTimeout(context.Background(), data.timeout)
+		if data.cancel {
+			cancel()
+		}
+		err := Sleep(ctx, 50*time.Millisecond)
+		cancel()
+		if err != data.expectedErr {
+			t.Errorf("Unexpected error. Expected: %v Actual: %v", data.expectedErr, err)
+		}
+	}
+}
+
+func TestBindTimeoutNegative(t *testing.T) {
+	if _, err := BindTimeout(nil, -1); err == nil {
+		t.Error("Expected an error for a negative timeout")
+	}
+}
+
+func TestHandle(t *testing.T) {
+	dir, err := ioutil.TempDir("", "interrupt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	partial := filepath.Join(dir, "partial")
+	if err = ioutil.WriteFile(partial, []byte("partial"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	kept := filepath.Join(dir, "kept")
+	if err = ioutil.WriteFile(kept, []byte("kept"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	var order []string
+	record := func(name string, err error) func(ctx context.Context) error {
+		return func(ctx context.Context) error {
+			if ctx.Err() != nil {
+				t.Errorf("Cleanup %s was given a context that is already done", name)
+			}
+			if _, statErr := os.Stat(partial); name != "files" && statErr != nil {
+				t.Errorf("Cleanup %s ran after the files were removed", name)
+			}
+			order = append(order, name)
+			return err
+		}
+	}
+	OnInterrupt(Files, record("files", nil))
+	RemoveOnInterrupt(partial)
+	RemoveOnInterrupt(kept)()
+	OnInterrupt(Jobs, record("first job", nil))
+	OnInterrupt(Jobs, record("second job", errors.New("failed")))
+	OnInterrupt(Jobs, record("removed job", nil))()
+
+	var code int
+	exit = func(c int) {
+		code = c
+	}
+	defer func() {
+		exit = os.Exit
+	}()
+	handle()
+
+	expectedOrder := []string{"second job", "first job", "files"}
+	if !reflect.DeepEqual(order, expectedOrder) {
+		t.Errorf("Unexpected cleanup order. Expected: %v Actual: %v", expectedOrder, order)
+	}
+	if code != ExitCode {
+		t.Errorf("Unexpected exit code. Expected: %d Actual: %d", ExitCode, code)
+	}
+	if Err(Context()) != ErrInterrupted {
+		t.Errorf("Expected the root context to be interrupted but got %v", Err(Context()))
+	}
+	if _, err = os.Stat(partial); !os.IsNotExist(err) {
+		t.Errorf("Expected %s to be removed", partial)
+	}
+	if _, err = os.Stat(kept); err != nil {
+		t.Errorf("Expected %s to be kept: %s", kept, err)
+	}
+}
@@ -1,6 +1,11 @@
 package jobs
 
-import "github.com/catalyzeio/cli/models"
+import (
+	"io"
+	"time"
+
+	"github.com/catalyzeio/cli/models"
+)
 
 // IJobs
 type IJobs interface {
@@ -16,7 +21,10 @@ type IJobs interface {
 	PollForStatus(statuses []string, jobID, svcID string) (string, error)
 	PollTillFinished(jobID, svcID string) (string, error)
 	List(svcID string, page, pageSize int) (*[]models.Job, error)
+	TempLogsURL(job *models.Job, svcID string) (*models.TempURL, error)
+	Logs(job *models.Job, svcID string, w io.Writer) error
 	WaitToAppear(jobID, svcID string) error
+	WaitForDeploy(jobType, target, svcID string, timeout time.Duration, deploy func() error) (*models.Job, error)
 }
 
 // SJobs is a concrete implementation of IJobs
@@ -0,0 +1,88 @@
+package jobs
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/catalyzeio/cli/lib/crypto"
+	"github.com/catalyzeio/cli/models"
+)
+
+// TempLogsURL returns a temporary URL the logs of the given job can be
+// downloaded from. Only backup, restore, and import jobs store their logs,
+// encrypted alongside the backup. The logs of every other job are sent to the
+// environment's logging dashboard.
+func (j *SJobs) TempLogsURL(job *models.Job, svcID string) (*models.TempURL, error) {
+	if logsEncryption(job) == nil {
+		return nil, fmt.Errorf("The %s job %s does not store its logs. Only backup, restore, and import jobs do. View the logs of other jobs in your logging dashboard", job.Type, job.ID)
+	}
+	headers := j.Settings.HTTPManager.GetHeaders(j.Settings.SessionToken, j.Settings.Version, j.Settings.Pod, j.Settings.UsersID)
+	resp, statusCode, err := j.Settings.HTTPManager.Get(nil, fmt.Sprintf("%s%s/environments/%s/services/%s/backup-restore-logs-url/%s", j.Settings.PaasHost, j.Settings.PaasHostVersion, j.Settings.EnvironmentID, svcID, job.ID), headers)
+	if err != nil {
+		return nil, err
+	}
+	var tempURL models.TempURL
+	err = j.Settings.HTTPManager.ConvertResp(resp, statusCode, &tempURL)
+	if err != nil {
+		return nil, err
+	}
+	return &tempURL, nil
+}
+
+// Logs downloads the logs of the given job and decrypts them as they are
+// written to w. The job must carry the encryption keys of its logs, such as a
+// job retrieved with Retrieve.
+func (j *SJobs) Logs(job *models.Job, svcID string, w io.Writer) error {
+	tempURL, err := j.TempLogsURL(job, svcID)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest("GET", tempURL.URL, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := http.DefaultClient.Do(req.WithContext(j.Settings.HTTPManager.Context()))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("Failed to download the logs of job %s: %s", job.ID, resp.Status)
+	}
+	store := logsEncryption(job)
+	logsKey := store.KeyLogs
+	if logsKey == "" {
+		logsKey = store.Key
+	}
+	dfw, err := crypto.New().NewDecryptWriteCloser(nopWriteCloser{w}, logsKey, store.IV)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(dfw, resp.Body)
+	if closeErr := dfw.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// logsEncryption returns the encryption store the logs of the given job are
+// encrypted with, or nil if the job does not store its logs
+func logsEncryption(job *models.Job) *models.EncryptionStore {
+	if job.Type == "restore" && job.Restore != nil {
+		return job.Restore
+	}
+	if job.Backup != nil {
+		return job.Backup
+	}
+	return job.Restore
+}
+
+// nopWriteCloser adds a Close method that does nothing to a writer
+type nopWriteCloser struct {
+	io.Writer
+}
+
+func (nopWriteCloser) Close() error {
+	return nil
+}
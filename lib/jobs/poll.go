@@ -1,14 +1,28 @@
 package jobs
 
 import (
+	"context"
 	"fmt"
 	"time"
 
 	"github.com/Sirupsen/logrus"
 	"github.com/catalyzeio/cli/config"
+	"github.com/catalyzeio/cli/lib/httpclient"
+	"github.com/catalyzeio/cli/lib/interrupt"
 	"github.com/catalyzeio/cli/models"
 )
 
+const (
+	// maxFailedPolls is the number of requests for a job that may fail in a
+	// row before polling gives up
+	maxFailedPolls = 3
+	// maxAppearPolls is the number of times a new job is looked for before
+	// giving up on it appearing
+	maxAppearPolls = 300
+	// appearPollTime is the time between looking for a new job
+	appearPollTime = 2 * time.Second
+)
+
 func contains(v string, a []string) bool {
 	for _, i := range a {
 		if i == v {
@@ -22,21 +36,63 @@ func (j *SJobs) PollTillFinished(jobID, svcID string) (string, error) {
 	return j.PollForStatus([]string{"finished"}, jobID, svcID)
 }
 
+// WaitToAppear waits for a job that was just created, such as a snapshot
+// backup, to be retrievable. Requests that fail for any reason other than the
+// job not being found yet are retried up to maxFailedPolls times in a row.
 func (j *SJobs) WaitToAppear(jobID, svcID string) error {
-	for {
+	ctx := j.Settings.HTTPManager.Context()
+	failedAttempts := 0
+	for attempt := 1; ; attempt++ {
 		headers := j.Settings.HTTPManager.GetHeaders(j.Settings.SessionToken, j.Settings.Version, j.Settings.Pod, j.Settings.UsersID)
-		_, statusCode, err := j.Settings.HTTPManager.Get(nil, fmt.Sprintf("%s%s/environments/%s/services/%s/jobs/%s", j.Settings.PaasHost, j.Settings.PaasHostVersion, j.Settings.EnvironmentID, svcID, jobID), headers)
-		if err != nil {
-			return err
+		_, _, err := j.Settings.HTTPManager.Get(nil, fmt.Sprintf("%s%s/environments/%s/services/%s/jobs/%s", j.Settings.PaasHost, j.Settings.PaasHostVersion, j.Settings.EnvironmentID, svcID, jobID), headers)
+		if ctxErr := interrupt.Err(ctx); ctxErr != nil {
+			return ctxErr
 		}
-		if statusCode >= 200 && statusCode < 300 {
+		switch {
+		case err == nil:
 			return nil
+		case httpclient.IsNotFound(err):
+			failedAttempts = 0
+		default:
+			failedAttempts++
+			if failedAttempts >= maxFailedPolls {
+				return fmt.Errorf("Failed to retrieve job %s: %s", jobID, err)
+			}
+		}
+		if attempt >= maxAppearPolls {
+			return fmt.Errorf("Job %s did not appear after %d attempts", jobID, maxAppearPolls)
+		}
+		if err = interrupt.Sleep(ctx, appearPollTime); err != nil {
+			return err
 		}
-		time.Sleep(time.Second * 2)
 	}
 }
 
 func (j *SJobs) PollForStatus(statuses []string, jobID, svcID string) (string, error) {
+	return j.pollForStatus(statuses, jobID, svcID, time.Time{})
+}
+
+// TimeoutError is returned when a job is still pending or running once the
+// timeout it was polled with has passed
+type TimeoutError struct {
+	JobID  string
+	Status string
+}
+
+func (e *TimeoutError) Error() string {
+	return fmt.Sprintf("Timed out waiting for job %s which is still in status '%s'", e.JobID, e.Status)
+}
+
+// IsTimeout returns whether err is a TimeoutError
+func IsTimeout(err error) bool {
+	_, ok := err.(*TimeoutError)
+	return ok
+}
+
+// pollForStatus polls a job until it reaches one of the given statuses. A
+// non-zero deadline stops the polling with an error once it has passed.
+func (j *SJobs) pollForStatus(statuses []string, jobID, svcID string, deadline time.Time) (string, error) {
+	ctx := j.Settings.HTTPManager.Context()
 	var job models.Job
 	failedAttempts := 0
 poll:
@@ -44,6 +100,9 @@ poll:
 		failed := false
 		headers := j.Settings.HTTPManager.GetHeaders(j.Settings.SessionToken, j.Settings.Version, j.Settings.Pod, j.Settings.UsersID)
 		resp, statusCode, err := j.Settings.HTTPManager.Get(nil, fmt.Sprintf("%s%s/environments/%s/services/%s/jobs/%s", j.Settings.PaasHost, j.Settings.PaasHostVersion, j.Settings.EnvironmentID, svcID, jobID), headers)
+		if ctxErr := interrupt.Err(ctx); ctxErr != nil {
+			return "", ctxErr
+		}
 		if err != nil {
 			failed = true
 		}
@@ -59,12 +118,17 @@ poll:
 		case contains(s, statuses):
 			break poll
 		case contains(s, []string{"scheduled", "queued", "started", "running", "stopped", "waiting"}):
-			if failedAttempts >= 3 {
+			if failedAttempts >= maxFailedPolls {
 				return "", fmt.Errorf("Error - ended in status '%s'.", job.Status)
 			}
+			if !deadline.IsZero() && !time.Now().Before(deadline) {
+				return "", &TimeoutError{JobID: jobID, Status: job.Status}
+			}
 			// all because logrus treats print, println, and printf the same
 			logrus.StandardLogger().Out.Write([]byte("."))
-			time.Sleep(config.JobPollTime * time.Second)
+			if err = sleepUntil(ctx, deadline); err != nil {
+				return "", err
+			}
 		default:
 			return "", fmt.Errorf("Error - ended in status '%s'.", job.Status)
 		}
@@ -74,3 +138,14 @@ poll:
 	}
 	return job.Status, nil
 }
+
+// sleepUntil waits for the job poll interval, cutting it short if the given
+// non-zero deadline comes first. An error is returned if the context is done
+// before the wait is over.
+func sleepUntil(ctx context.Context, deadline time.Time) error {
+	wait := config.JobPollTime * time.Second
+	if remaining := deadline.Sub(time.Now()); !deadline.IsZero() && remaining < wait {
+		wait = remaining
+	}
+	return interrupt.Sleep(ctx, wait)
+}
@@ -0,0 +1,68 @@
+package jobs
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/catalyzeio/cli/models"
+)
+
+// deployJobsPageSize is the number of the most recent jobs looked through to
+// find the job started by a deploy
+const deployJobsPageSize = 100
+
+// WaitForDeploy starts a deploy with the given func and waits for the job it
+// creates to be running. Deploy requests do not return the job they start so
+// the job is found by comparing the jobs of the given type, and target if
+// given, before and after the deploy. An error is returned if the job fails
+// or if it is not running before the timeout passes. A timeout of 0 waits
+// indefinitely.
+func (j *SJobs) WaitForDeploy(jobType, target, svcID string, timeout time.Duration, deploy func() error) (*models.Job, error) {
+	existing, err := j.RetrieveByType(svcID, jobType, 1, deployJobsPageSize)
+	if err != nil {
+		return nil, err
+	}
+	known := map[string]bool{}
+	for _, job := range *existing {
+		known[job.ID] = true
+	}
+	if err = deploy(); err != nil {
+		return nil, err
+	}
+	var deadline time.Time
+	if timeout > 0 {
+		deadline = time.Now().Add(timeout)
+	}
+	job, err := j.waitForNewJob(jobType, target, svcID, known, deadline)
+	if err != nil {
+		return nil, err
+	}
+	status, err := j.pollForStatus([]string{"running"}, job.ID, svcID, deadline)
+	if err != nil {
+		return job, err
+	}
+	job.Status = status
+	return job, nil
+}
+
+// waitForNewJob returns the newest job of the given type and target that is
+// not one of the known jobs, waiting for it to appear
+func (j *SJobs) waitForNewJob(jobType, target, svcID string, known map[string]bool, deadline time.Time) (*models.Job, error) {
+	for {
+		jobs, err := j.RetrieveByType(svcID, jobType, 1, deployJobsPageSize)
+		if err != nil {
+			return nil, err
+		}
+		for _, job := range *jobs {
+			if !known[job.ID] && (target == "" || job.Target == target) {
+				return &job, nil
+			}
+		}
+		if !deadline.IsZero() && !time.Now().Before(deadline) {
+			return nil, fmt.Errorf("Timed out waiting for the %s job to be created", jobType)
+		}
+		if err = sleepUntil(j.Settings.HTTPManager.Context(), deadline); err != nil {
+			return nil, err
+		}
+	}
+}
@@ -4,10 +4,13 @@ import (
 	"bufio"
 	"errors"
 	"fmt"
+	"io"
 	"os"
 	"runtime"
 	"strings"
 
+	"github.com/Sirupsen/logrus"
+	"github.com/catalyzeio/cli/models"
 	"golang.org/x/crypto/ssh/terminal"
 )
 
@@ -15,18 +18,30 @@ import (
 // input.
 type IPrompts interface {
 	UsernamePassword() (string, string, error)
-	KeyPassphrase(string) string
-	Password(msg string) string
+	KeyPassphrase(string) (string, error)
+	Password(msg string) (string, error)
+	BackupPassphrase(confirm bool) (string, error)
 	PHI() error
 	YesNo(msg string) error
-	OTP(string) string
+	OTP(string) (string, error)
+}
+
+// out is where prompts are written. It follows the output of logrus so that
+// prompts move to stderr along with everything else when a command writes its
+// results to stdout.
+func out() io.Writer {
+	return logrus.StandardLogger().Out
 }
 
 // SPrompts is a concrete implementation of IPrompts
 type SPrompts struct{}
 
-// New returns a new instance of IPrompts
-func New() IPrompts {
+// New returns a new instance of IPrompts. If the given settings are
+// non-interactive, the returned IPrompts never reads from stdin.
+func New(settings *models.Settings) IPrompts {
+	if settings != nil && settings.NonInteractive {
+		return NewNonInteractive(settings.AssumeYes)
+	}
 	return &SPrompts{}
 }
 
@@ -40,7 +55,7 @@ var validAnswers = map[string]bool{
 // UsernamePassword prompts a user to enter their username and password.
 func (p *SPrompts) UsernamePassword() (string, string, error) {
 	var username string
-	fmt.Print("Username or Email: ")
+	fmt.Fprint(out(), "Username or Email: ")
 	in := bufio.NewReader(os.Stdin)
 	username, err := in.ReadString('\n')
 	if err != nil {
@@ -50,18 +65,18 @@ func (p *SPrompts) UsernamePassword() (string, string, error) {
 	if runtime.GOOS == "windows" {
 		username = strings.TrimRight(username, "\r")
 	}
-	fmt.Print("Password: ")
+	fmt.Fprint(out(), "Password: ")
 	bytes, _ := terminal.ReadPassword(int(os.Stdin.Fd()))
-	fmt.Println("")
+	fmt.Fprintln(out(), "")
 	return username, string(bytes), nil
 }
 
 // KeyPassphrase prompts a user to enter a passphrase for a named key.
-func (p *SPrompts) KeyPassphrase(filepath string) string {
-	fmt.Printf("Enter passphrase for %s: ", filepath)
+func (p *SPrompts) KeyPassphrase(filepath string) (string, error) {
+	fmt.Fprintf(out(), "Enter passphrase for %s: ", filepath)
 	bytes, _ := terminal.ReadPassword(int(os.Stdin.Fd()))
-	fmt.Println("")
-	return string(bytes)
+	fmt.Fprintln(out(), "")
+	return string(bytes), nil
 }
 
 // PHI prompts a user to accept liability for downloading PHI to their local
@@ -69,12 +84,12 @@ func (p *SPrompts) KeyPassphrase(filepath string) string {
 func (p *SPrompts) PHI() error {
 	var answer string
 	for {
-		fmt.Println("This operation might result in PHI data being downloaded and decrypted to your local machine. By entering \"y\" at the prompt below, you warrant that you have the necessary privileges to view the data, have taken all necessary precautions to secure this data, and absolve Catalyze of any issues that might arise from its loss.")
-		fmt.Print("Do you wish to proceed? (y/n) ")
+		fmt.Fprintln(out(), "This operation might result in PHI data being downloaded and decrypted to your local machine. By entering \"y\" at the prompt below, you warrant that you have the necessary privileges to view the data, have taken all necessary precautions to secure this data, and absolve Catalyze of any issues that might arise from its loss.")
+		fmt.Fprint(out(), "Do you wish to proceed? (y/n) ")
 		fmt.Scanln(&answer)
-		fmt.Println("")
+		fmt.Fprintln(out(), "")
 		if _, contains := validAnswers[strings.ToLower(answer)]; !contains {
-			fmt.Printf("%s is not a valid option. Please enter 'y' or 'n'\n", answer)
+			fmt.Fprintf(out(), "%s is not a valid option. Please enter 'y' or 'n'\n", answer)
 		} else {
 			break
 		}
@@ -94,11 +109,11 @@ func (p *SPrompts) PHI() error {
 func (p *SPrompts) YesNo(msg string) error {
 	var answer string
 	for {
-		fmt.Printf(msg)
+		fmt.Fprint(out(), msg)
 		fmt.Scanln(&answer)
-		fmt.Println("")
+		fmt.Fprintln(out(), "")
 		if _, contains := validAnswers[strings.ToLower(answer)]; !contains {
-			fmt.Printf("%s is not a valid option. Please enter 'y' or 'n'\n", answer)
+			fmt.Fprintf(out(), "%s is not a valid option. Please enter 'y' or 'n'\n", answer)
 		} else {
 			break
 		}
@@ -112,24 +127,50 @@ func (p *SPrompts) YesNo(msg string) error {
 // Password prompts the user for a password displaying the given message.
 // The password will be hidden while typed. A newline is not added to the given
 // message. If a newline is required, it should be part of the passed in string.
-func (p *SPrompts) Password(msg string) string {
-	fmt.Print(msg)
-	bytes, _ := terminal.ReadPassword(int(os.Stdin.Fd()))
-	fmt.Println("")
-	return string(bytes)
+func (p *SPrompts) Password(msg string) (string, error) {
+	fmt.Fprint(out(), msg)
+	bytes, err := terminal.ReadPassword(int(os.Stdin.Fd()))
+	fmt.Fprintln(out(), "")
+	if err != nil {
+		return "", fmt.Errorf("Could not read the password from the terminal: %s", err)
+	}
+	return string(bytes), nil
+}
+
+// BackupPassphrase prompts for the passphrase that wraps the key of a
+// downloaded backup. When confirm is true, the passphrase must be entered
+// twice.
+func (p *SPrompts) BackupPassphrase(confirm bool) (string, error) {
+	passphrase, err := p.Password("Backup key passphrase: ")
+	if err != nil {
+		return "", err
+	}
+	if passphrase == "" {
+		return "", errors.New("A passphrase is required")
+	}
+	if confirm {
+		again, err := p.Password("Confirm backup key passphrase: ")
+		if err != nil {
+			return "", err
+		}
+		if again != passphrase {
+			return "", errors.New("The passphrases do not match")
+		}
+	}
+	return passphrase, nil
 }
 
 // OTP prompts for a one-time password and returns the value.
-func (p *SPrompts) OTP(preferredMode string) string {
-	fmt.Println("This account has two-factor authentication enabled.")
+func (p *SPrompts) OTP(preferredMode string) (string, error) {
+	fmt.Fprintln(out(), "This account has two-factor authentication enabled.")
 	prompt := "Your one-time password: "
 	if preferredMode == "authenticator" {
 		prompt = "Your authenticator one-time password: "
 	} else if preferredMode == "email" {
 		prompt = "One-time password (sent to your email): "
 	}
-	fmt.Print(prompt)
+	fmt.Fprint(out(), prompt)
 	var token string
 	fmt.Scanln(&token)
-	return strings.TrimSpace(token)
+	return strings.TrimSpace(token), nil
 }
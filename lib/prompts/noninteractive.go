@@ -0,0 +1,104 @@
+package prompts
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/catalyzeio/cli/config"
+)
+
+// SNonInteractivePrompts is an implementation of IPrompts that never reads from
+// stdin. Confirmations are either automatically accepted or fail immediately
+// and secrets are read from env variables or files.
+type SNonInteractivePrompts struct {
+	AssumeYes bool
+}
+
+// NewNonInteractive returns a new instance of IPrompts suitable for CI
+// pipelines and other environments without a terminal. If assumeYes is true,
+// all confirmations are accepted. Otherwise they fail with an error.
+func NewNonInteractive(assumeYes bool) IPrompts {
+	return &SNonInteractivePrompts{
+		AssumeYes: assumeYes,
+	}
+}
+
+// UsernamePassword always fails. Credentials must be given with the global
+// username and password options or their env variables.
+func (p *SNonInteractivePrompts) UsernamePassword() (string, string, error) {
+	return "", "", fmt.Errorf("A username and password are required but prompts are disabled in non-interactive mode. Set the %s and %s env variables or sign in with a private key", config.CatalyzeUsernameEnvVar, config.CatalyzePasswordEnvVar)
+}
+
+// KeyPassphrase reads the passphrase for an encrypted private key from the
+// CATALYZE_KEY_PASSPHRASE env variable or the file named by the
+// CATALYZE_KEY_PASSPHRASE_FILE env variable.
+func (p *SNonInteractivePrompts) KeyPassphrase(filepath string) (string, error) {
+	passphrase, err := secret(config.KeyPassphraseEnvVar, config.KeyPassphraseFileEnvVar)
+	if err != nil {
+		return "", fmt.Errorf("The private key %s is encrypted: %s", filepath, err)
+	}
+	return passphrase, nil
+}
+
+// Password always fails since there is nowhere to read the password from.
+func (p *SNonInteractivePrompts) Password(msg string) (string, error) {
+	return "", fmt.Errorf("A password is required but prompts are disabled in non-interactive mode")
+}
+
+// BackupPassphrase reads the passphrase that wraps the key of a downloaded
+// backup from the CATALYZE_BACKUP_PASSPHRASE env variable or the file named by
+// the CATALYZE_BACKUP_PASSPHRASE_FILE env variable.
+func (p *SNonInteractivePrompts) BackupPassphrase(confirm bool) (string, error) {
+	passphrase, err := secret(config.BackupPassphraseEnvVar, config.BackupPassphraseFileEnvVar)
+	if err != nil {
+		return "", fmt.Errorf("A backup key passphrase is required: %s", err)
+	}
+	if passphrase == "" {
+		return "", fmt.Errorf("A backup key passphrase is required")
+	}
+	return passphrase, nil
+}
+
+// PHI accepts liability for downloading PHI only if confirmations are assumed.
+func (p *SNonInteractivePrompts) PHI() error {
+	if !p.AssumeYes {
+		return fmt.Errorf("This operation might result in PHI data being downloaded and decrypted to your local machine and requires confirmation. Rerun with the global --yes option to accept")
+	}
+	return nil
+}
+
+// YesNo accepts the given question only if confirmations are assumed.
+func (p *SNonInteractivePrompts) YesNo(msg string) error {
+	if !p.AssumeYes {
+		return fmt.Errorf("Confirmation is required but prompts are disabled in non-interactive mode. Rerun with the global --yes option to accept: %s", strings.TrimSpace(msg))
+	}
+	return nil
+}
+
+// OTP reads a one-time password from the CATALYZE_OTP env variable or the
+// file named by the CATALYZE_OTP_FILE env variable.
+func (p *SNonInteractivePrompts) OTP(preferredMode string) (string, error) {
+	token, err := secret(config.OTPEnvVar, config.OTPFileEnvVar)
+	if err != nil {
+		return "", fmt.Errorf("This account has two-factor authentication enabled: %s", err)
+	}
+	return token, nil
+}
+
+// secret returns the value of the given env variable, falling back to the
+// contents of the file named by fileEnvVar. Surrounding whitespace is trimmed.
+func secret(envVar, fileEnvVar string) (string, error) {
+	if value := os.Getenv(envVar); value != "" {
+		return strings.TrimSpace(value), nil
+	}
+	if path := os.Getenv(fileEnvVar); path != "" {
+		b, err := ioutil.ReadFile(path)
+		if err != nil {
+			return "", err
+		}
+		return strings.TrimSpace(string(b)), nil
+	}
+	return "", fmt.Errorf("prompts are disabled in non-interactive mode. Set the %s or %s env variable", envVar, fileEnvVar)
+}
@@ -0,0 +1,75 @@
+package prompts
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/catalyzeio/cli/config"
+)
+
+var yesNoTests = []struct {
+	assumeYes bool
+	expectErr bool
+}{
+	{true, false},
+	{false, true},
+}
+
+func TestNonInteractiveYesNo(t *testing.T) {
+	for _, data := range yesNoTests {
+		t.Logf("Data: %+v", data)
+		p := NewNonInteractive(data.assumeYes)
+		if err := p.YesNo("Are you sure? (y/n) "); (err != nil) != data.expectErr {
+			t.Errorf("Expected error: %t. Found: %v", data.expectErr, err)
+		}
+		if err := p.PHI(); (err != nil) != data.expectErr {
+			t.Errorf("Expected error: %t. Found: %v", data.expectErr, err)
+		}
+	}
+	if _, _, err := NewNonInteractive(true).UsernamePassword(); err == nil {
+		t.Error("Expected an error when prompting for a username and password")
+	}
+}
+
+func TestNonInteractiveSecrets(t *testing.T) {
+	dir, err := ioutil.TempDir("", "prompts")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	otpFile := filepath.Join(dir, "otp")
+	if err = ioutil.WriteFile(otpFile, []byte("654321\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	var secretTests = []struct {
+		env       map[string]string
+		expected  string
+		expectErr bool
+	}{
+		{map[string]string{}, "", true},
+		{map[string]string{config.OTPEnvVar: "123456"}, "123456", false},
+		{map[string]string{config.OTPFileEnvVar: otpFile}, "654321", false},
+		{map[string]string{config.OTPEnvVar: "123456", config.OTPFileEnvVar: otpFile}, "123456", false},
+		{map[string]string{config.OTPFileEnvVar: filepath.Join(dir, "missing")}, "", true},
+	}
+	for _, data := range secretTests {
+		t.Logf("Data: %+v", data)
+		os.Unsetenv(config.OTPEnvVar)
+		os.Unsetenv(config.OTPFileEnvVar)
+		for k, v := range data.env {
+			os.Setenv(k, v)
+		}
+		otp, err := NewNonInteractive(false).OTP("authenticator")
+		if (err != nil) != data.expectErr {
+			t.Errorf("Expected error: %t. Found: %v", data.expectErr, err)
+		}
+		if otp != data.expected {
+			t.Errorf("Expected OTP %q. Found: %q", data.expected, otp)
+		}
+	}
+	os.Unsetenv(config.OTPEnvVar)
+	os.Unsetenv(config.OTPFileEnvVar)
+}
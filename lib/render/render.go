@@ -0,0 +1,110 @@
+package render
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/catalyzeio/cli/models"
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	// Table is the default, human readable output format
+	Table = "table"
+	// JSON outputs results as indented JSON
+	JSON = "json"
+	// YAML outputs results as YAML
+	YAML = "yaml"
+)
+
+// Formats lists every supported output format
+var Formats = []string{Table, JSON, YAML}
+
+// IRender is the shared output layer for commands that print results. JSON
+// and YAML output is generated from the given data, which should always be
+// one of the models structs (or a slice or map of them) so the schema stays
+// stable. Table output is left to the command through the table func.
+type IRender interface {
+	Format() string
+	Structured() bool
+	Render(data interface{}, table func() error) error
+}
+
+// SRender is a concrete implementation of IRender
+type SRender struct {
+	format string
+	out    io.Writer
+}
+
+// New returns an instance of IRender using the output format in the given
+// settings.
+func New(settings *models.Settings) IRender {
+	return NewWithFormat(settings.Output)
+}
+
+// NewWithFormat returns an instance of IRender for the given format, writing
+// to stdout. An empty format is treated as Table.
+func NewWithFormat(format string) IRender {
+	if format == "" {
+		format = Table
+	}
+	return &SRender{
+		format: format,
+		out:    os.Stdout,
+	}
+}
+
+// Validate returns an error if the given format is not supported.
+func Validate(format string) error {
+	for _, f := range Formats {
+		if f == format {
+			return nil
+		}
+	}
+	return fmt.Errorf("Invalid output format \"%s\". Supported formats are table, json, and yaml", format)
+}
+
+// Format returns the output format in use.
+func (r *SRender) Format() string {
+	return r.format
+}
+
+// Structured reports whether output is machine readable. Commands should not
+// print anything other than the rendered data to stdout when this is true.
+func (r *SRender) Structured() bool {
+	return r.format == JSON || r.format == YAML
+}
+
+// Render outputs data in the chosen format. For Table output, the given
+// table func is called instead.
+func (r *SRender) Render(data interface{}, table func() error) error {
+	switch r.format {
+	case JSON:
+		b, err := json.MarshalIndent(data, "", "    ")
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(r.out, string(b))
+		return err
+	case YAML:
+		// go through JSON first so the YAML keys match the JSON field names
+		b, err := json.Marshal(data)
+		if err != nil {
+			return err
+		}
+		var generic interface{}
+		if err = yaml.Unmarshal(b, &generic); err != nil {
+			return err
+		}
+		b, err = yaml.Marshal(generic)
+		if err != nil {
+			return err
+		}
+		_, err = r.out.Write(b)
+		return err
+	default:
+		return table()
+	}
+}
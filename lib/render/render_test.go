@@ -0,0 +1,53 @@
+package render
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/catalyzeio/cli/models"
+)
+
+var renderTests = []struct {
+	format         string
+	expectedOutput string
+	expectTable    bool
+}{
+	{Table, "", true},
+	{JSON, "[\n    {\n        \"id\": 1,\n        \"name\": \"admin\"\n    }\n]\n", false},
+	{YAML, "- id: 1\n  name: admin\n", false},
+}
+
+func TestRender(t *testing.T) {
+	roles := []models.Role{{ID: 1, Name: "admin"}}
+	for _, data := range renderTests {
+		t.Logf("Data: %+v", data)
+		out := &bytes.Buffer{}
+		r := &SRender{format: data.format, out: out}
+		calledTable := false
+		err := r.Render(roles, func() error {
+			calledTable = true
+			return nil
+		})
+		if err != nil {
+			t.Errorf("Unexpected error: %s", err)
+			continue
+		}
+		if calledTable != data.expectTable {
+			t.Errorf("Expected table output to be %t. Found: %t", data.expectTable, calledTable)
+		}
+		if out.String() != data.expectedOutput {
+			t.Errorf("Expected: %q. Found: %q", data.expectedOutput, out.String())
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	for _, f := range Formats {
+		if err := Validate(f); err != nil {
+			t.Errorf("Expected %s to be valid. Found: %s", f, err)
+		}
+	}
+	if err := Validate("xml"); err == nil {
+		t.Error("Expected xml to be invalid")
+	}
+}
@@ -65,6 +65,36 @@ func (rt *ReaderTransfer) Length() ByteSize {
 	return rt.length
 }
 
+// ProgressTransfer monitors the progress of a transfer that is made up of
+// several separate reads, such as the parts of a multipart upload
+type ProgressTransfer struct {
+	length      ByteSize
+	transferred int64
+}
+
+// NewProgressTransfer instantiates a ProgressTransfer for a transfer of the
+// given length of which transferred bytes are already complete
+func NewProgressTransfer(length, transferred int64) *ProgressTransfer {
+	pt := new(ProgressTransfer)
+	pt.length = ByteSize(length)
+	pt.transferred = transferred
+	return pt
+}
+
+// Add records that n more bytes have been transferred. n may be negative
+// when a partial transfer has to be retried.
+func (pt *ProgressTransfer) Add(n int64) {
+	atomic.AddInt64(&pt.transferred, n)
+}
+
+func (pt *ProgressTransfer) Transferred() ByteSize {
+	return ByteSize(atomic.LoadInt64(&pt.transferred))
+}
+
+func (pt *ProgressTransfer) Length() ByteSize {
+	return pt.length
+}
+
 // WriteCloserTransfer monitors how much of a WriteCloser has been written
 type WriteCloserTransfer struct {
 	length      ByteSize
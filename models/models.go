@@ -1,6 +1,11 @@
 package models
 
-import "github.com/jault3/mow.cli"
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/jault3/mow.cli"
+)
 
 // AssociatedEnv holds information about an associated environment
 type AssociatedEnv struct {
@@ -80,7 +85,11 @@ type Hits struct {
 	Hits     *[]LogHits `json:"hits"`
 }
 
+// HTTPManager makes requests to the Catalyze APIs. Every request is made with
+// the manager's context and stops when it is done.
 type HTTPManager interface {
+	Context() context.Context
+	WithContext(ctx context.Context) HTTPManager
 	GetHeaders(sessionToken, version, pod, userID string) map[string][]string
 	ConvertResp(b []byte, statusCode int, s interface{}) error
 	Get(body []byte, url string, headers map[string][]string) ([]byte, int, error)
@@ -104,11 +113,16 @@ type Invite struct {
 
 // LogHits contain ordering data for logs
 type LogHits struct {
-	Index  string              `json:"_index"`
-	Type   string              `json:"_type"`
-	ID     string              `json:"_id"`
-	Score  float64             `json:"_score"`
-	Fields map[string][]string `json:"fields"`
+	Index string  `json:"_index"`
+	Type  string  `json:"_type"`
+	ID    string  `json:"_id"`
+	Score float64 `json:"_score"`
+	// Source holds the requested fields of the log line as they were indexed,
+	// which are not always strings
+	Source map[string]json.RawMessage `json:"_source"`
+	// Sort holds the values the hit was sorted by, which are used to page
+	// from the hit with search_after
+	Sort []json.RawMessage `json:"sort"`
 }
 
 // Login is used for making an authentication request
@@ -185,6 +199,8 @@ type Pod struct {
 	Name                 string `json:"name"`
 	PHISafe              bool   `json:"phiSafe"`
 	ImportRequiresLength bool   `json:"importRequiresLength"`
+	// MultipartImports is set for pods that accept imports uploaded in parts
+	MultipartImports bool `json:"multipartImports"`
 }
 
 // Job job
@@ -201,6 +217,32 @@ type Job struct {
 	IsSnapshotBackup *bool            `json:"isSnapshotBackup,omitempty"`
 }
 
+// ServiceJob is a job along with the service it belongs to
+type ServiceJob struct {
+	ServiceID    string `json:"service_id"`
+	ServiceLabel string `json:"service_label"`
+	Job          Job    `json:"job"`
+}
+
+// BackupResult is the outcome of backing up one of many database services
+type BackupResult struct {
+	ServiceID    string `json:"service_id"`
+	ServiceLabel string `json:"service_label"`
+	JobID        string `json:"job_id,omitempty"`
+	Status       string `json:"status"`
+	Error        string `json:"error,omitempty"`
+}
+
+// BackupRetention is whether a backup is kept or deleted by a retention policy
+// and the retention periods it is kept for
+type BackupRetention struct {
+	ID        string   `json:"id"`
+	CreatedAt string   `json:"created_at"`
+	Status    string   `json:"status"`
+	Keep      bool     `json:"keep"`
+	Reasons   []string `json:"reasons,omitempty"`
+}
+
 // PodWrapper pod wrapper
 type PodWrapper struct {
 	Pods *[]Pod `json:"pods"`
@@ -212,6 +254,30 @@ type PostInvite struct {
 	LinkTemplate string `json:"linkTemplate"`
 }
 
+// Profile is a named set of API hosts, credentials, and environment
+// associations. The session token for a profile is kept in the credential
+// store under the profile's name.
+type Profile struct {
+	AccountsHost    string                   `json:"accounts_host,omitempty"`
+	AuthHost        string                   `json:"auth_host,omitempty"`
+	PaasHost        string                   `json:"paas_host,omitempty"`
+	AuthHostVersion string                   `json:"auth_host_version,omitempty"`
+	PaasHostVersion string                   `json:"paas_host_version,omitempty"`
+	PrivateKeyPath  string                   `json:"private_key_path"`
+	UsersID         string                   `json:"user_id"`
+	Environments    map[string]AssociatedEnv `json:"environments"`
+	Default         string                   `json:"default"`
+	Pods            *[]Pod                   `json:"pods"`
+	PodCheck        int64                    `json:"pod_check"`
+}
+
+// NamedProfile is a Profile along with its name, used when listing profiles
+type NamedProfile struct {
+	Name   string `json:"name"`
+	Active bool   `json:"active"`
+	Profile
+}
+
 type Release struct {
 	Name      string `json:"release,omitempty"`
 	CreatedAt string `json:"created_at,omitempty"`
@@ -269,15 +335,23 @@ type ServiceSize struct {
 
 // Settings holds various settings for the current context. All items with
 // `json:"-"` are never persisted to disk but used in memory for the current
-// command.
+// command. The remaining items are persisted in the Profile used for the
+// current command. Their JSON names match the settings file format from before
+// profiles were added so old settings files can be migrated.
 type Settings struct {
-	AccountsHost    string      `json:"-"`
-	AuthHost        string      `json:"-"`
-	PaasHost        string      `json:"-"`
-	AuthHostVersion string      `json:"-"`
-	PaasHostVersion string      `json:"-"`
-	Version         string      `json:"-"`
-	HTTPManager     HTTPManager `json:"-"`
+	AccountsHost    string             `json:"-"`
+	AuthHost        string             `json:"-"`
+	PaasHost        string             `json:"-"`
+	AuthHostVersion string             `json:"-"`
+	PaasHostVersion string             `json:"-"`
+	Version         string             `json:"-"`
+	HTTPManager     HTTPManager        `json:"-"`
+	Output          string             `json:"-"` // the format command results are printed in
+	NonInteractive  bool               `json:"-"` // never read from stdin, fail instead of prompting
+	AssumeYes       bool               `json:"-"` // automatically confirm yes/no prompts
+	Profile         string             `json:"-"` // the name of the profile used for the current command
+	ActiveProfile   string             `json:"-"` // the profile used when none is given, set with "profile use"
+	Profiles        map[string]Profile `json:"-"` // every profile in the settings file
 
 	Username        string                   `json:"-"`
 	Password        string                   `json:"-"`
@@ -287,7 +361,7 @@ type Settings struct {
 	EnvironmentName string                   `json:"-"` // the name of the environment used for the current command
 	OrgID           string                   `json:"-"` // the org ID the chosen environment for this commands belongs to
 	PrivateKeyPath  string                   `json:"private_key_path"`
-	SessionToken    string                   `json:"token"`
+	SessionToken    string                   `json:"token,omitempty"` // only read to migrate old settings files, stored in the credential store
 	UsersID         string                   `json:"user_id"`
 	Environments    map[string]AssociatedEnv `json:"environments"`
 	Default         string                   `json:"default"`
@@ -310,11 +384,45 @@ type Spec struct {
 	Payload *Payload `json:"payload"`
 }
 
+// WrappedKey is encryption key material that has itself been encrypted with
+// a public key or a passphrase so it can be stored next to the data it
+// protects. Salt and Iterations are only used by passphrase wrapping.
+type WrappedKey struct {
+	Method     string `json:"method"`
+	Salt       string `json:"salt,omitempty"`
+	Iterations int    `json:"iterations,omitempty"`
+	Nonce      string `json:"nonce,omitempty"`
+	Ciphertext string `json:"ciphertext"`
+}
+
+// BackupKey is the sidecar file saved next to a backup that was downloaded
+// without being decrypted. It holds everything needed to decrypt the backup
+// offline.
+type BackupKey struct {
+	JobID        string     `json:"jobId"`
+	ServiceID    string     `json:"serviceId"`
+	ServiceLabel string     `json:"serviceLabel"`
+	CreatedAt    string     `json:"createdAt"`
+	Key          WrappedKey `json:"key"`
+}
+
 // TempURL holds a URL for uploading or downloading files from a temporary URL
 type TempURL struct {
 	URL string `json:"url"`
 }
 
+// Upload is a multipart upload of a file that is sent in several parts
+type Upload struct {
+	ID       string `json:"id"`
+	Filename string `json:"filename"`
+}
+
+// UploadPart is a single part of an Upload that has been uploaded
+type UploadPart struct {
+	Number int    `json:"partNumber"`
+	ETag   string `json:"etag"`
+}
+
 // User is an authenticated User
 type User struct {
 	Username     string `json:"name"`
@@ -334,6 +442,37 @@ type Workers struct {
 	Workers map[string]int `json:"workers"`
 }
 
+// WorkerSummary is the output of `worker list`
+type WorkerSummary struct {
+	Service string         `json:"service"`
+	Limit   int            `json:"worker_limit"`
+	Used    int            `json:"workers_used"`
+	Workers []WorkerTarget `json:"workers"`
+}
+
+// WorkerTarget is a single Procfile target and how many workers it runs
+type WorkerTarget struct {
+	Target  string `json:"target"`
+	Scale   int    `json:"scale"`
+	Running int    `json:"running"`
+}
+
+// EnvironmentStatus is the output of `status`
+type EnvironmentStatus struct {
+	Environment *Environment    `json:"environment"`
+	Services    []ServiceStatus `json:"services"`
+}
+
+// ServiceStatus holds the running jobs and the latest build of a service
+type ServiceStatus struct {
+	ID             string `json:"id"`
+	Label          string `json:"label"`
+	Type           string `json:"type"`
+	ReleaseVersion string `json:"release_version,omitempty"`
+	Jobs           []Job  `json:"jobs"`
+	LatestBuild    *Job   `json:"latest_build,omitempty"`
+}
+
 type Maintenance struct {
 	UpstreamID string `json:"upstream"`
 	CreatedAt  string `json:"createdAt"`
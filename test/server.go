@@ -0,0 +1,941 @@
+package test
+
+import (
+	"bytes"
+	"crypto/md5"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/catalyzeio/cli/lib/crypto"
+	"github.com/catalyzeio/cli/models"
+	"github.com/catalyzeio/gcm/gcm"
+)
+
+const (
+	// Username is the username of the account seeded in the FakeServer
+	Username = "cli-tests"
+	// Password is the password of the account seeded in the FakeServer
+	Password = "cli-tests-password"
+	// Email is the email of the account seeded in the FakeServer
+	Email = "cli-tests@catalyze.io"
+	// Pod is the pod the seeded environment lives in
+	Pod = "pod01"
+	// EnvID is the ID of the seeded environment
+	EnvID = "env1234"
+	// OrgID is the ID of the org the seeded environment belongs to
+	OrgID = "org1234"
+	// CodeSvcID is the ID of the seeded code service
+	CodeSvcID = "svc-code-1"
+	// ProxySvcID is the ID of the seeded service proxy
+	ProxySvcID = "svc-proxy"
+	// DBSvcID is the ID of the seeded database service
+	DBSvcID = "svc-db01"
+	// DBLabel is the label of the seeded database service
+	DBLabel = "db01"
+	// ExistingCert is the name of the cert seeded on the service proxy
+	ExistingCert = "sbox0513063.catalyzeapps.com"
+)
+
+// FakeUser is an account known to the FakeServer. If OTP is set, signing in
+// requires a second factor and OTP is the only accepted one-time password.
+type FakeUser struct {
+	ID       string
+	Name     string
+	Email    string
+	Password string
+	OTP      string
+}
+
+// FakeServer is an in-process stand-in for the auth and PaaS APIs built on
+// net/http/httptest. All state is kept in memory and can be inspected or
+// modified directly by tests. Temporary upload and download URLs handed out by
+// the server point back at the server itself so backups, imports, and logs
+// work end to end without any other service.
+type FakeServer struct {
+	*httptest.Server
+
+	mu sync.Mutex
+
+	Users        []*FakeUser
+	Pods         []models.Pod
+	Environments []models.Environment
+	// Services, keyed by environment ID
+	Services map[string][]models.Service
+	// Jobs, EnvVars, Certs, Sites, Workers, Releases, and Files are keyed by
+	// service ID
+	Jobs     map[string][]models.Job
+	EnvVars  map[string]map[string]string
+	Certs    map[string][]models.Cert
+	Sites    map[string][]models.Site
+	Workers  map[string]*models.Workers
+	Releases map[string][]models.Release
+	Files    map[string][]models.ServiceFile
+	// BackupData is the plaintext a backup of a service contains, keyed by
+	// service ID
+	BackupData map[string][]byte
+	// Imports holds the decrypted data received by import jobs, keyed by
+	// service ID
+	Imports map[string][]byte
+	// Blobs is the storage behind temporary URLs, keyed by object name
+	Blobs map[string][]byte
+	// UploadFailures holds the status codes returned by the next uploads of
+	// each part number of a multipart upload, which is useful for injecting
+	// failed parts
+	UploadFailures map[int][]int
+	// UploadedParts is the part number of every successfully uploaded part of
+	// a multipart upload in the order they were uploaded
+	UploadedParts []int
+	// DownloadFailures holds the status codes returned by the next ranged
+	// downloads of a blob starting at each offset
+	DownloadFailures map[int64][]int
+	// DownloadedRanges is the Range header of every successful ranged download
+	// of a blob in the order they were downloaded
+	DownloadedRanges []string
+	// IgnoreRanges makes blob downloads ignore the Range header like servers
+	// without support for ranged requests
+	IgnoreRanges bool
+
+	sessions  map[string]*FakeUser
+	mfa       map[string]*FakeUser
+	overrides map[string]http.HandlerFunc
+	uploads   map[string]*fakeUpload
+	nextID    int
+}
+
+// fakeUpload is a multipart upload in progress. The size is -1 if it was not
+// given when the upload was started.
+type fakeUpload struct {
+	filename string
+	size     int64
+}
+
+// NewFakeServer starts a FakeServer seeded with a single user, environment,
+// and the code, service proxy, and database services the tests expect. The
+// caller is responsible for calling Close.
+func NewFakeServer() *FakeServer {
+	s := &FakeServer{
+		Users: []*FakeUser{{ID: "user1234", Name: Username, Email: Email, Password: Password}},
+		Pods:  []models.Pod{{Name: Pod, PHISafe: true, ImportRequiresLength: true}},
+		Environments: []models.Environment{
+			{ID: EnvID, Name: EnvLabel, Pod: Pod, Namespace: "ns1234", OrgID: OrgID},
+		},
+		Services: map[string][]models.Service{
+			EnvID: {
+				{ID: CodeSvcID, Label: SvcLabel, Name: "code", Type: "code", DNS: "code-1.internal", Source: "ssh://git@git.catalyze.io/code-1.git", Scale: 1, WorkerScale: 2, Redeployable: true},
+				{ID: ProxySvcID, Label: "service_proxy", Name: "service_proxy", Type: "utility", DNS: "service-proxy.internal", Scale: 1, Redeployable: true},
+				{ID: DBSvcID, Label: DBLabel, Name: "postgresql", Type: "database", DNS: "db01.internal", Scale: 1},
+			},
+		},
+		Jobs:     map[string][]models.Job{},
+		EnvVars:  map[string]map[string]string{},
+		Certs:    map[string][]models.Cert{ProxySvcID: {{Name: ExistingCert, Service: ProxySvcID}}},
+		Sites:    map[string][]models.Site{},
+		Workers:  map[string]*models.Workers{},
+		Releases: map[string][]models.Release{},
+		Files:    map[string][]models.ServiceFile{},
+		BackupData: map[string][]byte{
+			DBSvcID: []byte("CREATE TABLE mytable (id TEXT PRIMARY KEY, val TEXT);\nINSERT INTO mytable (id, val) values ('1', 'test');\n"),
+		},
+		Imports:          map[string][]byte{},
+		Blobs:            map[string][]byte{},
+		UploadFailures:   map[int][]int{},
+		DownloadFailures: map[int64][]int{},
+		sessions:         map[string]*FakeUser{},
+		mfa:              map[string]*FakeUser{},
+		overrides:        map[string]http.HandlerFunc{},
+		uploads:          map[string]*fakeUpload{},
+	}
+	s.Server = httptest.NewServer(s)
+	return s
+}
+
+// Override replaces the handling of a single method and path with the given
+// handler. This is useful for injecting failures. Pass a nil handler to remove
+// an override.
+func (s *FakeServer) Override(method, path string, h http.HandlerFunc) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	key := method + " " + path
+	if h == nil {
+		delete(s.overrides, key)
+	} else {
+		s.overrides[key] = h
+	}
+}
+
+// AddJob adds a job to a service and returns it with a freshly assigned ID.
+func (s *FakeServer) AddJob(svcID string, job models.Job) models.Job {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.addJob(svcID, job)
+}
+
+func (s *FakeServer) addJob(svcID string, job models.Job) models.Job {
+	job.ID = s.newID("job")
+	if job.CreatedAt == "" {
+		job.CreatedAt = time.Now().UTC().Add(time.Duration(s.nextID) * time.Second).Format("2006-01-02T15:04:05")
+	}
+	s.Jobs[svcID] = append(s.Jobs[svcID], job)
+	return job
+}
+
+// ExpireSessions signs out every user so that the next request made with an
+// existing session token is rejected
+func (s *FakeServer) ExpireSessions() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sessions = map[string]*FakeUser{}
+}
+
+// ResetImports forgets the data imported into the given service and the parts
+// uploaded so far, and fails the next uploads of each part number in failures
+// with the given status codes
+func (s *FakeServer) ResetImports(svcID string, failures map[int][]int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.Imports, svcID)
+	s.UploadedParts = nil
+	s.UploadFailures = map[int][]int{}
+	for part, codes := range failures {
+		s.UploadFailures[part] = append([]int{}, codes...)
+	}
+}
+
+// Imported returns the decrypted data imported into the given service
+func (s *FakeServer) Imported(svcID string) []byte {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.Imports[svcID]
+}
+
+// Uploaded returns the part number of every part uploaded since the last
+// ResetImports in the order they were uploaded
+func (s *FakeServer) Uploaded() []int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]int(nil), s.UploadedParts...)
+}
+
+// JobCount returns the number of jobs of the given service
+func (s *FakeServer) JobCount(svcID string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.Jobs[svcID])
+}
+
+func (s *FakeServer) newID(prefix string) string {
+	s.nextID++
+	return fmt.Sprintf("%s-%04d", prefix, s.nextID)
+}
+
+func (s *FakeServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	override := s.overrides[r.Method+" "+r.URL.Path]
+	s.mu.Unlock()
+	if override != nil {
+		override(w, r)
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	path := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	switch {
+	case path[0] == "auth":
+		s.serveAuth(w, r, path[1:])
+	case path[0] == "pods" && r.Method == "GET":
+		writeJSON(w, 200, models.PodWrapper{Pods: &s.Pods})
+	case path[0] == "blobs" && len(path) == 2:
+		s.serveBlob(w, r, path[1])
+	case s.user(r) == nil:
+		writeError(w, 401, 1001, "Unauthorized", "A valid session token is required")
+	case path[0] == "environments":
+		s.serveEnvironments(w, r, path[1:])
+	default:
+		writeError(w, 404, 404, "Not Found", fmt.Sprintf("%s %s is not supported by the fake server", r.Method, r.URL.Path))
+	}
+}
+
+func (s *FakeServer) user(r *http.Request) *FakeUser {
+	return s.sessions[strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")]
+}
+
+func (s *FakeServer) signin(u *FakeUser) map[string]string {
+	token := s.newID("session")
+	s.sessions[token] = u
+	return map[string]string{"id": u.ID, "name": u.Name, "email": u.Email, "sessionToken": token}
+}
+
+func (s *FakeServer) serveAuth(w http.ResponseWriter, r *http.Request, path []string) {
+	route := strings.Join(path, "/")
+	switch {
+	case r.Method == "POST" && route == "signin":
+		var login models.Login
+		json.NewDecoder(r.Body).Decode(&login)
+		for _, u := range s.Users {
+			if (u.Name == login.Identifier || u.Email == login.Identifier) && u.Password == login.Password {
+				if u.OTP != "" {
+					mfaID := s.newID("mfa")
+					s.mfa[mfaID] = u
+					writeJSON(w, 200, map[string]string{"mfaID": mfaID, "mfaPreferredType": "authenticator"})
+					return
+				}
+				writeJSON(w, 200, s.signin(u))
+				return
+			}
+		}
+		writeError(w, 401, 1002, "Invalid Credentials", "The given username or password is incorrect")
+	case r.Method == "POST" && len(path) == 3 && path[0] == "signin" && path[1] == "mfa":
+		u, ok := s.mfa[path[2]]
+		var body struct {
+			OTP string `json:"otp"`
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+		if !ok || body.OTP != u.OTP {
+			writeError(w, 401, 1003, "Invalid One-Time Password", "The given one-time password is incorrect")
+			return
+		}
+		delete(s.mfa, path[2])
+		writeJSON(w, 200, s.signin(u))
+	case r.Method == "GET" && route == "verify":
+		u := s.user(r)
+		if u == nil {
+			writeError(w, 401, 1001, "Unauthorized", "A valid session token is required")
+			return
+		}
+		writeJSON(w, 200, models.User{UsersID: u.ID, Username: u.Name, Email: u.Email})
+	case r.Method == "DELETE" && route == "signout":
+		delete(s.sessions, strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer "))
+		w.WriteHeader(204)
+	default:
+		writeError(w, 404, 404, "Not Found", fmt.Sprintf("%s %s is not supported by the fake server", r.Method, r.URL.Path))
+	}
+}
+
+func (s *FakeServer) serveBlob(w http.ResponseWriter, r *http.Request, name string) {
+	switch r.Method {
+	case "GET":
+		b, ok := s.Blobs[name]
+		if !ok {
+			w.WriteHeader(404)
+			return
+		}
+		rangeHeader := r.Header.Get("Range")
+		if s.IgnoreRanges || rangeHeader == "" {
+			w.Header().Set("Content-Length", strconv.Itoa(len(b)))
+			w.Write(b)
+			return
+		}
+		var offset int64
+		fmt.Sscanf(rangeHeader, "bytes=%d-", &offset)
+		if failures := s.DownloadFailures[offset]; len(failures) > 0 {
+			s.DownloadFailures[offset] = failures[1:]
+			w.WriteHeader(failures[0])
+			return
+		}
+		s.DownloadedRanges = append(s.DownloadedRanges, rangeHeader)
+		http.ServeContent(w, r, name, time.Time{}, bytes.NewReader(b))
+	case "PUT":
+		b, _ := ioutil.ReadAll(r.Body)
+		if i := strings.LastIndex(name, ".part"); i != -1 {
+			number, _ := strconv.Atoi(name[i+len(".part"):])
+			if failures := s.UploadFailures[number]; len(failures) > 0 {
+				s.UploadFailures[number] = failures[1:]
+				w.WriteHeader(failures[0])
+				return
+			}
+			s.UploadedParts = append(s.UploadedParts, number)
+			sum := md5.Sum(b)
+			w.Header().Set("ETag", hex.EncodeToString(sum[:]))
+		}
+		s.Blobs[name] = b
+		w.WriteHeader(200)
+	default:
+		w.WriteHeader(405)
+	}
+}
+
+func (s *FakeServer) serveEnvironments(w http.ResponseWriter, r *http.Request, path []string) {
+	if len(path) == 0 {
+		if r.Method != "GET" {
+			writeError(w, 405, 405, "Method Not Allowed", "")
+			return
+		}
+		envs := []models.Environment{}
+		for _, e := range s.Environments {
+			if e.Pod == r.Header.Get("X-Pod-ID") {
+				envs = append(envs, e)
+			}
+		}
+		writeJSON(w, 200, envs)
+		return
+	}
+	var env *models.Environment
+	for i := range s.Environments {
+		if s.Environments[i].ID == path[0] {
+			env = &s.Environments[i]
+		}
+	}
+	if env == nil {
+		writeError(w, 404, 404, "Environment Not Found", "Could not find an environment with the given ID")
+		return
+	}
+	if len(path) == 1 {
+		switch r.Method {
+		case "GET":
+			writeJSON(w, 200, env)
+		case "PUT":
+			json.NewDecoder(r.Body).Decode(env)
+			writeJSON(w, 200, env)
+		default:
+			writeError(w, 405, 405, "Method Not Allowed", "")
+		}
+		return
+	}
+	if path[1] != "services" {
+		writeError(w, 404, 404, "Not Found", fmt.Sprintf("%s %s is not supported by the fake server", r.Method, r.URL.Path))
+		return
+	}
+	if len(path) == 2 {
+		writeJSON(w, 200, s.Services[env.ID])
+		return
+	}
+	var svc *models.Service
+	for i := range s.Services[env.ID] {
+		if s.Services[env.ID][i].ID == path[2] {
+			svc = &s.Services[env.ID][i]
+		}
+	}
+	if svc == nil {
+		writeError(w, 404, 404, "Service Not Found", "Could not find a service with the given ID")
+		return
+	}
+	if len(path) == 3 {
+		switch r.Method {
+		case "GET":
+			writeJSON(w, 200, svc)
+		case "PUT":
+			var updates map[string]string
+			json.NewDecoder(r.Body).Decode(&updates)
+			if label, ok := updates["label"]; ok {
+				svc.Label = label
+			}
+			writeJSON(w, 200, svc)
+		default:
+			writeError(w, 405, 405, "Method Not Allowed", "")
+		}
+		return
+	}
+	s.serveService(w, r, svc, path[3:])
+}
+
+func (s *FakeServer) serveService(w http.ResponseWriter, r *http.Request, svc *models.Service, path []string) {
+	resource := path[0]
+	id := ""
+	if len(path) > 1 {
+		id = path[1]
+	}
+	switch {
+	case resource == "certs":
+		s.serveCerts(w, r, svc, id)
+	case resource == "env":
+		s.serveEnvVars(w, r, svc, id)
+	case resource == "jobs":
+		s.serveJobs(w, r, svc, id)
+	case resource == "sites":
+		s.serveSites(w, r, svc, id)
+	case resource == "workers":
+		s.serveWorkers(w, r, svc)
+	case resource == "releases":
+		s.serveReleases(w, r, svc, id)
+	case resource == "files":
+		s.serveFiles(w, r, svc, id)
+	case resource == "deploy" && r.Method == "POST":
+		s.serveDeploy(w, r, svc)
+	case resource == "backup" && r.Method == "POST":
+		s.serveBackup(w, r, svc)
+	case resource == "import" && r.Method == "POST":
+		s.serveImport(w, r, svc)
+	case resource == "restore" && r.Method == "POST" && id != "":
+		s.serveRestore(w, r, svc, id)
+	case resource == "restore-url" && id == "" && r.Method == "GET":
+		writeJSON(w, 200, models.TempURL{URL: fmt.Sprintf("%s/blobs/%s", s.URL, s.newID("upload"))})
+	case resource == "restore-url" && id == "multipart":
+		s.serveMultipartUpload(w, r, path[2:])
+	case resource == "backup-url" && r.Method == "GET" && id != "":
+		s.serveTempURL(w, "backup-"+id)
+	case resource == "backup-restore-logs-url" && r.Method == "GET" && id != "":
+		s.serveTempURL(w, "logs-"+id)
+	default:
+		writeError(w, 404, 404, "Not Found", fmt.Sprintf("%s %s is not supported by the fake server", r.Method, r.URL.Path))
+	}
+}
+
+// serveMultipartUpload starts and completes multipart uploads and hands out
+// the URLs the parts are uploaded to. Each part is stored as its own blob until
+// the upload is completed, at which point they are joined under the upload's
+// filename.
+func (s *FakeServer) serveMultipartUpload(w http.ResponseWriter, r *http.Request, path []string) {
+	switch {
+	case len(path) == 0 && r.Method == "POST":
+		var params struct {
+			Size *int64 `json:"size"`
+		}
+		json.NewDecoder(r.Body).Decode(&params)
+		u := &fakeUpload{filename: s.newID("upload"), size: -1}
+		if params.Size != nil {
+			u.size = *params.Size
+		} else {
+			for _, p := range s.Pods {
+				if p.Name == r.Header.Get("X-Pod-ID") && p.ImportRequiresLength {
+					writeError(w, 400, 400, "Import Requires Length", "The size of the upload is required for imports on this pod")
+					return
+				}
+			}
+		}
+		id := s.newID("multipart")
+		s.uploads[id] = u
+		writeJSON(w, 201, models.Upload{ID: id, Filename: u.filename})
+	case s.uploads[path[0]] == nil:
+		writeError(w, 404, 404, "Not Found", "Could not find an upload with the given ID")
+	case len(path) == 2 && r.Method == "GET":
+		writeJSON(w, 200, models.TempURL{URL: fmt.Sprintf("%s/blobs/%s.part%s", s.URL, path[0], path[1])})
+	case len(path) == 1 && r.Method == "POST":
+		u := s.uploads[path[0]]
+		var params struct {
+			Parts []models.UploadPart `json:"parts"`
+		}
+		json.NewDecoder(r.Body).Decode(&params)
+		var joined []byte
+		for i, p := range params.Parts {
+			b, ok := s.Blobs[fmt.Sprintf("%s.part%d", path[0], p.Number)]
+			sum := md5.Sum(b)
+			if p.Number != i+1 || !ok || p.ETag != hex.EncodeToString(sum[:]) {
+				writeError(w, 400, 400, "Invalid Upload", fmt.Sprintf("Part %d has not been uploaded", i+1))
+				return
+			}
+			joined = append(joined, b...)
+		}
+		if len(params.Parts) == 0 || (u.size >= 0 && int64(len(joined)) != u.size) {
+			writeError(w, 400, 400, "Invalid Upload", "The uploaded parts do not match the size of the upload")
+			return
+		}
+		for _, p := range params.Parts {
+			delete(s.Blobs, fmt.Sprintf("%s.part%d", path[0], p.Number))
+		}
+		delete(s.uploads, path[0])
+		s.Blobs[u.filename] = joined
+		w.WriteHeader(204)
+	default:
+		writeError(w, 405, 405, "Method Not Allowed", "")
+	}
+}
+
+func (s *FakeServer) serveTempURL(w http.ResponseWriter, name string) {
+	if _, ok := s.Blobs[name]; !ok {
+		writeError(w, 404, 404, "Not Found", "No file exists for the given job")
+		return
+	}
+	writeJSON(w, 200, models.TempURL{URL: fmt.Sprintf("%s/blobs/%s", s.URL, name)})
+}
+
+func (s *FakeServer) serveCerts(w http.ResponseWriter, r *http.Request, svc *models.Service, name string) {
+	certs := s.Certs[svc.ID]
+	index := -1
+	for i, c := range certs {
+		if c.Name == name {
+			index = i
+		}
+	}
+	switch {
+	case r.Method == "GET" && name == "":
+		if certs == nil {
+			certs = []models.Cert{}
+		}
+		writeJSON(w, 200, certs)
+	case r.Method == "POST" && name == "":
+		var cert models.Cert
+		json.NewDecoder(r.Body).Decode(&cert)
+		for _, c := range certs {
+			if c.Name == cert.Name {
+				writeError(w, 400, 92003, "Cert Already Exists", "A Cert already exists with the given name; alter name or delete existing cert to continue.")
+				return
+			}
+		}
+		cert.Service = svc.ID
+		s.Certs[svc.ID] = append(certs, cert)
+		w.WriteHeader(201)
+	case index == -1:
+		writeError(w, 404, 92002, "Cert Not Found", "Could not find a cert with the given name associated with the service.")
+	case r.Method == "PUT":
+		var cert models.Cert
+		json.NewDecoder(r.Body).Decode(&cert)
+		cert.Name = name
+		cert.Service = svc.ID
+		certs[index] = cert
+		w.WriteHeader(200)
+	case r.Method == "DELETE":
+		s.Certs[svc.ID] = append(certs[:index], certs[index+1:]...)
+		w.WriteHeader(204)
+	default:
+		writeError(w, 405, 405, "Method Not Allowed", "")
+	}
+}
+
+func (s *FakeServer) serveEnvVars(w http.ResponseWriter, r *http.Request, svc *models.Service, name string) {
+	if s.EnvVars[svc.ID] == nil {
+		s.EnvVars[svc.ID] = map[string]string{}
+	}
+	vars := s.EnvVars[svc.ID]
+	switch {
+	case r.Method == "GET" && name == "":
+		writeJSON(w, 200, vars)
+	case r.Method == "POST" && name == "":
+		var updates map[string]string
+		json.NewDecoder(r.Body).Decode(&updates)
+		for k, v := range updates {
+			vars[k] = v
+		}
+		writeJSON(w, 200, vars)
+	case r.Method == "DELETE" && name != "":
+		if _, ok := vars[name]; !ok {
+			writeError(w, 404, 404, "Variable Not Found", "Could not find an environment variable with the given name")
+			return
+		}
+		delete(vars, name)
+		w.WriteHeader(204)
+	default:
+		writeError(w, 405, 405, "Method Not Allowed", "")
+	}
+}
+
+func (s *FakeServer) serveJobs(w http.ResponseWriter, r *http.Request, svc *models.Service, id string) {
+	jobs := s.Jobs[svc.ID]
+	if id == "" {
+		if r.Method != "GET" {
+			writeError(w, 405, 405, "Method Not Allowed", "")
+			return
+		}
+		q := r.URL.Query()
+		filtered := []models.Job{}
+		for _, j := range jobs {
+			if (q.Get("type") == "" || j.Type == q.Get("type")) && (q.Get("status") == "" || j.Status == q.Get("status")) {
+				filtered = append(filtered, j)
+			}
+		}
+		// newest first, matching the API
+		for i, k := 0, len(filtered)-1; i < k; i, k = i+1, k-1 {
+			filtered[i], filtered[k] = filtered[k], filtered[i]
+		}
+		page, _ := strconv.Atoi(q.Get("pageNumber"))
+		pageSize, _ := strconv.Atoi(q.Get("pageSize"))
+		if page > 0 && pageSize > 0 {
+			start := (page - 1) * pageSize
+			end := start + pageSize
+			if start > len(filtered) {
+				start = len(filtered)
+			}
+			if end > len(filtered) {
+				end = len(filtered)
+			}
+			filtered = filtered[start:end]
+		}
+		writeJSON(w, 200, filtered)
+		return
+	}
+	for i, j := range jobs {
+		if j.ID != id {
+			continue
+		}
+		switch r.Method {
+		case "GET":
+			if r.URL.Query().Get("spec") != "true" {
+				j.Spec = nil
+			}
+			writeJSON(w, 200, j)
+		case "DELETE":
+			s.Jobs[svc.ID] = append(jobs[:i], jobs[i+1:]...)
+			w.WriteHeader(204)
+		default:
+			writeError(w, 405, 405, "Method Not Allowed", "")
+		}
+		return
+	}
+	writeError(w, 404, 404, "Job Not Found", "Could not find a job with the given ID")
+}
+
+func (s *FakeServer) serveSites(w http.ResponseWriter, r *http.Request, svc *models.Service, id string) {
+	sites := s.Sites[svc.ID]
+	if id == "" {
+		switch r.Method {
+		case "GET":
+			if sites == nil {
+				sites = []models.Site{}
+			}
+			writeJSON(w, 200, sites)
+		case "POST":
+			var site models.Site
+			json.NewDecoder(r.Body).Decode(&site)
+			for _, existing := range sites {
+				if existing.Name == site.Name {
+					writeError(w, 400, 93003, "Site Already Exists", "A site already exists with the given name")
+					return
+				}
+			}
+			s.nextID++
+			site.ID = s.nextID
+			s.Sites[svc.ID] = append(sites, site)
+			writeJSON(w, 201, site)
+		default:
+			writeError(w, 405, 405, "Method Not Allowed", "")
+		}
+		return
+	}
+	siteID, _ := strconv.Atoi(id)
+	for i, site := range sites {
+		if site.ID != siteID {
+			continue
+		}
+		switch r.Method {
+		case "GET":
+			writeJSON(w, 200, site)
+		case "DELETE":
+			s.Sites[svc.ID] = append(sites[:i], sites[i+1:]...)
+			w.WriteHeader(204)
+		default:
+			writeError(w, 405, 405, "Method Not Allowed", "")
+		}
+		return
+	}
+	writeError(w, 404, 93002, "Site Not Found", "Could not find a site with the given ID")
+}
+
+func (s *FakeServer) serveWorkers(w http.ResponseWriter, r *http.Request, svc *models.Service) {
+	if s.Workers[svc.ID] == nil {
+		s.Workers[svc.ID] = &models.Workers{Limit: svc.WorkerScale, Workers: map[string]int{}}
+	}
+	switch r.Method {
+	case "GET":
+		writeJSON(w, 200, s.Workers[svc.ID])
+	case "POST":
+		var workers models.Workers
+		json.NewDecoder(r.Body).Decode(&workers)
+		s.Workers[svc.ID].Workers = workers.Workers
+		writeJSON(w, 200, s.Workers[svc.ID])
+	default:
+		writeError(w, 405, 405, "Method Not Allowed", "")
+	}
+}
+
+func (s *FakeServer) serveReleases(w http.ResponseWriter, r *http.Request, svc *models.Service, name string) {
+	releases := s.Releases[svc.ID]
+	if name == "" {
+		if r.Method != "GET" {
+			writeError(w, 405, 405, "Method Not Allowed", "")
+			return
+		}
+		if releases == nil {
+			releases = []models.Release{}
+		}
+		writeJSON(w, 200, releases)
+		return
+	}
+	for i, rls := range releases {
+		if rls.Name != name {
+			continue
+		}
+		switch r.Method {
+		case "GET":
+			writeJSON(w, 200, rls)
+		case "PUT":
+			var update models.Release
+			json.NewDecoder(r.Body).Decode(&update)
+			if update.Name != "" {
+				releases[i].Name = update.Name
+			}
+			if update.Notes != "" {
+				releases[i].Notes = update.Notes
+			}
+			w.WriteHeader(200)
+		case "DELETE":
+			s.Releases[svc.ID] = append(releases[:i], releases[i+1:]...)
+			w.WriteHeader(204)
+		default:
+			writeError(w, 405, 405, "Method Not Allowed", "")
+		}
+		return
+	}
+	writeError(w, 404, 404, "Release Not Found", "Could not find a release with the given name")
+}
+
+func (s *FakeServer) serveFiles(w http.ResponseWriter, r *http.Request, svc *models.Service, id string) {
+	files := s.Files[svc.ID]
+	if id == "" {
+		if r.Method != "GET" {
+			writeError(w, 405, 405, "Method Not Allowed", "")
+			return
+		}
+		if files == nil {
+			files = []models.ServiceFile{}
+		}
+		writeJSON(w, 200, files)
+		return
+	}
+	fileID, _ := strconv.Atoi(id)
+	for _, f := range files {
+		if f.ID == fileID && r.Method == "GET" {
+			writeJSON(w, 200, f)
+			return
+		}
+	}
+	writeError(w, 404, 404, "File Not Found", "Could not find a file with the given ID")
+}
+
+func (s *FakeServer) serveDeploy(w http.ResponseWriter, r *http.Request, svc *models.Service) {
+	q := r.URL.Query()
+	job := models.Job{Type: "deploy", Status: "running"}
+	if target := q.Get("target"); target != "" {
+		job.Type = "worker"
+		job.Target = target
+		job.Spec = &models.Spec{Payload: &models.Payload{Environment: map[string]string{"PROCFILE_TARGET": target}}}
+	} else {
+		for i, j := range s.Jobs[svc.ID] {
+			if j.Type == "deploy" && j.Status == "running" {
+				s.Jobs[svc.ID][i].Status = "stopped"
+			}
+		}
+	}
+	if release := q.Get("release"); release != "" {
+		svc.ReleaseVersion = release
+	}
+	writeJSON(w, 200, s.addJob(svc.ID, job))
+}
+
+func (s *FakeServer) serveBackup(w http.ResponseWriter, r *http.Request, svc *models.Service) {
+	key, iv := randomKey()
+	store := &models.EncryptionStore{Key: hex.EncodeToString(key), IV: hex.EncodeToString(iv)}
+	job := s.addJob(svc.ID, models.Job{Type: "backup", Status: "finished", Backup: store})
+	s.Blobs["backup-"+job.ID] = encrypt(s.BackupData[svc.ID], key, iv)
+	s.Blobs["logs-"+job.ID] = encrypt([]byte(fmt.Sprintf("backup of %s finished\n", svc.Label)), key, iv)
+	writeJSON(w, 200, job)
+}
+
+func (s *FakeServer) serveImport(w http.ResponseWriter, r *http.Request, svc *models.Service) {
+	var params map[string]interface{}
+	json.NewDecoder(r.Body).Decode(&params)
+	filename, _ := params["filename"].(string)
+	// single uploads are named after the path of the URL they were sent to
+	filename = strings.TrimPrefix(filename, "blobs/")
+	hexKey, _ := params["encryptionKey"].(string)
+	hexIV, _ := params["encryptionIV"].(string)
+	encrypted, ok := s.Blobs[filename]
+	if !ok {
+		writeError(w, 400, 400, "Invalid Import", "No file has been uploaded with the given filename")
+		return
+	}
+	key, _ := hex.DecodeString(hexKey)
+	iv, _ := hex.DecodeString(hexIV)
+	status := "finished"
+	plain, err := decrypt(encrypted, key, iv)
+	if err != nil {
+		status = "failed"
+	} else {
+		s.Imports[svc.ID] = plain
+	}
+	job := s.addJob(svc.ID, models.Job{Type: "restore", Status: status, Restore: &models.EncryptionStore{Key: hexKey, IV: hexIV}})
+	s.Blobs["logs-"+job.ID] = encrypt([]byte(fmt.Sprintf("restore of %s %s\n", svc.Label, status)), key, iv)
+	writeJSON(w, 200, job)
+}
+
+func (s *FakeServer) serveRestore(w http.ResponseWriter, r *http.Request, svc *models.Service, backupID string) {
+	var backup *models.Job
+	for i, j := range s.Jobs[svc.ID] {
+		if j.ID == backupID && j.Type == "backup" {
+			backup = &s.Jobs[svc.ID][i]
+		}
+	}
+	if backup == nil {
+		writeError(w, 404, 404, "Not Found", "Could not find a backup with the given ID")
+		return
+	}
+	key, _ := hex.DecodeString(backup.Backup.Key)
+	iv, _ := hex.DecodeString(backup.Backup.IV)
+	status := "finished"
+	encrypted, ok := s.Blobs["backup-"+backup.ID]
+	plain, err := decrypt(encrypted, key, iv)
+	if !ok || err != nil {
+		status = "failed"
+	} else {
+		s.Imports[svc.ID] = plain
+	}
+	job := s.addJob(svc.ID, models.Job{Type: "restore", Status: status, Restore: &models.EncryptionStore{Key: backup.Backup.Key, IV: backup.Backup.IV}})
+	s.Blobs["logs-"+job.ID] = encrypt([]byte(fmt.Sprintf("restore of %s %s\n", svc.Label, status)), key, iv)
+	writeJSON(w, 200, job)
+}
+
+func writeJSON(w http.ResponseWriter, statusCode int, v interface{}) {
+	b, _ := json.Marshal(v)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	w.Write(b)
+}
+
+func writeError(w http.ResponseWriter, statusCode, code int, title, description string) {
+	writeJSON(w, statusCode, models.Error{Code: code, Title: title, Description: description})
+}
+
+func randomKey() ([]byte, []byte) {
+	key := make([]byte, crypto.KeySize)
+	iv := make([]byte, crypto.IVSize)
+	rand.Read(key)
+	rand.Read(iv)
+	return key, iv
+}
+
+func aad() []byte {
+	return crypto.New().Unhex([]byte(gcm.AAD), crypto.AADSize)
+}
+
+func encrypt(plain, key, iv []byte) []byte {
+	er, err := gcm.NewEncryptReader(bytes.NewReader(plain), key, iv, aad())
+	if err != nil {
+		panic(err)
+	}
+	b, err := ioutil.ReadAll(er)
+	if err != nil {
+		panic(err)
+	}
+	return b
+}
+
+type bufferCloser struct {
+	bytes.Buffer
+}
+
+func (b *bufferCloser) Close() error {
+	return nil
+}
+
+func decrypt(encrypted, key, iv []byte) ([]byte, error) {
+	out := &bufferCloser{}
+	dwc, err := gcm.NewDecryptWriteCloser(out, key, iv, aad())
+	if err != nil {
+		return nil, err
+	}
+	if _, err = dwc.Write(encrypted); err != nil {
+		return nil, err
+	}
+	if err = dwc.Close(); err != nil {
+		return nil, err
+	}
+	return out.Bytes(), nil
+}
@@ -1,12 +1,92 @@
 package test
 
 import (
+	"bytes"
 	"fmt"
+	"io"
+	"io/ioutil"
 	"os"
 	"os/exec"
+	"path/filepath"
 	"strings"
+	"testing"
+
+	"github.com/catalyzeio/cli/config"
 )
 
+// Server is the FakeServer started by Setup. It is nil until Setup is called.
+var Server *FakeServer
+
+// Main runs the tests in m against a FakeServer and returns the exit code to
+// pass to os.Exit. Packages without any other setup needs can use it directly
+// as their TestMain.
+func Main(m *testing.M) int {
+	teardown, err := Setup()
+	if err != nil {
+		fmt.Println(err)
+		return 1
+	}
+	defer teardown()
+	return m.Run()
+}
+
+// Setup starts a FakeServer, builds the CLI into a temporary directory that
+// is placed at the front of the PATH, and points the CLI at the fake server
+// through the host env variables. A temporary home directory and working
+// directory are used so that no real settings or git repos are touched. The
+// returned func stops the server and removes all temporary files.
+func Setup() (func(), error) {
+	tmp, err := ioutil.TempDir("", "catalyze-cli-test")
+	if err != nil {
+		return nil, err
+	}
+	wd, _ := os.Getwd()
+	teardown := func() {
+		if Server != nil {
+			Server.Close()
+		}
+		os.Chdir(wd)
+		os.RemoveAll(tmp)
+	}
+	binDir := filepath.Join(tmp, "bin")
+	homeDir := filepath.Join(tmp, "home")
+	workDir := filepath.Join(tmp, "work")
+	for _, dir := range []string{binDir, homeDir, workDir} {
+		if err = os.MkdirAll(dir, 0700); err != nil {
+			teardown()
+			return nil, err
+		}
+	}
+	binary := filepath.Join(binDir, BinaryName)
+	if output, err := exec.Command("go", "build", "-o", binary, "github.com/catalyzeio/cli").CombinedOutput(); err != nil {
+		teardown()
+		return nil, fmt.Errorf("Unexpected error building the CLI: %s", output)
+	}
+
+	Server = NewFakeServer()
+	env := map[string]string{
+		"PATH":                        binDir + string(os.PathListSeparator) + os.Getenv("PATH"),
+		"HOME":                        homeDir,
+		"USERPROFILE":                 homeDir,
+		config.AccountsHostEnvVar:     Server.URL,
+		config.AuthHostEnvVar:         Server.URL,
+		config.PaasHostEnvVar:         Server.URL,
+		config.CatalyzeUsernameEnvVar: Username,
+		config.CatalyzePasswordEnvVar: Password,
+		config.CredentialStoreEnvVar:  "file",
+	}
+	for k, v := range env {
+		os.Setenv(k, v)
+	}
+	os.Unsetenv(config.CatalyzeEnvironmentEnvVar)
+	os.Unsetenv(config.LogLevelEnvVar)
+	if err = os.Chdir(workDir); err != nil {
+		teardown()
+		return nil, err
+	}
+	return teardown, nil
+}
+
 // SetUpGitRepo runs git init in the current directory.
 func SetUpGitRepo() error {
 	output, err := RunCommand("git", []string{"init"})
@@ -37,9 +117,29 @@ func ClearAssociations() error {
 
 // RunCommand runs the given command and arguments with the current os ENV.
 func RunCommand(command string, args []string) (string, error) {
+	return RunCommandWithStdin(command, args, strings.NewReader("n\n"))
+}
+
+// RunCommandWithStdin runs the given command and arguments with the current os
+// ENV and the given reader as stdin.
+func RunCommandWithStdin(command string, args []string, stdin io.Reader) (string, error) {
 	cmd := exec.Command(command, args...)
 	cmd.Env = os.Environ()
-	cmd.Stdin = strings.NewReader("n\n")
+	cmd.Stdin = stdin
 	output, err := cmd.CombinedOutput()
 	return string(output), err
 }
+
+// RunCommandSeparateOutput runs the given command and arguments with the
+// current os ENV and the given reader as stdin. Unlike RunCommand, stdout and
+// stderr are returned separately.
+func RunCommandSeparateOutput(command string, args []string, stdin io.Reader) (string, string, error) {
+	cmd := exec.Command(command, args...)
+	cmd.Env = os.Environ()
+	cmd.Stdin = stdin
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	err := cmd.Run()
+	return stdout.String(), stderr.String(), err
+}